@@ -14,6 +14,9 @@ import (
 	dbconn "auth_service/internal/repository"
 	service "auth_service/internal/service"
 
+	"shared/migrate"
+	"shared/secret"
+
 	"github.com/go-chi/chi/v5"
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
@@ -25,6 +28,24 @@ func main() {
 	var db *sql.DB = dbconn.GetDbConnection()
 	defer db.Close()
 
+	// `auth_service migrate [up | down [steps] | status]` runs instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.RunCommand(db, "auth_service", dbconn.Migrations, os.Args[2:]); err != nil {
+			log.Fatal().
+				Err(err).
+				Str("service", "auth service").
+				Msg("Migrate failed")
+		}
+		return
+	}
+
+	if _, err := migrate.Up(db, "auth_service", dbconn.Migrations); err != nil {
+		log.Fatal().
+			Err(err).
+			Str("service", "auth service").
+			Msg("Migrations failed")
+	}
+
 	// redis
 	redisDb := redis.NewClient(&redis.Options{
 		Addr:     os.Getenv("REDIS_HOST") + ":" + os.Getenv("REDIS_PORT"),
@@ -61,10 +82,16 @@ func main() {
 		log.Fatal().Msg("SESSION_PREFIX environment variable is not set")
 	}
 
+	// the other services check it on the internal routes auth_service calls
+	internalSecret, status := os.LookupEnv("INTERNAL_SECRET")
+	if !status {
+		log.Fatal().Msg("INTERNAL_SECRET environment variable is not set")
+	}
+
 	// router
 	r := chi.NewRouter()
 
-	authService := service.NewAuthService(db, redisDb, writer, key, sessionPrefix)
+	authService := service.NewAuthService(db, redisDb, writer, key, sessionPrefix, internalSecret)
 	authHandler := handlers.NewAuthHandler(authService)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -72,13 +99,39 @@ func main() {
 
 	go authService.StartTokenCleanup(ctx)
 
+	purgeReader := service.GetKafkaReader(kafkaUrl, []string{"user-purged"}, "auth_service")
+	authService.StartPurgeAudit(ctx, purgeReader)
+
 	r.Post("/api/auth/register", authHandler.HandleRegister())
 	r.Post("/api/auth/login", authHandler.Login())
 
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AuthMiddleware(authService))
-		r.Delete("/api/auth/delete_user", authHandler.HandleDelete())
 		r.Get("/api/auth/me", authHandler.HandleMe())
+		// the other services resolve session cookies, bot tokens included, here
+		r.Get("/api/auth/check_token", authHandler.HandleMe())
+		r.Post("/api/auth/logout", authHandler.HandleLogout())
+
+		// the bot token of a linked telegram chat can not manage the account
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.FullScopeOnly(authService))
+			r.Delete("/api/auth/delete_user", authHandler.HandleDelete())
+
+			r.Post("/api/auth/export", authHandler.HandleExportStart())
+			r.Get("/api/auth/export/{job_id}", authHandler.HandleExportStatus())
+			r.Get("/api/auth/export/{job_id}/download", authHandler.HandleExportDownload())
+
+			r.Post("/api/auth/telegram/link", authHandler.HandleTelegramLink())
+		})
+	})
+
+	// reachable only inside the backend network, nginx proxies /api only
+	r.Group(func(r chi.Router) {
+		r.Use(secret.Bot(os.Getenv("TELEGRAM_BOT_SECRET")))
+		r.Post("/internal/telegram/link_code", authHandler.HandleTelegramLinkCode())
+		r.Post("/internal/telegram/token", authHandler.HandleBotToken())
+		r.Post("/internal/telegram/chats", authHandler.HandleTelegramChats())
+		r.Post("/internal/telegram/unlink", authHandler.HandleTelegramUnlink())
 	})
 
 	log.Info().Msg("Auth server is running")
@@ -0,0 +1,174 @@
+package auth_integration_tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loginAlice(t *testing.T) string {
+	creds := map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	}
+	body, _ := json.Marshal(creds)
+
+	resp, err := http.Post(
+		"http://localhost:8080/api/auth/login",
+		"application/json",
+		bytes.NewBuffer(body),
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var token string
+	for _, c := range resp.Cookies() {
+		if c.Name == "session_token" {
+			token = c.Value
+			break
+		}
+	}
+	require.NotEmpty(t, token)
+
+	return token
+}
+
+func TestExport_Success(t *testing.T) {
+	token := loginAlice(t)
+	client := &http.Client{}
+
+	req, err := http.NewRequest("POST", "http://localhost:8080/api/auth/export", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var job map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	jobId := job["job_id"].(string)
+
+	statusUrl := "http://localhost:8080/api/auth/export/" + jobId
+
+	require.Eventually(t, func() bool {
+		statusReq, _ := http.NewRequest("GET", statusUrl, nil)
+		statusReq.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+		statusResp, err := client.Do(statusReq)
+		if err != nil {
+			return false
+		}
+		defer statusResp.Body.Close()
+
+		var status map[string]interface{}
+		if err := json.NewDecoder(statusResp.Body).Decode(&status); err != nil {
+			return false
+		}
+		return status["status"] == "done"
+	}, 30*time.Second, 500*time.Millisecond)
+
+	downloadReq, err := http.NewRequest("GET", statusUrl+"/download", nil)
+	require.NoError(t, err)
+	downloadReq.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	downloadResp, err := client.Do(downloadReq)
+	require.NoError(t, err)
+	defer downloadResp.Body.Close()
+	require.Equal(t, http.StatusOK, downloadResp.StatusCode)
+	assert.Equal(t, "application/zip", downloadResp.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(downloadResp.Body)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"export.json", "categories.csv", "notes.csv"}, names)
+}
+
+func TestExport_MissingToken(t *testing.T) {
+	resp, err := http.Post("http://localhost:8080/api/auth/export", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestExport_UnknownJob(t *testing.T) {
+	token := loginAlice(t)
+
+	req, err := http.NewRequest(
+		"GET",
+		"http://localhost:8080/api/auth/export/"+uuid.NewString(),
+		nil,
+	)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestExport_OneRunningJob(t *testing.T) {
+	token := loginAlice(t)
+	client := &http.Client{}
+
+	start := func() *http.Response {
+		req, err := http.NewRequest("POST", "http://localhost:8080/api/auth/export", nil)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := start()
+	defer first.Body.Close()
+	require.Equal(t, http.StatusAccepted, first.StatusCode)
+
+	var job map[string]interface{}
+	require.NoError(t, json.NewDecoder(first.Body).Decode(&job))
+
+	second := start()
+	defer second.Body.Close()
+	assert.Equal(t, http.StatusConflict, second.StatusCode)
+
+	// the other export tests start their own jobs
+	statusUrl := "http://localhost:8080/api/auth/export/" + job["job_id"].(string)
+	require.Eventually(t, func() bool {
+		statusReq, _ := http.NewRequest("GET", statusUrl, nil)
+		statusReq.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+		statusResp, err := client.Do(statusReq)
+		if err != nil {
+			return false
+		}
+		defer statusResp.Body.Close()
+
+		var status map[string]interface{}
+		if err := json.NewDecoder(statusResp.Body).Decode(&status); err != nil {
+			return false
+		}
+		return status["status"] == "done" || status["status"] == "failed"
+	}, 30*time.Second, 500*time.Millisecond)
+}
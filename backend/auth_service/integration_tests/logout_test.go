@@ -0,0 +1,50 @@
+package auth_integration_tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendWithToken(t *testing.T, method string, url string, token string) *http.Response {
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func Test_Logout_RevokesOnlyThatSession(t *testing.T) {
+	token := loginAlice(t)
+	// tokens carry a second resolution exp, logins in the same second get the same token
+	time.Sleep(1100 * time.Millisecond)
+	otherToken := loginAlice(t)
+	require.NotEqual(t, token, otherToken)
+
+	resp := sendWithToken(t, "POST", "http://localhost:8080/api/auth/logout", token)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	meResp := sendWithToken(t, "GET", "http://localhost:8080/api/auth/me", token)
+	defer meResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, meResp.StatusCode)
+
+	otherResp := sendWithToken(t, "GET", "http://localhost:8080/api/auth/me", otherToken)
+	defer otherResp.Body.Close()
+	assert.Equal(t, http.StatusOK, otherResp.StatusCode)
+}
+
+func Test_Logout_MissingToken(t *testing.T) {
+	resp, err := http.Post("http://localhost:8080/api/auth/logout", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,90 @@
+package auth_integration_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postTelegramLink(t *testing.T, token string, body []byte) *http.Response {
+	req, err := http.NewRequest("POST", "http://localhost:8080/api/auth/telegram/link", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func Test_TelegramLink_UnknownCode(t *testing.T) {
+	token := loginAlice(t)
+
+	body, _ := json.Marshal(map[string]string{"code": "NOSUCHCD"})
+	resp := postTelegramLink(t, token, body)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_TelegramLink_EmptyCode(t *testing.T) {
+	token := loginAlice(t)
+
+	body, _ := json.Marshal(map[string]string{"code": ""})
+	resp := postTelegramLink(t, token, body)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_TelegramLink_InvalidJSON(t *testing.T) {
+	token := loginAlice(t)
+
+	resp := postTelegramLink(t, token, []byte("{code"))
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_TelegramLink_MissingToken(t *testing.T) {
+	body, _ := json.Marshal(map[string]string{"code": "NOSUCHCD"})
+	resp := postTelegramLink(t, "", body)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func Test_TelegramInternalRoutes_NotProxied(t *testing.T) {
+	body, _ := json.Marshal(map[string]int64{"chat_id": 42})
+	resp, err := http.Post("http://localhost:8080/internal/telegram/token", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
+
+func Test_TelegramChats_NotProxied(t *testing.T) {
+	body, _ := json.Marshal(map[string]string{"user_id": "00000000-0000-0000-0000-000000000001"})
+	resp, err := http.Post("http://localhost:8080/internal/telegram/chats", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
+
+func Test_TelegramUnlink_NotProxied(t *testing.T) {
+	body, _ := json.Marshal(map[string]int64{"chat_id": 42})
+	resp, err := http.Post("http://localhost:8080/internal/telegram/unlink", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
@@ -6,9 +6,11 @@ import (
 	render "auth_service/internal/render"
 	service "auth_service/internal/service"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
@@ -96,6 +98,7 @@ func (h *AuthHandler) HandleMe() http.HandlerFunc {
 		if err != nil {
 			log.Error().Err(err).Msg("check token failed")
 			render.HandleError(w, err)
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -135,3 +138,121 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+func (h *AuthHandler) HandleLogout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value(middleware.UserIdKey).(uuid.UUID)
+		if !ok {
+			log.Error().Msg("user id not found")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		session_cookie, err := r.Cookie("session_token")
+		if err != nil {
+			log.Error().Err(err).Msg("Unauthorized")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if err := h.service.Logout(r.Context(), userId, session_cookie.Value); err != nil {
+			log.Error().Err(err).Msg("user logout failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     "session_token",
+			Value:    "",
+			Path:     "/",
+			Expires:  time.Unix(0, 0),
+			MaxAge:   -1,
+			HttpOnly: true,
+		})
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *AuthHandler) HandleExportStart() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value(middleware.UserIdKey).(uuid.UUID)
+		if !ok {
+			log.Error().Msg("user id not found")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		job, err := h.service.StartExport(r.Context(), userId)
+		if err != nil {
+			log.Error().Err(err).Msg("export start failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+func (h *AuthHandler) HandleExportStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value(middleware.UserIdKey).(uuid.UUID)
+		if !ok {
+			log.Error().Msg("user id not found")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		jobId, err := uuid.Parse(chi.URLParam(r, "job_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("export job id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		job, err := h.service.GetExport(r.Context(), userId, jobId)
+		if err != nil {
+			log.Error().Err(err).Msg("export status failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	}
+}
+
+func (h *AuthHandler) HandleExportDownload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value(middleware.UserIdKey).(uuid.UUID)
+		if !ok {
+			log.Error().Msg("user id not found")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		jobId, err := uuid.Parse(chi.URLParam(r, "job_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("export job id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		archive, err := h.service.GetExportArchive(r.Context(), userId, jobId)
+		if err != nil {
+			log.Error().Err(err).Msg("export download failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf(`attachment; filename="halo-export-%s.zip"`, time.Now().Format("2006-01-02")),
+		)
+		w.WriteHeader(http.StatusOK)
+		w.Write(archive)
+	}
+}
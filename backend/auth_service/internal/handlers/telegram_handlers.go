@@ -0,0 +1,137 @@
+package auth_handlers
+
+import (
+	middleware "auth_service/internal/middleware"
+	models "auth_service/internal/models"
+	render "auth_service/internal/render"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+func writeJson(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Error().Err(err).Msg("failed to write json response")
+	}
+}
+
+// HandleTelegramLinkCode is called by the bot when a chat sends /link
+func (h *AuthHandler) HandleTelegramLinkCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TelegramChatRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("telegram chat json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		code, err := h.service.CreateTelegramLinkCode(r.Context(), req.Chat_id)
+		if err != nil {
+			log.Error().Err(err).Msg("telegram link code failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		writeJson(w, code)
+	}
+}
+
+// HandleTelegramLink confirms the code shown by the bot for the logged in user
+func (h *AuthHandler) HandleTelegramLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := r.Context().Value(middleware.UserIdKey).(uuid.UUID)
+		if !ok {
+			log.Error().Msg("user id not found")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req models.TelegramLinkRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("telegram link json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		link, err := h.service.ConfirmTelegramLink(r.Context(), userId, req.Code)
+		if err != nil {
+			log.Error().Err(err).Msg("telegram link failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		writeJson(w, link)
+	}
+}
+
+// HandleTelegramChats tells the bot which chats to notify about events of a user
+func (h *AuthHandler) HandleTelegramChats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TelegramUserRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("telegram user json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		chats, err := h.service.TelegramChats(r.Context(), req.User_id)
+		if err != nil {
+			log.Error().Err(err).Msg("telegram chats failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		writeJson(w, chats)
+	}
+}
+
+// HandleBotToken gives the bot a scoped token of the user linked to the chat
+func (h *AuthHandler) HandleBotToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TelegramChatRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("telegram chat json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		token, err := h.service.IssueBotToken(r.Context(), req.Chat_id)
+		if err != nil {
+			log.Error().Err(err).Msg("bot token failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		writeJson(w, token)
+	}
+}
+
+// HandleTelegramUnlink is called by the bot when a chat sends /unlink
+func (h *AuthHandler) HandleTelegramUnlink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.TelegramChatRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("telegram chat json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		link, err := h.service.UnlinkTelegramChat(r.Context(), req.Chat_id)
+		if err != nil {
+			log.Error().Err(err).Msg("telegram unlink failed")
+			render.HandleError(w, err)
+			return
+		}
+
+		writeJson(w, link)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	models "auth_service/internal/models"
 	render "auth_service/internal/render"
 	service "auth_service/internal/service"
 	"context"
@@ -34,3 +35,29 @@ func AuthMiddleware(s service.AuthService) func(next http.Handler) http.Handler
 		})
 	}
 }
+
+// FullScopeOnly rejects scoped tokens, e.g. the bot token, on account management routes,
+// it runs after AuthMiddleware
+func FullScopeOnly(s service.AuthService) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session_cookie, err := r.Cookie("session_token")
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			scope, err := s.TokenScope(session_cookie.Value)
+			if err != nil {
+				render.HandleError(w, err)
+				return
+			}
+			if scope != "" {
+				render.HandleError(w, models.ErrForbiddenScope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -10,4 +10,8 @@ var (
 
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrForbiddenScope     = errors.New("token scope does not allow this action")
+
+	ErrNotReady      = errors.New("resource is not ready")
+	ErrExportRunning = errors.New("an export is already running")
 )
@@ -32,3 +32,103 @@ type UserLoginResponse struct {
 type CheckTokenRequest struct {
 	Token string `json:"token"`
 }
+
+type UserPurgedEvent struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Service     string    `json:"service"`
+	Purged_rows int64     `json:"purged_rows"`
+	Purged_at   int64     `json:"purged_at"`
+}
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusRunning = "running"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+)
+
+type ExportJob struct {
+	Job_id     uuid.UUID `json:"job_id"`
+	Status     string    `json:"status"`
+	Progress   int       `json:"progress"`
+	Error      string    `json:"error,omitempty"`
+	Created_at int64     `json:"created_at"`
+	Expires_at int64     `json:"expires_at"`
+}
+
+type ExportAccount struct {
+	User_id         uuid.UUID `json:"user_id"`
+	Login           string    `json:"login"`
+	Created_at      int64     `json:"created_at"`
+	Active_sessions int       `json:"active_sessions"`
+}
+
+type ExportProfile struct {
+	User_id  uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+}
+
+type ExportCategory struct {
+	Id         uuid.UUID `json:"category_id"`
+	Name       string    `json:"name"`
+	Created_at int64     `json:"created_at"`
+	Updated_at int64     `json:"updated_at"`
+}
+
+type ExportNote struct {
+	Id          uuid.UUID `json:"note_id"`
+	Category_id uuid.UUID `json:"category_id"`
+	Content     string    `json:"content"`
+	Created_at  int64     `json:"created_at"`
+	Updated_at  int64     `json:"updated_at"`
+	Ended_at    int64     `json:"ended_at"`
+	Completed   bool      `json:"completed"`
+}
+
+type UserDataExport struct {
+	Exported_at int64            `json:"exported_at"`
+	Account     ExportAccount    `json:"account"`
+	Profile     *ExportProfile   `json:"profile"`
+	Categories  []ExportCategory `json:"categories"`
+	Notes       []ExportNote     `json:"notes"`
+}
+
+// TokenScopeBot marks tokens the telegram bot uses on behalf of a linked user,
+// they work for notes and categories but not for account management
+const TokenScopeBot = "bot"
+
+type TelegramChatRequest struct {
+	Chat_id int64 `json:"chat_id"`
+}
+
+type TelegramUserRequest struct {
+	User_id uuid.UUID `json:"user_id"`
+}
+
+// TelegramChats are the chats linked to the user, the bot pushes reminders to them
+type TelegramChats struct {
+	User_id  uuid.UUID `json:"user_id"`
+	Chat_ids []int64   `json:"chat_ids"`
+}
+
+type TelegramLinkCode struct {
+	Code       string `json:"code"`
+	Expires_at int64  `json:"expires_at"`
+}
+
+type TelegramLinkRequest struct {
+	Code string `json:"code"`
+}
+
+type TelegramLink struct {
+	Chat_id   int64     `json:"chat_id"`
+	User_id   uuid.UUID `json:"user_id"`
+	Linked_at int64     `json:"linked_at"`
+}
+
+type BotToken struct {
+	Token      string    `json:"token"`
+	User_id    uuid.UUID `json:"user_id"`
+	Expires_at int64     `json:"expires_at"`
+}
@@ -20,6 +20,12 @@ func HandleError(w http.ResponseWriter, err error) {
 		http.Error(w, "Incorrect password", http.StatusUnauthorized)
 	case errors.Is(err, models.ErrInvalidToken):
 		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	case errors.Is(err, models.ErrForbiddenScope):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, models.ErrNotReady):
+		http.Error(w, "Not ready", http.StatusConflict)
+	case errors.Is(err, models.ErrExportRunning):
+		http.Error(w, "Export already running", http.StatusConflict)
 	default:
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
@@ -1,6 +1,7 @@
+-- IF NOT EXISTS adopts databases created by the old db/init/schema.sql
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-CREATE TABLE auth_credentials (
+CREATE TABLE IF NOT EXISTS auth_credentials (
   user_id UUID PRIMARY KEY,
   login VARCHAR(255) UNIQUE NOT NULL,
   password_hash TEXT NOT NULL,
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_purge_audit (
+  user_id UUID NOT NULL,
+  service VARCHAR(64) NOT NULL,
+  purged_rows BIGINT NOT NULL,
+  purged_at BIGINT NOT NULL,
+  PRIMARY KEY (user_id, service)
+);
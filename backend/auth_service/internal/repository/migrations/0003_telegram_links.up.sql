@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS telegram_links (
+  chat_id BIGINT PRIMARY KEY,
+  user_id UUID NOT NULL,
+  linked_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS telegram_links_user_id ON telegram_links(user_id);
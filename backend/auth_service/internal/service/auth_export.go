@@ -0,0 +1,350 @@
+package auth_service
+
+import (
+	"archive/zip"
+	models "auth_service/internal/models"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	exportPrefix  = "export"
+	exportTTL     = 24 * time.Hour
+	exportTimeout = 2 * time.Minute
+
+	userServiceUrl     = "http://user_service:8080"
+	noteServiceUrl     = "http://note_service:8080"
+	categoryServiceUrl = "http://category_service:8080"
+)
+
+func exportJobKey(userId uuid.UUID, jobId uuid.UUID) string {
+	return fmt.Sprintf("%v:%v:%v", exportPrefix, userId, jobId)
+}
+
+// exportRunningKey holds the job id of the running export of the user, one at
+// a time keeps the archives a user can pile up in redis bounded
+func exportRunningKey(userId uuid.UUID) string {
+	return fmt.Sprintf("%v:%v:running", exportPrefix, userId)
+}
+
+func exportArchiveKey(userId uuid.UUID, jobId uuid.UUID) string {
+	return exportJobKey(userId, jobId) + ":archive"
+}
+
+func (s *authService) StartExport(ctx context.Context, userId uuid.UUID) (models.ExportJob, error) {
+	jobId, err := uuid.NewV7()
+	if err != nil {
+		return models.ExportJob{}, fmt.Errorf("service: generating export id failed: %w", err)
+	}
+
+	// expires with the timeout of the job in case the service stops mid-export
+	started, err := s.redisDb.SetNX(ctx, exportRunningKey(userId), jobId.String(), exportTimeout).Result()
+	if err != nil {
+		return models.ExportJob{}, fmt.Errorf("service: redis export lock failed: %w", err)
+	}
+	if !started {
+		return models.ExportJob{}, models.ErrExportRunning
+	}
+
+	now := time.Now()
+	job := models.ExportJob{
+		Job_id:     jobId,
+		Status:     models.ExportStatusPending,
+		Created_at: now.Unix(),
+		Expires_at: now.Add(exportTTL).Unix(),
+	}
+
+	err = s.saveExportJob(ctx, userId, job)
+	if err != nil {
+		s.redisDb.Del(ctx, exportRunningKey(userId))
+		return models.ExportJob{}, fmt.Errorf("service: saving export job failed: %w", err)
+	}
+
+	// request context ends with the response, the job outlives it
+	go s.runExport(userId, job)
+
+	return job, nil
+}
+
+func (s *authService) GetExport(
+	ctx context.Context,
+	userId uuid.UUID,
+	jobId uuid.UUID,
+) (models.ExportJob, error) {
+	var job models.ExportJob
+
+	data, err := s.redisDb.Get(ctx, exportJobKey(userId, jobId)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return job, models.ErrNotFound
+		}
+		return job, fmt.Errorf("service: redis export job fetch failed: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &job); err != nil {
+		return job, fmt.Errorf("service: export job parse failed: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *authService) GetExportArchive(
+	ctx context.Context,
+	userId uuid.UUID,
+	jobId uuid.UUID,
+) ([]byte, error) {
+	job, err := s.GetExport(ctx, userId, jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != models.ExportStatusDone {
+		return nil, models.ErrNotReady
+	}
+
+	archive, err := s.redisDb.Get(ctx, exportArchiveKey(userId, jobId)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("service: redis export archive fetch failed: %w", err)
+	}
+
+	return archive, nil
+}
+
+func (s *authService) saveExportJob(ctx context.Context, userId uuid.UUID, job models.ExportJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("export job marshal failed: %w", err)
+	}
+
+	ttl := time.Until(time.Unix(job.Expires_at, 0))
+
+	err = s.redisDb.Set(ctx, exportJobKey(userId, job.Job_id), data, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("redis export job insertion failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *authService) runExport(userId uuid.UUID, job models.ExportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	defer func() {
+		if err := s.redisDb.Del(context.Background(), exportRunningKey(userId)).Err(); err != nil {
+			log.Error().Err(err).Msg("export lock release failed")
+		}
+	}()
+
+	fail := func(err error) {
+		log.Error().Err(err).Str("job_id", job.Job_id.String()).Msg("user data export failed")
+		job.Status = models.ExportStatusFailed
+		job.Error = "export failed"
+		// ctx may be the one that timed out
+		if err := s.saveExportJob(context.Background(), userId, job); err != nil {
+			log.Error().Err(err).Msg("export job status update failed")
+		}
+	}
+
+	progress := func(value int) {
+		job.Status = models.ExportStatusRunning
+		job.Progress = value
+		if err := s.saveExportJob(ctx, userId, job); err != nil {
+			log.Error().Err(err).Msg("export job status update failed")
+		}
+	}
+
+	export := models.UserDataExport{
+		Exported_at: time.Now().Unix(),
+		Categories:  make([]models.ExportCategory, 0),
+		Notes:       make([]models.ExportNote, 0),
+	}
+
+	account, err := getUserCredentialsInfo(ctx, s.db, userId)
+	if err != nil {
+		fail(fmt.Errorf("auth metadata: %w", err))
+		return
+	}
+
+	tokens, err := s.getUserTokens(ctx, userId)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		fail(fmt.Errorf("auth sessions: %w", err))
+		return
+	}
+	account.Active_sessions = len(tokens)
+	export.Account = account
+	progress(20)
+
+	var profile models.ExportProfile
+	found, err := s.fetchInternalExport(ctx, userServiceUrl+"/internal/user/export", userId, &profile)
+	if err != nil {
+		fail(fmt.Errorf("profile: %w", err))
+		return
+	}
+	if found {
+		export.Profile = &profile
+	}
+	progress(40)
+
+	_, err = s.fetchInternalExport(
+		ctx,
+		categoryServiceUrl+"/internal/category/export",
+		userId,
+		&export.Categories,
+	)
+	if err != nil {
+		fail(fmt.Errorf("categories: %w", err))
+		return
+	}
+	progress(60)
+
+	_, err = s.fetchInternalExport(ctx, noteServiceUrl+"/internal/note/export", userId, &export.Notes)
+	if err != nil {
+		fail(fmt.Errorf("notes: %w", err))
+		return
+	}
+	progress(80)
+
+	archive, err := buildExportArchive(export)
+	if err != nil {
+		fail(fmt.Errorf("archive: %w", err))
+		return
+	}
+
+	ttl := time.Until(time.Unix(job.Expires_at, 0))
+	err = s.redisDb.Set(ctx, exportArchiveKey(userId, job.Job_id), archive, ttl).Err()
+	if err != nil {
+		fail(fmt.Errorf("archive insertion: %w", err))
+		return
+	}
+
+	job.Status = models.ExportStatusDone
+	job.Progress = 100
+	if err := s.saveExportJob(ctx, userId, job); err != nil {
+		log.Error().Err(err).Msg("export job status update failed")
+		return
+	}
+
+	log.Info().Str("job_id", job.Job_id.String()).Msg("User data export finished")
+}
+
+// fetchInternalExport reports false when the service has no data for the user
+func (s *authService) fetchInternalExport(
+	ctx context.Context,
+	endpoint string,
+	userId uuid.UUID,
+	dst any,
+) (bool, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false, fmt.Errorf("export url parse failed: %w", err)
+	}
+	u.RawQuery = url.Values{"user_id": {userId.String()}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, fmt.Errorf("export request failed: %w", err)
+	}
+	req.Header.Set("X-Internal-Secret", s.internalSecret)
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("export request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("export request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return false, fmt.Errorf("export response decode failed: %w", err)
+	}
+
+	return true, nil
+}
+
+func buildExportArchive(export models.UserDataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	jsonFile, err := archive.Create("export.json")
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(jsonFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return nil, err
+	}
+
+	categoriesFile, err := archive.Create("categories.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	categoryRows := [][]string{{"category_id", "name", "created_at", "updated_at"}}
+	for _, c := range export.Categories {
+		categoryRows = append(categoryRows, []string{
+			c.Id.String(),
+			c.Name,
+			strconv.FormatInt(c.Created_at, 10),
+			strconv.FormatInt(c.Updated_at, 10),
+		})
+	}
+
+	if err := csv.NewWriter(categoriesFile).WriteAll(categoryRows); err != nil {
+		return nil, err
+	}
+
+	notesFile, err := archive.Create("notes.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	noteRows := [][]string{
+		{"note_id", "category_id", "content", "created_at", "updated_at", "ended_at", "completed"},
+	}
+	for _, n := range export.Notes {
+		noteRows = append(noteRows, []string{
+			n.Id.String(),
+			n.Category_id.String(),
+			n.Content,
+			strconv.FormatInt(n.Created_at, 10),
+			strconv.FormatInt(n.Updated_at, 10),
+			strconv.FormatInt(n.Ended_at, 10),
+			strconv.FormatBool(n.Completed),
+		})
+	}
+
+	if err := csv.NewWriter(notesFile).WriteAll(noteRows); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
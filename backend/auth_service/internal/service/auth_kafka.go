@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -67,3 +69,50 @@ func (s *authService) sentUserDeletedEvent(
 	}
 	return nil
 }
+
+func GetKafkaReader(kafkaURL string, topics []string, groupID string) *kafka.Reader {
+	brokers := strings.Split(kafkaURL, ",")
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		GroupTopics: topics,
+		MinBytes:    10e3, // 10KB
+		MaxBytes:    10e6, // 10MB
+	})
+}
+
+// StartPurgeAudit records user-purged reports from the other services,
+// so a deletion can be followed until every service has dropped the user's data
+func (s *authService) StartPurgeAudit(ctx context.Context, reader *kafka.Reader) {
+	go func() {
+		defer reader.Close()
+
+		for {
+			m, err := reader.ReadMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Error().Err(err).Msg("kafka user-purged reading failed")
+				continue
+			}
+
+			var event models.UserPurgedEvent
+			if err := json.Unmarshal(m.Value, &event); err != nil {
+				log.Error().Err(err).Msg("user-purged parse failed")
+				continue
+			}
+
+			if err := addPurgeAudit(ctx, s.db, event); err != nil {
+				log.Error().Err(err).Msg("user-purged audit failed")
+				continue
+			}
+
+			log.Info().
+				Str("user_id", event.User_id.String()).
+				Str("service", event.Service).
+				Int64("purged_rows", event.Purged_rows).
+				Msg("User purge confirmed")
+		}
+	}()
+}
@@ -104,3 +104,43 @@ func checkCredentials(
 
 	return userId, nil
 }
+
+func addPurgeAudit(ctx context.Context, db *sql.DB, event models.UserPurgedEvent) error {
+	query := `INSERT INTO user_purge_audit (user_id, service, purged_rows, purged_at)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (user_id, service)
+						DO UPDATE SET purged_rows = EXCLUDED.purged_rows, purged_at = EXCLUDED.purged_at`
+
+	_, err := db.ExecContext(
+		ctx,
+		query,
+		event.User_id,
+		event.Service,
+		event.Purged_rows,
+		event.Purged_at,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting purge audit failed: %w", err)
+	}
+
+	return nil
+}
+
+func getUserCredentialsInfo(
+	ctx context.Context,
+	db *sql.DB,
+	userId uuid.UUID,
+) (models.ExportAccount, error) {
+	account := models.ExportAccount{User_id: userId}
+
+	query := `SELECT login, created_at
+						FROM auth_credentials
+						WHERE user_id = $1`
+
+	err := db.QueryRowContext(ctx, query, userId).Scan(&account.Login, &account.Created_at)
+	if err != nil {
+		return account, repository.MapError(err)
+	}
+
+	return account, nil
+}
@@ -52,12 +52,22 @@ func (s *authService) deleteExpiredTokens(ctx context.Context) {
 }
 
 func (s *authService) createToken(ctx context.Context, userId uuid.UUID) (string, error) {
-	t := time.Now().Unix() + 60*60*24*31*6
+	return s.createScopedToken(ctx, userId, jwt.MapClaims{}, 60*60*24*31*6)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userId,
-		"exp":     t,
-	})
+// createScopedToken signs a session token with extra claims, e.g. a scope,
+// and registers it like any other session so it can be checked and revoked
+func (s *authService) createScopedToken(
+	ctx context.Context,
+	userId uuid.UUID,
+	claims jwt.MapClaims,
+	ttlSeconds int64,
+) (string, error) {
+	t := time.Now().Unix() + ttlSeconds
+
+	claims["user_id"] = userId
+	claims["exp"] = t
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	signed, err := token.SignedString([]byte(s.secretKey))
 	if err != nil {
@@ -77,7 +87,7 @@ func (s *authService) createToken(ctx context.Context, userId uuid.UUID) (string
 	return signed, nil
 }
 
-func (s *authService) ParseToken(tokenStr string) (uuid.UUID, error) {
+func (s *authService) parseClaims(tokenStr string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("jwt signing method not supported")
@@ -85,12 +95,21 @@ func (s *authService) ParseToken(tokenStr string) (uuid.UUID, error) {
 		return []byte(s.secretKey), nil
 	})
 	if err != nil {
-		return uuid.UUID{}, models.ErrInvalidToken
+		return nil, models.ErrInvalidToken
 	}
 
 	claims, status := token.Claims.(jwt.MapClaims)
 	if !status || !token.Valid {
-		return uuid.UUID{}, models.ErrInvalidToken
+		return nil, models.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (s *authService) ParseToken(tokenStr string) (uuid.UUID, error) {
+	claims, err := s.parseClaims(tokenStr)
+	if err != nil {
+		return uuid.UUID{}, err
 	}
 
 	userIdRaw, ok := claims["user_id"].(string)
@@ -106,6 +125,17 @@ func (s *authService) ParseToken(tokenStr string) (uuid.UUID, error) {
 	return userId, nil
 }
 
+// TokenScope returns the scope claim of the token, empty for regular sessions
+func (s *authService) TokenScope(tokenStr string) (string, error) {
+	claims, err := s.parseClaims(tokenStr)
+	if err != nil {
+		return "", err
+	}
+
+	scope, _ := claims["scope"].(string)
+	return scope, nil
+}
+
 func (s *authService) deleteTokensByUserId(ctx context.Context, userId uuid.UUID) error {
 	_, err := s.redisDb.Del(ctx, fmt.Sprintf("%v:%v", s.sessionPrefix, userId)).Result()
 	if err != nil {
@@ -114,6 +144,19 @@ func (s *authService) deleteTokensByUserId(ctx context.Context, userId uuid.UUID
 	return nil
 }
 
+func (s *authService) revokeToken(ctx context.Context, userId uuid.UUID, token string) error {
+	key := fmt.Sprintf("%v:%v", s.sessionPrefix, userId)
+
+	removed, err := s.redisDb.ZRem(ctx, key, token).Result()
+	if err != nil {
+		return fmt.Errorf("redis token removal failed: %w", err)
+	}
+	if removed == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
 func (s *authService) getUserTokens(ctx context.Context, userId uuid.UUID) ([]string, error) {
 	key := fmt.Sprintf("%v:%v", s.sessionPrefix, userId)
 
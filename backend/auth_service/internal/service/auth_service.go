@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -18,15 +19,27 @@ type AuthService interface {
 	DeleteUser(ctx context.Context, userId uuid.UUID) error
 	CheckToken(ctx context.Context, userSessionToken string) (uuid.UUID, error)
 	Login(ctx context.Context, userData models.UserLogin) (string, error)
+	Logout(ctx context.Context, userId uuid.UUID, userSessionToken string) error
 	StartTokenCleanup(ctx context.Context)
+	StartPurgeAudit(ctx context.Context, reader *kafka.Reader)
+	StartExport(ctx context.Context, userId uuid.UUID) (models.ExportJob, error)
+	GetExport(ctx context.Context, userId uuid.UUID, jobId uuid.UUID) (models.ExportJob, error)
+	GetExportArchive(ctx context.Context, userId uuid.UUID, jobId uuid.UUID) ([]byte, error)
+	TokenScope(userSessionToken string) (string, error)
+	CreateTelegramLinkCode(ctx context.Context, chatId int64) (models.TelegramLinkCode, error)
+	ConfirmTelegramLink(ctx context.Context, userId uuid.UUID, code string) (models.TelegramLink, error)
+	IssueBotToken(ctx context.Context, chatId int64) (models.BotToken, error)
+	TelegramChats(ctx context.Context, userId uuid.UUID) (models.TelegramChats, error)
+	UnlinkTelegramChat(ctx context.Context, chatId int64) (models.TelegramLink, error)
 }
 
 type authService struct {
-	db            *sql.DB
-	redisDb       *redis.Client
-	writer        *kafka.Writer
-	secretKey     string
-	sessionPrefix string
+	db             *sql.DB
+	redisDb        *redis.Client
+	writer         *kafka.Writer
+	secretKey      string
+	sessionPrefix  string
+	internalSecret string
 }
 
 func NewAuthService(
@@ -35,13 +48,15 @@ func NewAuthService(
 	writer *kafka.Writer,
 	key string,
 	sessionPrefix string,
+	internalSecret string,
 ) AuthService {
 	return &authService{
-		db:            db,
-		redisDb:       redisDb,
-		writer:        writer,
-		secretKey:     key,
-		sessionPrefix: sessionPrefix,
+		db:             db,
+		redisDb:        redisDb,
+		writer:         writer,
+		secretKey:      key,
+		sessionPrefix:  sessionPrefix,
+		internalSecret: internalSecret,
 	}
 }
 
@@ -93,11 +108,26 @@ func (s *authService) DeleteUser(ctx context.Context, userId uuid.UUID) error {
 		return fmt.Errorf("service: send user deleted event failed: %w", err)
 	}
 
+	err = addPurgeAudit(ctx, s.db, models.UserPurgedEvent{
+		User_id:     userId,
+		Service:     "auth_service",
+		Purged_rows: 1,
+		Purged_at:   time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("service: purge audit failed: %w", err)
+	}
+
 	err = s.deleteTokensByUserId(ctx, userId)
 	if err != nil {
 		return fmt.Errorf("service: delete tokens by user id failed: %w", err)
 	}
 
+	err = deleteTelegramLinks(ctx, s.db, userId)
+	if err != nil {
+		return fmt.Errorf("service: delete telegram links failed: %w", err)
+	}
+
 	return nil
 }
 
@@ -141,3 +171,11 @@ func (s *authService) Login(ctx context.Context, userData models.UserLogin) (str
 
 	return token, nil
 }
+
+// Logout revokes one session, other sessions of the user stay valid
+func (s *authService) Logout(ctx context.Context, userId uuid.UUID, userSessionToken string) error {
+	if err := s.revokeToken(ctx, userId, userSessionToken); err != nil {
+		return fmt.Errorf("service: token revoke failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package auth_service
+
+import (
+	models "auth_service/internal/models"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	telegramCodePrefix = "telegram_link:"
+	telegramCodeTTL    = 10 * time.Minute
+	telegramCodeLength = 8
+
+	// no 0/O and 1/I, the code is typed by hand
+	telegramCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	botTokenTTL = 24 * 60 * 60
+)
+
+func newTelegramCode() (string, error) {
+	buf := make([]byte, telegramCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, telegramCodeLength)
+	for i, b := range buf {
+		code[i] = telegramCodeAlphabet[int(b)%len(telegramCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// CreateTelegramLinkCode gives the chat a one-time code, the user confirms it
+// while logged in and the chat gets linked to the account
+func (s *authService) CreateTelegramLinkCode(ctx context.Context, chatId int64) (models.TelegramLinkCode, error) {
+	if chatId == 0 {
+		return models.TelegramLinkCode{}, models.ErrInvalidRequest
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := newTelegramCode()
+		if err != nil {
+			return models.TelegramLinkCode{}, fmt.Errorf("service: link code generation failed: %w", err)
+		}
+
+		created, err := s.redisDb.SetNX(ctx, telegramCodePrefix+code, chatId, telegramCodeTTL).Result()
+		if err != nil {
+			return models.TelegramLinkCode{}, fmt.Errorf("service: link code store failed: %w", err)
+		}
+		if created {
+			return models.TelegramLinkCode{
+				Code:       code,
+				Expires_at: time.Now().Add(telegramCodeTTL).Unix(),
+			}, nil
+		}
+	}
+
+	return models.TelegramLinkCode{}, fmt.Errorf("service: link code collision")
+}
+
+// ConfirmTelegramLink consumes the code, a chat can be linked to one user only
+func (s *authService) ConfirmTelegramLink(
+	ctx context.Context,
+	userId uuid.UUID,
+	code string,
+) (models.TelegramLink, error) {
+	if code == "" {
+		return models.TelegramLink{}, models.ErrInvalidRequest
+	}
+
+	value, err := s.redisDb.GetDel(ctx, telegramCodePrefix+code).Result()
+	if errors.Is(err, redis.Nil) {
+		return models.TelegramLink{}, models.ErrNotFound
+	}
+	if err != nil {
+		return models.TelegramLink{}, fmt.Errorf("service: link code fetch failed: %w", err)
+	}
+
+	chatId, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return models.TelegramLink{}, fmt.Errorf("service: link code parse failed: %w", err)
+	}
+
+	link := models.TelegramLink{
+		Chat_id:   chatId,
+		User_id:   userId,
+		Linked_at: time.Now().Unix(),
+	}
+
+	previousUserId, err := getTelegramLinkUser(ctx, s.db, chatId)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return models.TelegramLink{}, fmt.Errorf("service: get telegram link failed: %w", err)
+	}
+	relinked := err == nil && previousUserId != userId
+
+	if err := saveTelegramLink(ctx, s.db, link); err != nil {
+		return models.TelegramLink{}, fmt.Errorf("service: save telegram link failed: %w", err)
+	}
+
+	// the chat moved to another account, the bot must not act for the previous one
+	if relinked {
+		if err := s.revokeBotTokens(ctx, previousUserId, chatId); err != nil {
+			return models.TelegramLink{}, fmt.Errorf("service: revoke bot tokens failed: %w", err)
+		}
+	}
+
+	return link, nil
+}
+
+// UnlinkTelegramChat removes the link of the chat and revokes the bot tokens issued for it
+func (s *authService) UnlinkTelegramChat(ctx context.Context, chatId int64) (models.TelegramLink, error) {
+	if chatId == 0 {
+		return models.TelegramLink{}, models.ErrInvalidRequest
+	}
+
+	link, err := deleteTelegramLink(ctx, s.db, chatId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.TelegramLink{}, err
+		}
+		return models.TelegramLink{}, fmt.Errorf("service: delete telegram link failed: %w", err)
+	}
+
+	if err := s.revokeBotTokens(ctx, link.User_id, chatId); err != nil {
+		return models.TelegramLink{}, fmt.Errorf("service: revoke bot tokens failed: %w", err)
+	}
+
+	return link, nil
+}
+
+// revokeBotTokens removes the bot tokens of the user issued for the chat from
+// the sessions, other sessions and chats of the user stay valid
+func (s *authService) revokeBotTokens(ctx context.Context, userId uuid.UUID, chatId int64) error {
+	key := fmt.Sprintf("%v:%v", s.sessionPrefix, userId)
+
+	tokens, err := s.redisDb.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("redis token fetch failed: %w", err)
+	}
+
+	for _, token := range tokens {
+		// expired tokens fail to parse, the cleanup removes them
+		claims, err := s.parseClaims(token)
+		if err != nil {
+			continue
+		}
+
+		scope, _ := claims["scope"].(string)
+		tokenChatId, _ := claims["chat_id"].(float64)
+		if scope != models.TokenScopeBot || int64(tokenChatId) != chatId {
+			continue
+		}
+
+		if err := s.redisDb.ZRem(ctx, key, token).Err(); err != nil {
+			return fmt.Errorf("redis token removal failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IssueBotToken returns a short lived token with the bot scope for the user linked to the chat
+func (s *authService) IssueBotToken(ctx context.Context, chatId int64) (models.BotToken, error) {
+	userId, err := getTelegramLinkUser(ctx, s.db, chatId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.BotToken{}, err
+		}
+		return models.BotToken{}, fmt.Errorf("service: get telegram link failed: %w", err)
+	}
+
+	token, err := s.createScopedToken(ctx, userId, jwt.MapClaims{
+		"scope":   models.TokenScopeBot,
+		"chat_id": chatId,
+	}, botTokenTTL)
+	if err != nil {
+		return models.BotToken{}, fmt.Errorf("service: bot token creation failed: %w", err)
+	}
+
+	return models.BotToken{
+		Token:      token,
+		User_id:    userId,
+		Expires_at: time.Now().Unix() + botTokenTTL,
+	}, nil
+}
+
+// TelegramChats lists the chats linked to the user, none is not an error
+func (s *authService) TelegramChats(ctx context.Context, userId uuid.UUID) (models.TelegramChats, error) {
+	if userId == uuid.Nil {
+		return models.TelegramChats{}, models.ErrInvalidRequest
+	}
+
+	query := `SELECT chat_id FROM telegram_links WHERE user_id = $1 ORDER BY linked_at`
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return models.TelegramChats{}, fmt.Errorf("service: get telegram chats failed: %w", err)
+	}
+	defer rows.Close()
+
+	chats := models.TelegramChats{User_id: userId, Chat_ids: []int64{}}
+	for rows.Next() {
+		var chatId int64
+		if err := rows.Scan(&chatId); err != nil {
+			return models.TelegramChats{}, fmt.Errorf("service: scan telegram chat failed: %w", err)
+		}
+		chats.Chat_ids = append(chats.Chat_ids, chatId)
+	}
+	if err := rows.Err(); err != nil {
+		return models.TelegramChats{}, fmt.Errorf("service: get telegram chats failed: %w", err)
+	}
+
+	return chats, nil
+}
+
+func saveTelegramLink(ctx context.Context, db *sql.DB, link models.TelegramLink) error {
+	query := `INSERT INTO telegram_links (chat_id, user_id, linked_at)
+						VALUES ($1, $2, $3)
+						ON CONFLICT (chat_id)
+						DO UPDATE SET user_id = EXCLUDED.user_id, linked_at = EXCLUDED.linked_at`
+
+	_, err := db.ExecContext(ctx, query, link.Chat_id, link.User_id, link.Linked_at)
+	return err
+}
+
+func getTelegramLinkUser(ctx context.Context, db *sql.DB, chatId int64) (uuid.UUID, error) {
+	var userId uuid.UUID
+
+	query := `SELECT user_id FROM telegram_links WHERE chat_id = $1`
+	err := db.QueryRowContext(ctx, query, chatId).Scan(&userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userId, models.ErrNotFound
+	}
+	return userId, err
+}
+
+func deleteTelegramLink(ctx context.Context, db *sql.DB, chatId int64) (models.TelegramLink, error) {
+	link := models.TelegramLink{Chat_id: chatId}
+
+	query := `DELETE FROM telegram_links WHERE chat_id = $1 RETURNING user_id, linked_at`
+	err := db.QueryRowContext(ctx, query, chatId).Scan(&link.User_id, &link.Linked_at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, models.ErrNotFound
+	}
+	return link, err
+}
+
+func deleteTelegramLinks(ctx context.Context, db *sql.DB, userId uuid.UUID) error {
+	query := `DELETE FROM telegram_links WHERE user_id = $1`
+
+	_, err := db.ExecContext(ctx, query, userId)
+	return err
+}
@@ -2,9 +2,18 @@ package main
 
 import (
 	handlers "category_service/internal/handlers"
+	category_kafka "category_service/internal/kafka"
+	reminders "category_service/internal/reminders"
 	dbconn "category_service/internal/repository"
 	"database/sql"
+	"fmt"
 	"net/http"
+	"os"
+	// habits are reminded of in the timezones of the users without tzdata in the image
+	_ "time/tzdata"
+
+	"shared/migrate"
+	"shared/secret"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
@@ -14,11 +23,44 @@ func main() {
 	var db *sql.DB = dbconn.GetDbConnection()
 	defer db.Close()
 
+	// `category_service migrate [up | down [steps] | status]` runs instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.RunCommand(db, "category_service", dbconn.Migrations, os.Args[2:]); err != nil {
+			log.Fatal().
+				Err(err).
+				Str("service", "category service").
+				Msg("Migrate failed")
+		}
+		return
+	}
+
+	if _, err := migrate.Up(db, "category_service", dbconn.Migrations); err != nil {
+		log.Fatal().
+			Err(err).
+			Str("service", "category service").
+			Msg("Migrations failed")
+	}
+
+	// kafka
+	kafkaUrl := fmt.Sprintf("%v:%v", os.Getenv("KAFKA_HOST"), os.Getenv("KAFKA_PORT"))
+	writer := category_kafka.GetKafkaWriter(kafkaUrl)
+	defer writer.Close()
+
+	go category_kafka.RunKafkaListener(db, writer)
+	go reminders.Run(db, writer, os.Getenv("INTERNAL_SECRET"))
+
 	r := chi.NewRouter()
 
 	r.Post("/api/category", handlers.AddCategory(db))
 	r.Delete("/api/category", handlers.DeleteCategory(db))
+	r.Put("/api/category", handlers.RenameCategory(db))
 	r.Get("/api/category", handlers.GetCategory(db))
+	r.Post("/api/category/import", handlers.ImportCategories(db))
+
+	r.Group(func(r chi.Router) {
+		r.Use(secret.Internal(os.Getenv("INTERNAL_SECRET")))
+		r.Get("/internal/category/export", handlers.ExportCategories(db))
+	})
 
 	log.Info().Msg("category service is running")
 	err := http.ListenAndServe(":8080", r)
@@ -242,3 +242,72 @@ func TestAddCategory_DuplicateNameWithDifferentUser(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp2.StatusCode)
 }
+
+func postCategory(t *testing.T, sessionToken string, category map[string]interface{}) *http.Response {
+	categoryBody, _ := json.Marshal(category)
+
+	req, err := http.NewRequest(
+		"POST",
+		"http://localhost:8080/api/category",
+		bytes.NewBuffer(categoryBody),
+	)
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestAddCategory_WithSchedule(t *testing.T) {
+	creds := map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	}
+
+	sessionToken := loginAndGetToken(t, creds)
+
+	resp := postCategory(t, sessionToken, map[string]interface{}{
+		"name":          fmt.Sprintf("Habit %s", uuid.New().String()),
+		"schedule_days": 31,
+		"remind_time":   "08:30",
+	})
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var category struct {
+		Schedule_days int    `json:"schedule_days"`
+		Remind_time   string `json:"remind_time"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&category))
+	assert.Equal(t, 31, category.Schedule_days)
+	assert.Equal(t, "08:30", category.Remind_time)
+}
+
+func TestAddCategory_InvalidSchedule(t *testing.T) {
+	creds := map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	}
+
+	sessionToken := loginAndGetToken(t, creds)
+
+	for _, category := range []map[string]interface{}{
+		{"name": fmt.Sprintf("Habit %s", uuid.New().String()), "schedule_days": 128},
+		{"name": fmt.Sprintf("Habit %s", uuid.New().String()), "remind_time": "25:00"},
+		{"name": fmt.Sprintf("Habit %s", uuid.New().String()), "remind_time": "8:30"},
+	} {
+		resp := postCategory(t, sessionToken, category)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, category)
+	}
+}
@@ -120,3 +120,31 @@ func TestGetCategory_LastPage(t *testing.T) {
 	assert.Equal(t, http.StatusOK, getResp.StatusCode)
 }
 
+
+func TestGetCategory_RevokedToken(t *testing.T) {
+	sessionToken := loginAndGetToken(t, map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	})
+
+	logoutReq, err := http.NewRequest("POST", "http://localhost:8080/api/auth/logout", nil)
+	require.NoError(t, err)
+	logoutReq.AddCookie(&http.Cookie{Name: "session_token", Value: sessionToken})
+
+	client := &http.Client{}
+	logoutResp, err := client.Do(logoutReq)
+	require.NoError(t, err)
+	defer logoutResp.Body.Close()
+	require.Equal(t, http.StatusOK, logoutResp.StatusCode)
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/category?page=1", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: sessionToken})
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// clients renew the token on 401, a 500 would look like an outage
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,87 @@
+package integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	models "category_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCategories_CSV(t *testing.T) {
+	creds := map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	}
+
+	sessionToken := loginAndGetToken(t, creds)
+
+	name := "Imported " + uuid.NewString()[:8]
+	body := "name\n" + name + "\nHealth\n\n"
+
+	req, err := http.NewRequest(
+		"POST",
+		"http://localhost:8080/api/category/import",
+		strings.NewReader(body),
+	)
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "text/csv")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report models.CategoryImportReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Existing)
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, name, report.Rows[0].Name)
+	assert.NotEqual(t, uuid.Nil, report.Rows[0].Category_id)
+}
+
+func TestImportCategories_JSONRejectsEmptyName(t *testing.T) {
+	creds := map[string]string{
+		"login":    "alice",
+		"password": "alice123",
+	}
+
+	sessionToken := loginAndGetToken(t, creds)
+
+	req, err := http.NewRequest(
+		"POST",
+		"http://localhost:8080/api/category/import",
+		strings.NewReader(`[{"name": "  "}]`),
+	)
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report models.CategoryImportReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+	assert.Equal(t, 1, report.Rejected)
+}
@@ -0,0 +1,111 @@
+package integration_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	models "category_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendCategory(t *testing.T, method string, token string, category map[string]interface{}) *http.Response {
+	body, _ := json.Marshal(category)
+
+	req, err := http.NewRequest(method, "http://localhost:8080/api/category", bytes.NewBuffer(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestRenameCategory_Success(t *testing.T) {
+	token := loginAndGetToken(t, map[string]string{"login": "alice", "password": "alice123"})
+
+	name := fmt.Sprintf("Category name %s", uuid.NewString())
+	resp := sendCategory(t, "POST", token, map[string]interface{}{"name": name})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var created models.CategoryInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	require.NotEqual(t, uuid.Nil, created.Id)
+
+	newName := fmt.Sprintf("Renamed %s", uuid.NewString())
+	renameResp := sendCategory(t, "PUT", token, map[string]interface{}{
+		"category_id": created.Id,
+		"name":        newName,
+	})
+	defer renameResp.Body.Close()
+	require.Equal(t, http.StatusOK, renameResp.StatusCode)
+
+	var renamed models.CategoryInfo
+	require.NoError(t, json.NewDecoder(renameResp.Body).Decode(&renamed))
+	assert.Equal(t, created.Id, renamed.Id)
+	assert.Equal(t, newName, renamed.Name)
+	assert.NotZero(t, renamed.Updated_at)
+
+	deleteResp := sendCategory(t, "DELETE", token, map[string]interface{}{"category_id": created.Id})
+	defer deleteResp.Body.Close()
+	assert.Equal(t, http.StatusOK, deleteResp.StatusCode)
+}
+
+func TestRenameCategory_NameTaken(t *testing.T) {
+	token := loginAndGetToken(t, map[string]string{"login": "alice", "password": "alice123"})
+
+	first := fmt.Sprintf("Category name %s", uuid.NewString())
+	second := fmt.Sprintf("Category name %s", uuid.NewString())
+
+	resp := sendCategory(t, "POST", token, map[string]interface{}{"name": first})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var created models.CategoryInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+	resp2 := sendCategory(t, "POST", token, map[string]interface{}{"name": second})
+	defer resp2.Body.Close()
+	require.Equal(t, http.StatusOK, resp2.StatusCode)
+
+	renameResp := sendCategory(t, "PUT", token, map[string]interface{}{
+		"category_id": created.Id,
+		"name":        second,
+	})
+	defer renameResp.Body.Close()
+	assert.Equal(t, http.StatusConflict, renameResp.StatusCode)
+}
+
+func TestRenameCategory_Unknown(t *testing.T) {
+	token := loginAndGetToken(t, map[string]string{"login": "alice", "password": "alice123"})
+
+	resp := sendCategory(t, "PUT", token, map[string]interface{}{
+		"category_id": uuid.New(),
+		"name":        fmt.Sprintf("Category name %s", uuid.NewString()),
+	})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDeleteCategory_ByName(t *testing.T) {
+	token := loginAndGetToken(t, map[string]string{"login": "alice", "password": "alice123"})
+
+	name := fmt.Sprintf("Category name %s", uuid.NewString())
+	resp := sendCategory(t, "POST", token, map[string]interface{}{"name": name})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	deleteResp := sendCategory(t, "DELETE", token, map[string]interface{}{"name": name})
+	defer deleteResp.Body.Close()
+	assert.Equal(t, http.StatusOK, deleteResp.StatusCode)
+}
@@ -94,20 +94,19 @@ func getUserIdFromToken(r *http.Request) (models.UserInfo, httpError) {
 		return userInfo, httpErr
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Error().Msg("check token in id parse from token")
-		httpErr.Code = resp.StatusCode
-		httpErr.Error = fmt.Errorf("check token error")
-		return userInfo, httpErr
-	}
-
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Error().Msg("auth service check token")
-		httpErr.Code = http.StatusInternalServerError
-		httpErr.Error = fmt.Errorf("auth service check token")
-		httpErr.Msg = "Internal server error"
+		log.Error().Int("status", resp.StatusCode).Msg("auth service check token")
+		httpErr.Error = fmt.Errorf("auth service check token: status %d", resp.StatusCode)
+		// a revoked or expired token is for the client to renew, e.g. the bot asks for a new one
+		if resp.StatusCode == http.StatusUnauthorized {
+			httpErr.Code = http.StatusUnauthorized
+			httpErr.Msg = "Unauthorized"
+		} else {
+			httpErr.Code = http.StatusInternalServerError
+			httpErr.Msg = "Internal server error"
+		}
 		return userInfo, httpErr
 	}
 
@@ -122,6 +121,18 @@ func getUserIdFromToken(r *http.Request) (models.UserInfo, httpError) {
 	return userInfo, httpErr
 }
 
+// validSchedule checks the weekday bits and the HH:MM reminder time of a habit
+func validSchedule(categoryInfo models.CategoryInfo) bool {
+	if categoryInfo.Schedule_days < 0 || categoryInfo.Schedule_days > models.AllDays {
+		return false
+	}
+	if categoryInfo.Remind_time == "" {
+		return true
+	}
+	_, err := time.Parse("15:04", categoryInfo.Remind_time)
+	return err == nil && len(categoryInfo.Remind_time) == len("15:04")
+}
+
 func AddCategory(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userInfo, errInfo := getUserIdFromToken(r)
@@ -144,6 +155,12 @@ func AddCategory(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		if !validSchedule(categoryInfo) {
+			log.Error().Err(fmt.Errorf("invalid category schedule"))
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
 		exists, err := checkCategoryExistence(db, userInfo.User_id, categoryInfo.Name)
 		if err != nil {
 			log.Error().Err(err).Msg("check category existence")
@@ -157,8 +174,8 @@ func AddCategory(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		query := `INSERT INTO categories (id, user_id, name, created_at)
-							VALUES ($1, $2, $3, $4)`
+		query := `INSERT INTO categories (id, user_id, name, schedule_days, remind_time, created_at)
+							VALUES ($1, $2, $3, $4, $5, $6)`
 		categoryId, err := uuid.NewV7()
 		if err != nil {
 			log.Error().Err(err).Msg("new category id generation")
@@ -166,16 +183,38 @@ func AddCategory(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		_, err = db.Exec(query, categoryId, userInfo.User_id, categoryInfo.Name, time.Now().Unix())
+		createdAt := time.Now().Unix()
+
+		_, err = db.Exec(
+			query,
+			categoryId,
+			userInfo.User_id,
+			categoryInfo.Name,
+			categoryInfo.Schedule_days,
+			categoryInfo.Remind_time,
+			createdAt,
+		)
 		if err != nil {
 			log.Error().Err(err).Msg("category creating")
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+
+		categoryInfo.Id = categoryId
+		categoryInfo.User_id = userInfo.User_id
+		categoryInfo.Created_at = createdAt
+		categoryInfo.Updated_at = 0
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(categoryInfo); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
 	}
 }
 
-func DeleteCategory(db *sql.DB) http.HandlerFunc {
+func RenameCategory(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userInfo, errInfo := getUserIdFromToken(r)
 		if errInfo.Error != nil {
@@ -186,7 +225,13 @@ func DeleteCategory(db *sql.DB) http.HandlerFunc {
 		var categoryInfo models.CategoryInfo
 
 		if err := json.NewDecoder(r.Body).Decode(&categoryInfo); err != nil {
-			log.Error().Err(err).Msg("category id json decode")
+			log.Error().Err(err).Msg("category info json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if categoryInfo.Id == uuid.Nil || categoryInfo.Name == "" {
+			log.Error().Err(fmt.Errorf("category id or name is empty"))
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
@@ -198,14 +243,75 @@ func DeleteCategory(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		if !exists {
+		if exists {
+			log.Error().Err(fmt.Errorf("category name already exists"))
+			http.Error(w, "Conflict", http.StatusConflict)
+			return
+		}
+
+		query := `UPDATE categories SET name = $1, updated_at = $2
+							WHERE id = $3 AND user_id = $4
+							RETURNING created_at, schedule_days, remind_time`
+
+		updatedAt := time.Now().Unix()
+		err = db.QueryRow(query, categoryInfo.Name, updatedAt, categoryInfo.Id, userInfo.User_id).
+			Scan(&categoryInfo.Created_at, &categoryInfo.Schedule_days, &categoryInfo.Remind_time)
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Error().Err(fmt.Errorf("category not found"))
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("category renaming")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		categoryInfo.User_id = userInfo.User_id
+		categoryInfo.Updated_at = updatedAt
+
+		log.Info().Msg("Category renamed successfully")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(categoryInfo); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+func DeleteCategory(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var categoryInfo models.CategoryInfo
+
+		if err := json.NewDecoder(r.Body).Decode(&categoryInfo); err != nil {
+			log.Error().Err(err).Msg("category id json decode")
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
 
+		if categoryInfo.Id == uuid.Nil && categoryInfo.Name == "" {
+			log.Error().Err(fmt.Errorf("category id and name are empty"))
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		// a category is addressed by id, or by name when no id is given
 		query := `DELETE FROM categories WHERE id = $1 and user_id = $2`
-		res, err := db.Exec(query, categoryInfo.Id, userInfo.User_id)
+		var key any = categoryInfo.Id
+		if categoryInfo.Id == uuid.Nil {
+			query = `DELETE FROM categories WHERE name = $1 and user_id = $2`
+			key = categoryInfo.Name
+		}
+
+		res, err := db.Exec(query, key, userInfo.User_id)
 		if err != nil {
 			log.Error().Err(err).Msg("category deleting")
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -243,7 +349,7 @@ func GetCategory(db *sql.DB) http.HandlerFunc {
 
 		offset := (page - 1) * pageLimit
 
-		query := `SELECT id, user_id, name, created_at, updated_at
+		query := `SELECT id, user_id, name, schedule_days, remind_time, created_at, updated_at
 							FROM categories
 							WHERE user_id = $1
 							ORDER BY created_at DESC
@@ -267,6 +373,8 @@ func GetCategory(db *sql.DB) http.HandlerFunc {
 				&categoryInfo.Id,
 				&userInfo.User_id,
 				&categoryInfo.Name,
+				&categoryInfo.Schedule_days,
+				&categoryInfo.Remind_time,
 				&createdAt,
 				&updatedAt,
 			)
@@ -0,0 +1,75 @@
+package category_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	models "category_service/internal/models"
+)
+
+// ExportCategories is an internal endpoint used by auth_service to collect
+// every category of a user, it is guarded by the internal secret
+func ExportCategories(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := uuid.Parse(r.URL.Query().Get("user_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("export user id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		query := `SELECT id, user_id, name, created_at, updated_at
+							FROM categories
+							WHERE user_id = $1
+							ORDER BY created_at`
+
+		rows, err := db.Query(query, userId)
+		if err != nil {
+			log.Error().Err(err).Msg("export categories receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		categories := make([]models.CategoryInfo, 0)
+
+		for rows.Next() {
+			var categoryInfo models.CategoryInfo
+			var updatedAt sql.NullInt64
+
+			err := rows.Scan(
+				&categoryInfo.Id,
+				&categoryInfo.User_id,
+				&categoryInfo.Name,
+				&categoryInfo.Created_at,
+				&updatedAt,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("export category scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			categoryInfo.Updated_at = updatedAt.Int64
+
+			categories = append(categories, categoryInfo)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("export categories receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(categories); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
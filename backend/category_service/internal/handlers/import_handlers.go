@@ -0,0 +1,191 @@
+package category_handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	models "category_service/internal/models"
+)
+
+const (
+	importMaxBytes   = 5 << 20 // 5MB
+	importMaxRows    = 5000
+	categoryNameSize = 255
+)
+
+func isCsvRequest(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "csv" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "text/csv"
+}
+
+// parseCategoryRows returns rows with their position in the source:
+// the line number for csv (header is line 1) and the 1-based index for json
+func parseCategoryRows(w http.ResponseWriter, r *http.Request) ([]models.CategoryImportRow, []int, error) {
+	body := http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	var rows []models.CategoryImportRow
+	var positions []int
+
+	if !isCsvRequest(r) {
+		if err := json.NewDecoder(body).Decode(&rows); err != nil {
+			return nil, nil, fmt.Errorf("json decode: %w", err)
+		}
+		for i := range rows {
+			positions = append(positions, i+1)
+		}
+		return rows, positions, nil
+	}
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	nameColumn := -1
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), "name") {
+			nameColumn = i
+		}
+	}
+	if nameColumn < 0 {
+		return nil, nil, fmt.Errorf("csv header: name column not found")
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+
+		var row models.CategoryImportRow
+		if nameColumn < len(record) {
+			row.Name = record[nameColumn]
+		}
+		rows = append(rows, row)
+		positions = append(positions, line)
+	}
+
+	return rows, positions, nil
+}
+
+func importCategory(db *sql.DB, userId uuid.UUID, name string) (uuid.UUID, string, error) {
+	categoryId, err := uuid.NewV7()
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	query := `INSERT INTO categories (id, user_id, name, created_at)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (user_id, name) DO NOTHING`
+	res, err := db.Exec(query, categoryId, userId, name, time.Now().Unix())
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 1 {
+		return categoryId, models.ImportStatusCreated, nil
+	}
+
+	query = `SELECT id FROM categories WHERE user_id = $1 AND name = $2`
+	err = db.QueryRow(query, userId, name).Scan(&categoryId)
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	return categoryId, models.ImportStatusExisting, nil
+}
+
+// ImportCategories creates every missing category from a csv or json upload
+// and reports the id of each row, so callers can map names to ids
+func ImportCategories(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		rows, positions, err := parseCategoryRows(w, r)
+		if err != nil {
+			log.Error().Err(err).Msg("category import parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if len(rows) > importMaxRows {
+			log.Error().Int("rows", len(rows)).Msg("category import too large")
+			http.Error(w, "Too many rows", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		report := models.CategoryImportReport{Rows: make([]models.CategoryImportResult, 0, len(rows))}
+
+		for i, row := range rows {
+			result := models.CategoryImportResult{
+				Row:  positions[i],
+				Name: strings.TrimSpace(row.Name),
+			}
+
+			switch {
+			case result.Name == "":
+				result.Status = models.ImportStatusRejected
+				result.Error = "name is empty"
+			case len(result.Name) > categoryNameSize:
+				result.Status = models.ImportStatusRejected
+				result.Error = "name is too long"
+			default:
+				result.Category_id, result.Status, err = importCategory(db, userInfo.User_id, result.Name)
+				if err != nil {
+					log.Error().Err(err).Msg("category importing")
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+			}
+
+			switch result.Status {
+			case models.ImportStatusCreated:
+				report.Created++
+			case models.ImportStatusExisting:
+				report.Existing++
+			case models.ImportStatusRejected:
+				report.Rejected++
+			}
+
+			report.Rows = append(report.Rows, result)
+		}
+
+		log.Info().
+			Int("created", report.Created).
+			Int("existing", report.Existing).
+			Int("rejected", report.Rejected).
+			Msg("Categories imported")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package category_handlers
+
+import (
+	"database/sql"
+	"time"
+
+	models "category_service/internal/models"
+
+	"shared/events"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	purgeBatchSize = 500
+)
+
+func PurgeUserCategories(db *sql.DB, data []byte) (models.UserPurgedEvent, error) {
+	var event models.UserPurgedEvent
+
+	userId, err := events.ParseDeletedUserId(data)
+	if err != nil {
+		log.Error().Err(err).Msg("user-deleted parse failed")
+		return event, err
+	}
+	log.Info().Str("user_id", userId.String()).Msg("processing user-deleted")
+
+	query := `DELETE FROM categories
+						WHERE id IN (
+							SELECT id FROM categories
+							WHERE user_id = $1
+							LIMIT $2
+						)`
+
+	var purged int64
+	for {
+		res, err := db.Exec(query, userId, purgeBatchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("user categories purge error")
+			return event, err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			log.Error().Err(err).Msg("user categories purge rows affected")
+			return event, err
+		}
+
+		purged += affected
+		if affected < purgeBatchSize {
+			break
+		}
+	}
+
+	log.Info().
+		Str("user_id", userId.String()).
+		Int64("purged", purged).
+		Msg("User categories purged successfully")
+
+	event.User_id = userId
+	event.Service = "category_service"
+	event.Purged_rows = purged
+	event.Purged_at = time.Now().Unix()
+
+	return event, nil
+}
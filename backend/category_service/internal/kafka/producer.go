@@ -0,0 +1,66 @@
+package kafka_listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	models "category_service/internal/models"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func GetKafkaWriter(kafkaURL string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(kafkaURL),
+		Balancer:     &kafka.LeastBytes{},
+		BatchTimeout: 10 * time.Millisecond,
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+func sendUserPurgedEvent(
+	ctx context.Context,
+	writer *kafka.Writer,
+	event models.UserPurgedEvent,
+) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("user-purged marshal error: %w", err)
+	}
+
+	err = writer.WriteMessages(ctx, kafka.Message{
+		Topic: "user-purged",
+		Key:   []byte(event.User_id.String()),
+		Value: data,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka user-purged message error: %w", err)
+	}
+
+	return nil
+}
+
+// SendReminderEvent hands the reminder of a habit to the telegram bot
+func SendReminderEvent(
+	ctx context.Context,
+	writer *kafka.Writer,
+	event models.ReminderEvent,
+) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("note-reminder marshal error: %w", err)
+	}
+
+	err = writer.WriteMessages(ctx, kafka.Message{
+		Topic: "note-reminder",
+		Key:   []byte(event.User_id.String()),
+		Value: data,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka note-reminder message error: %w", err)
+	}
+
+	return nil
+}
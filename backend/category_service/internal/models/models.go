@@ -3,13 +3,64 @@ package models
 import "github.com/google/uuid"
 
 type CategoryInfo struct {
-	Id         uuid.UUID `json:"category_id"`
-	User_id    uuid.UUID `json:"user_id"`
-	Name       string    `json:"name"`
-	Created_at int64     `json:"created_at"`
-	Updated_at int64     `json:"updated_at"`
+	Id            uuid.UUID `json:"category_id"`
+	User_id       uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	Schedule_days int       `json:"schedule_days"`
+	Remind_time   string    `json:"remind_time"`
+	Created_at    int64     `json:"created_at"`
+	Updated_at    int64     `json:"updated_at"`
+}
+
+// AllDays is the schedule of a habit done every day, bit 0 is monday
+const AllDays = 1<<7 - 1
+
+// ReminderEvent asks the telegram bot to remind the user of a habit at remind_at
+type ReminderEvent struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Category_id uuid.UUID `json:"category_id"`
+	Content     string    `json:"content"`
+	Remind_at   int64     `json:"remind_at"`
+}
+
+// UserTimezone comes from user_service, users without one are in UTC
+type UserTimezone struct {
+	User_id  uuid.UUID `json:"user_id"`
+	Timezone string    `json:"timezone"`
 }
 
 type UserInfo struct {
 	User_id uuid.UUID `json:"user_id"`
 }
+
+type UserPurgedEvent struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Service     string    `json:"service"`
+	Purged_rows int64     `json:"purged_rows"`
+	Purged_at   int64     `json:"purged_at"`
+}
+
+const (
+	ImportStatusCreated  = "created"
+	ImportStatusExisting = "existing"
+	ImportStatusRejected = "rejected"
+)
+
+type CategoryImportRow struct {
+	Name string `json:"name"`
+}
+
+type CategoryImportResult struct {
+	Row         int       `json:"row"`
+	Name        string    `json:"name"`
+	Category_id uuid.UUID `json:"category_id"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type CategoryImportReport struct {
+	Created  int                    `json:"created"`
+	Existing int                    `json:"existing"`
+	Rejected int                    `json:"rejected"`
+	Rows     []CategoryImportResult `json:"rows"`
+}
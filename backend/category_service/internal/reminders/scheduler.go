@@ -0,0 +1,197 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	category_kafka "category_service/internal/kafka"
+	models "category_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const timezonesUrl = "http://user_service:8080/internal/user/timezones"
+
+// the habits are checked this often, a reminder goes out within a check of remind_time
+const checkInterval = time.Minute
+
+// a habit whose remind_time passed longer ago is not reminded of that day,
+// e.g. after the service was down, the bot drops such reminders as well
+const maxDelay = time.Hour
+
+type habit struct {
+	id           uuid.UUID
+	userId       uuid.UUID
+	name         string
+	scheduleDays int
+	remindTime   string
+}
+
+// Run reminds of the habits whose remind_time passed on a scheduled day in
+// the timezone of the user, each habit once a day, through the note-reminder topic
+func Run(db *sql.DB, writer *kafka.Writer, internalSecret string) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("habit reminders are scheduled")
+
+	for now := range ticker.C {
+		check(db, writer, internalSecret, now)
+	}
+}
+
+func check(db *sql.DB, writer *kafka.Writer, internalSecret string, now time.Time) {
+	habits, err := getHabits(db)
+	if err != nil {
+		log.Error().Err(err).Msg("habits receiving")
+		return
+	}
+	if len(habits) == 0 {
+		return
+	}
+
+	// reminding in UTC instead of the zone of the user would be hours off, so
+	// the check is skipped until user_service answers
+	timezones, err := getTimezones(internalSecret)
+	if err != nil {
+		log.Error().Err(err).Msg("user timezones receiving")
+		return
+	}
+
+	for _, h := range habits {
+		due, ok := dueAt(h.scheduleDays, h.remindTime, location(timezones[h.userId]), now)
+		if !ok {
+			continue
+		}
+		if err := remind(db, writer, h, due); err != nil {
+			log.Error().Err(err).Str("category_id", h.id.String()).Msg("habit reminder")
+		}
+	}
+}
+
+func getHabits(db *sql.DB) ([]habit, error) {
+	query := `SELECT id, user_id, name, schedule_days, remind_time
+						FROM categories
+						WHERE schedule_days <> 0 AND remind_time <> ''`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var habits []habit
+	for rows.Next() {
+		var h habit
+		if err := rows.Scan(&h.id, &h.userId, &h.name, &h.scheduleDays, &h.remindTime); err != nil {
+			return nil, err
+		}
+		habits = append(habits, h)
+	}
+
+	return habits, rows.Err()
+}
+
+// getTimezones asks user_service for the timezones of the users who set one
+func getTimezones(internalSecret string) (map[uuid.UUID]string, error) {
+	req, err := http.NewRequest("GET", timezonesUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Internal-Secret", internalSecret)
+
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user timezones: status %d", resp.StatusCode)
+	}
+
+	var users []models.UserTimezone
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, err
+	}
+
+	timezones := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		timezones[user.User_id] = user.Timezone
+	}
+	return timezones, nil
+}
+
+// location falls back to UTC like the digests of the bot
+func location(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// dueAt returns the remind time of the habit today in loc, ok when the day is
+// scheduled and the time passed at most maxDelay ago; bit 0 of the schedule is monday
+func dueAt(scheduleDays int, remindTime string, loc *time.Location, now time.Time) (time.Time, bool) {
+	local := now.In(loc)
+	weekday := (int(local.Weekday()) + 6) % 7
+	if scheduleDays&(1<<weekday) == 0 {
+		return time.Time{}, false
+	}
+
+	clock, err := time.Parse("15:04", remindTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	due := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	late := local.Sub(due)
+	return due, late >= 0 && late <= maxDelay
+}
+
+// remind claims the day of the habit and sends the reminder in one transaction,
+// a failed send rolls the claim back so the next check retries; another
+// instance of the service which claimed the day first makes this a no-op
+func remind(db *sql.DB, writer *kafka.Writer, h habit, due time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	day := due.Format("2006-01-02")
+	query := `UPDATE categories SET reminded_on = $2
+						WHERE id = $1 AND (reminded_on IS NULL OR reminded_on < $2)`
+	res, err := tx.Exec(query, h.id, day)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event := models.ReminderEvent{
+		User_id:     h.userId,
+		Category_id: h.id,
+		Content:     h.name,
+		Remind_at:   due.Unix(),
+	}
+	if err := category_kafka.SendReminderEvent(ctx, writer, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,6 +1,7 @@
+-- IF NOT EXISTS adopts databases created by the old db/init/schema.sql
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-CREATE TABLE categories (
+CREATE TABLE IF NOT EXISTS categories (
   id UUID PRIMARY KEY,
   user_id UUID NOT NULL,
   name VARCHAR(255) NOT NULL,
@@ -8,5 +9,5 @@ CREATE TABLE categories (
   updated_at BIGINT
 );
 
-CREATE UNIQUE INDEX unique_user_category_name
+CREATE UNIQUE INDEX IF NOT EXISTS unique_user_category_name
   ON categories(user_id, name);
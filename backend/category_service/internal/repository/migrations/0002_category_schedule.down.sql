@@ -0,0 +1,2 @@
+ALTER TABLE categories DROP COLUMN IF EXISTS remind_time;
+ALTER TABLE categories DROP COLUMN IF EXISTS schedule_days;
@@ -0,0 +1,4 @@
+-- a category with a schedule is a habit, schedule_days has a bit per weekday
+-- starting with monday, 0 means no schedule; remind_time is HH:MM or empty
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS schedule_days SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS remind_time VARCHAR(5) NOT NULL DEFAULT '';
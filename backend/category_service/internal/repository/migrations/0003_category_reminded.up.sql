@@ -0,0 +1,3 @@
+-- reminded_on is the day in the timezone of the user the habit was last
+-- reminded of, the scheduler claims a day by setting it so it reminds once
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS reminded_on DATE;
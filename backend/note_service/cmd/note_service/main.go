@@ -2,10 +2,17 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"os"
+
 	handlers "note_service/internal/handlers"
+	note_kafka "note_service/internal/kafka"
 	dbconn "note_service/internal/repository"
 
+	"shared/migrate"
+	"shared/secret"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
 )
@@ -14,11 +21,46 @@ func main() {
 	var db *sql.DB = dbconn.GetDbConnection()
 	defer db.Close()
 
+	// `note_service migrate [up | down [steps] | status]` runs instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.RunCommand(db, "note_service", dbconn.Migrations, os.Args[2:]); err != nil {
+			log.Fatal().
+				Err(err).
+				Str("service", "note service").
+				Msg("Migrate failed")
+		}
+		return
+	}
+
+	if _, err := migrate.Up(db, "note_service", dbconn.Migrations); err != nil {
+		log.Fatal().
+			Err(err).
+			Str("service", "note service").
+			Msg("Migrations failed")
+	}
+
+	// kafka
+	kafkaUrl := fmt.Sprintf("%v:%v", os.Getenv("KAFKA_HOST"), os.Getenv("KAFKA_PORT"))
+	writer := note_kafka.GetKafkaWriter(kafkaUrl)
+	defer writer.Close()
+
+	go note_kafka.RunKafkaListener(db, writer)
+
 	r := chi.NewRouter()
 
 	r.Post("/api/note", handlers.AddNote(db))
 	r.Delete("/api/note", handlers.DeleteNote(db))
 	r.Get("/api/note", handlers.GetNote(db))
+	r.Post("/api/note/import", handlers.ImportNotes(db))
+	r.Get("/api/note/changes", handlers.GetNoteChanges(db))
+	r.Post("/api/note/sync", handlers.PushNoteChanges(db))
+	r.Get("/api/note/search", handlers.SearchNotes(db))
+	r.Get("/api/note/{note_id}", handlers.GetNoteById(db))
+
+	r.Group(func(r chi.Router) {
+		r.Use(secret.Internal(os.Getenv("INTERNAL_SECRET")))
+		r.Get("/internal/note/export", handlers.ExportNotes(db))
+	})
 
 	log.Info().Msg("Note service is running")
 	err := http.ListenAndServe(":8080", r)
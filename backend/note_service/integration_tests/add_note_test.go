@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -168,3 +169,76 @@ func TestAddNote_EmptyBody(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
+
+func postNote(t *testing.T, token string, note map[string]interface{}) *http.Response {
+	body, _ := json.Marshal(note)
+
+	req, err := http.NewRequest("POST", "http://localhost:8080/api/note", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: token,
+	})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestAddNote_ClientIdIdempotent(t *testing.T) {
+	token := loginAsAlice(t)
+
+	noteId := uuid.Must(uuid.NewV7()).String()
+	createdAt := time.Now().Add(-time.Hour).Unix()
+	note := map[string]interface{}{
+		"note_id":    noteId,
+		"content":    "Offline note",
+		"created_at": createdAt,
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := postNote(t, token, note)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var stored map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&stored))
+		assert.Equal(t, noteId, stored["note_id"])
+		assert.Equal(t, float64(createdAt), stored["created_at"])
+	}
+}
+
+func TestAddNote_ClientIdConflict(t *testing.T) {
+	token := loginAsAlice(t)
+
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	resp := postNote(t, token, map[string]interface{}{"note_id": noteId, "content": "First"})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postNote(t, token, map[string]interface{}{"note_id": noteId, "content": "Second"})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestAddNote_CreatedAtOutOfRange(t *testing.T) {
+	token := loginAsAlice(t)
+
+	resp := postNote(t, token, map[string]interface{}{
+		"content":    "From the future",
+		"created_at": time.Now().Add(24 * time.Hour).Unix(),
+	})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postNote(t, token, map[string]interface{}{
+		"content":    "Too old",
+		"created_at": time.Now().Add(-365 * 24 * time.Hour).Unix(),
+	})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -135,7 +135,7 @@ func TestDeleteNote_NonexistentID(t *testing.T) {
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
 func TestDeleteNote_InvalidUUID(t *testing.T) {
@@ -348,5 +348,5 @@ func TestDeleteNote_ForeignUser(t *testing.T) {
 	require.NoError(t, err)
 	defer delResp.Body.Close()
 
-	assert.Equal(t, http.StatusBadRequest, delResp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, delResp.StatusCode)
 }
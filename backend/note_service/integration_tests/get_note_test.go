@@ -171,3 +171,85 @@ func TestGetNotes_InvalidPage(t *testing.T) {
 		assert.IsType(t, []map[string]interface{}{}, notes)
 	}
 }
+
+// logout revokes the session token, auth_service answers 401 for it afterwards
+func logout(t *testing.T, token string) {
+	req, err := http.NewRequest("POST", "http://localhost:8080/api/auth/logout", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGetNotes_RevokedToken(t *testing.T) {
+	token := loginAsAlice(t)
+	logout(t, token)
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/note?page=1", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// clients renew the token on 401, a 500 would look like an outage
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func getNoteById(t *testing.T, token string, noteId string) *http.Response {
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/note/"+noteId, nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestGetNoteById_Success(t *testing.T) {
+	token := loginAsAlice(t)
+
+	noteId := uuid.Must(uuid.NewV7()).String()
+	createResp := postNote(t, token, map[string]interface{}{"note_id": noteId, "content": "Single note"})
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusOK, createResp.StatusCode)
+
+	resp := getNoteById(t, token, noteId)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var note map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&note))
+	assert.Equal(t, noteId, note["note_id"])
+	assert.Equal(t, "Single note", note["content"])
+}
+
+func TestGetNoteById_ForeignUser(t *testing.T) {
+	noteId := uuid.Must(uuid.NewV7()).String()
+	createResp := postNote(t, loginAsAlice(t), map[string]interface{}{"note_id": noteId, "content": "Alice only"})
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusOK, createResp.StatusCode)
+
+	resp := getNoteById(t, loginAs(t, "bob", "bob123"), noteId)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetNoteById_Nonexistent(t *testing.T) {
+	resp := getNoteById(t, loginAsAlice(t), uuid.NewString())
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetNoteById_InvalidUUID(t *testing.T) {
+	resp := getNoteById(t, loginAsAlice(t), "not-a-uuid")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,171 @@
+package note_integration_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loginAsAlice(t *testing.T) string {
+	return loginAs(t, "alice", "alice123")
+}
+
+func loginAs(t *testing.T, login, password string) string {
+	creds := map[string]string{
+		"login":    login,
+		"password": password,
+	}
+	loginBody, _ := json.Marshal(creds)
+
+	loginResp, err := http.Post(
+		"http://localhost:8080/api/auth/login",
+		"application/json",
+		bytes.NewBuffer(loginBody),
+	)
+	require.NoError(t, err)
+	defer loginResp.Body.Close()
+	require.Equal(t, http.StatusOK, loginResp.StatusCode)
+
+	var token string
+	for _, c := range loginResp.Cookies() {
+		if c.Name == "session_token" {
+			token = c.Value
+			break
+		}
+	}
+	require.NotEmpty(t, token)
+
+	return token
+}
+
+func postImport(t *testing.T, token, contentType, body string) (*http.Response, map[string]interface{}) {
+	req, err := http.NewRequest(
+		"POST",
+		"http://localhost:8080/api/note/import",
+		strings.NewReader(body),
+	)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var report map[string]interface{}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	}
+
+	return resp, report
+}
+
+func TestImportNotes_CSV(t *testing.T) {
+	token := loginAsAlice(t)
+	suffix := uuid.NewString()[:8]
+
+	csvBody := "client_id,category,content,created_at,completed\n" +
+		"row-1-" + suffix + ",Import " + suffix + ",First imported note,2024-03-01,true\n" +
+		"row-2-" + suffix + ",Import " + suffix + ",Second imported note,1709300000,false\n" +
+		"row-3-" + suffix + ",,,2024-03-01,false\n"
+
+	resp, report := postImport(t, token, "text/csv", csvBody)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, float64(2), report["imported"])
+	assert.Equal(t, float64(1), report["rejected"])
+
+	_, report = postImport(t, token, "text/csv", csvBody)
+	assert.Equal(t, float64(0), report["imported"])
+	assert.Equal(t, float64(2), report["duplicates"])
+}
+
+func TestImportNotes_JSON(t *testing.T) {
+	token := loginAsAlice(t)
+	clientId := uuid.NewString()
+
+	rows := []map[string]interface{}{
+		{"client_id": clientId, "content": "Imported from json", "created_at": 1709300000},
+		{"client_id": clientId, "content": "Same id in one file"},
+		{"content": "Bad time", "created_at": "yesterday"},
+	}
+	body, _ := json.Marshal(rows)
+
+	resp, report := postImport(t, token, "application/json", string(body))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, float64(1), report["imported"])
+	assert.Equal(t, float64(1), report["duplicates"])
+	assert.Equal(t, float64(1), report["rejected"])
+}
+
+func TestImportNotes_ForeignId(t *testing.T) {
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	resp := postNote(t, loginAsAlice(t), map[string]interface{}{"note_id": noteId, "content": "Alice's note"})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := json.Marshal([]map[string]interface{}{{"client_id": noteId, "content": "Bob's note"}})
+	resp, report := postImport(t, loginAs(t, "bob", "bob123"), "application/json", string(body))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, float64(0), report["imported"])
+	assert.Equal(t, float64(0), report["duplicates"])
+	assert.Equal(t, float64(1), report["rejected"])
+}
+
+func TestImportNotes_DeletedId(t *testing.T) {
+	token := loginAsAlice(t)
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	resp := postNote(t, token, map[string]interface{}{"note_id": noteId, "content": "Deleted note"})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	delBody, _ := json.Marshal(map[string]string{"note_id": noteId})
+	req, _ := http.NewRequest("DELETE", "http://localhost:8080/api/note", bytes.NewBuffer(delBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	delResp, err := client.Do(req)
+	require.NoError(t, err)
+	defer delResp.Body.Close()
+	require.Equal(t, http.StatusOK, delResp.StatusCode)
+
+	body, _ := json.Marshal([]map[string]interface{}{
+		{"client_id": noteId, "content": "Deleted note", "created_at": 1709300000},
+	})
+	resp, report := postImport(t, token, "application/json", string(body))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, float64(0), report["imported"])
+	assert.Equal(t, float64(1), report["rejected"])
+}
+
+func TestImportNotes_InvalidBody(t *testing.T) {
+	token := loginAsAlice(t)
+
+	resp, _ := postImport(t, token, "application/json", `{"content":`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestImportNotes_MissingToken(t *testing.T) {
+	resp, err := http.Post(
+		"http://localhost:8080/api/note/import",
+		"application/json",
+		strings.NewReader("[]"),
+	)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,78 @@
+package note_integration_tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchNotes(t *testing.T, token string, query string) *http.Response {
+	req, err := http.NewRequest(
+		"GET",
+		"http://localhost:8080/api/note/search?q="+url.QueryEscape(query),
+		nil,
+	)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func TestSearchNotes_ByPrefix(t *testing.T) {
+	token := loginAsAlice(t)
+
+	word := "searchable" + uuid.NewString()[:8]
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	resp := postNote(t, token, map[string]interface{}{
+		"note_id": noteId,
+		"content": "Read a book about " + word,
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	searchResp := searchNotes(t, token, "book "+word[:len(word)-3])
+	defer searchResp.Body.Close()
+	require.Equal(t, http.StatusOK, searchResp.StatusCode)
+
+	var notes []interface{}
+	require.NoError(t, json.NewDecoder(searchResp.Body).Decode(&notes))
+	assert.True(t, containsNote(notes, noteId))
+}
+
+func TestSearchNotes_NoMatch(t *testing.T) {
+	token := loginAsAlice(t)
+
+	resp := searchNotes(t, token, "nomatch"+uuid.NewString()[:8])
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var notes []interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&notes))
+	assert.Empty(t, notes)
+}
+
+func TestSearchNotes_EmptyQuery(t *testing.T) {
+	token := loginAsAlice(t)
+
+	resp := searchNotes(t, token, " !? ")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchNotes_MissingToken(t *testing.T) {
+	resp, err := http.Get("http://localhost:8080/api/note/search?q=book")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
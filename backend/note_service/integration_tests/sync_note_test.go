@@ -0,0 +1,238 @@
+package note_integration_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pushChanges(t *testing.T, token string, changes map[string]interface{}) map[string]interface{} {
+	body, _ := json.Marshal(changes)
+
+	req, err := http.NewRequest("POST", "http://localhost:8080/api/note/sync", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	return result
+}
+
+func getChanges(t *testing.T, token string, cursor int64) map[string]interface{} {
+	return getChangesPage(t, token, fmt.Sprintf("cursor=%d", cursor))
+}
+
+func getChangesPage(t *testing.T, token string, params string) map[string]interface{} {
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/note/changes?"+params, nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var changes map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&changes))
+
+	return changes
+}
+
+func containsNote(items []interface{}, noteId string) bool {
+	for _, item := range items {
+		if item.(map[string]interface{})["note_id"] == noteId {
+			return true
+		}
+	}
+	return false
+}
+
+func changesCursor(changes map[string]interface{}) int64 {
+	return int64(changes["cursor"].(float64))
+}
+
+// latestCursor pages through the whole feed of the user
+func latestCursor(t *testing.T, token string) int64 {
+	var cursor int64
+	for {
+		changes := getChanges(t, token, cursor)
+		cursor = changesCursor(changes)
+		if changes["has_more"] != true {
+			return cursor
+		}
+	}
+}
+
+func TestSyncNotes_PushAndPull(t *testing.T) {
+	token := loginAsAlice(t)
+
+	cursor := latestCursor(t, token)
+	now := time.Now().Unix()
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	result := pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": noteId, "content": "Synced note", "created_at": now, "updated_at": now},
+		},
+	})
+	assert.Len(t, result["applied"], 1)
+
+	changes := getChanges(t, token, cursor)
+	assert.True(t, containsNote(changes["notes"].([]interface{}), noteId))
+	assert.Greater(t, changesCursor(changes), cursor)
+
+	result = pushChanges(t, token, map[string]interface{}{
+		"deleted": []map[string]interface{}{
+			{"note_id": noteId, "deleted_at": now + 1},
+		},
+	})
+	assert.Len(t, result["applied"], 1)
+
+	changes = getChanges(t, token, cursor)
+	assert.False(t, containsNote(changes["notes"].([]interface{}), noteId))
+	assert.True(t, containsNote(changes["deleted"].([]interface{}), noteId))
+
+	// nothing changed after the last pull
+	changes = getChanges(t, token, changesCursor(changes))
+	assert.Empty(t, changes["notes"])
+	assert.Empty(t, changes["deleted"])
+}
+
+func TestSyncNotes_LateOfflineChangeIsPulled(t *testing.T) {
+	token := loginAsAlice(t)
+
+	now := time.Now().Unix()
+	noteId := uuid.Must(uuid.NewV7()).String()
+	deletedId := uuid.Must(uuid.NewV7()).String()
+
+	pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": noteId, "content": "Original", "created_at": now - 600, "updated_at": now - 600},
+			{"note_id": deletedId, "content": "Deleted later", "created_at": now - 600, "updated_at": now - 600},
+		},
+	})
+
+	// device B pulls, then device A comes online with an edit and a delete made before that pull
+	cursor := latestCursor(t, token)
+
+	result := pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": noteId, "content": "Edited offline", "created_at": now - 600, "updated_at": now - 300},
+		},
+		"deleted": []map[string]interface{}{
+			{"note_id": deletedId, "deleted_at": now - 300},
+		},
+	})
+	assert.Len(t, result["applied"], 2)
+
+	changes := getChanges(t, token, cursor)
+	assert.True(t, containsNote(changes["notes"].([]interface{}), noteId))
+	assert.True(t, containsNote(changes["deleted"].([]interface{}), deletedId))
+}
+
+func TestSyncNotes_LastWriterWins(t *testing.T) {
+	token := loginAsAlice(t)
+
+	now := time.Now().Unix()
+	noteId := uuid.Must(uuid.NewV7()).String()
+
+	pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": noteId, "content": "Newer", "created_at": now - 10, "updated_at": now},
+		},
+	})
+
+	result := pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": noteId, "content": "Older", "created_at": now - 10, "updated_at": now - 5},
+		},
+	})
+
+	conflicts := result["conflicts"].([]interface{})
+	require.Len(t, conflicts, 1)
+
+	conflict := conflicts[0].(map[string]interface{})
+	assert.Equal(t, "newer_on_server", conflict["reason"])
+	assert.Equal(t, "Newer", conflict["note"].(map[string]interface{})["content"])
+}
+
+func TestSyncNotes_ChangesArePaged(t *testing.T) {
+	token := loginAsAlice(t)
+
+	cursor := latestCursor(t, token)
+	now := time.Now().Unix()
+
+	var notes []map[string]interface{}
+	for i := 0; i < 3; i++ {
+		notes = append(notes, map[string]interface{}{
+			"note_id":    uuid.Must(uuid.NewV7()).String(),
+			"content":    fmt.Sprintf("Paged note %d", i),
+			"created_at": now,
+			"updated_at": now,
+		})
+	}
+	pushChanges(t, token, map[string]interface{}{"notes": notes})
+
+	first := getChangesPage(t, token, fmt.Sprintf("cursor=%d&limit=2", cursor))
+	assert.Len(t, first["notes"], 2)
+	assert.Equal(t, true, first["has_more"])
+
+	second := getChangesPage(t, token, fmt.Sprintf("cursor=%d&limit=2", changesCursor(first)))
+	assert.Len(t, second["notes"], 1)
+	assert.Equal(t, false, second["has_more"])
+	assert.True(t, containsNote(second["notes"].([]interface{}), notes[2]["note_id"].(string)))
+}
+
+func TestSyncNotes_InvalidCursor(t *testing.T) {
+	token := loginAsAlice(t)
+
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/note/changes?cursor=abc", nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: token})
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSyncNotes_RejectsNewNoteOutOfRange(t *testing.T) {
+	token := loginAsAlice(t)
+
+	now := time.Now().Unix()
+	backdated := uuid.Must(uuid.NewV7()).String()
+	future := uuid.Must(uuid.NewV7()).String()
+
+	// AddNote answers 400 for these, the sync push must not take them either
+	result := pushChanges(t, token, map[string]interface{}{
+		"notes": []map[string]interface{}{
+			{"note_id": backdated, "content": "Backdated", "created_at": now - 60*24*60*60, "updated_at": now},
+			{"note_id": future, "content": "Future", "created_at": now + 60*60, "updated_at": now},
+		},
+	})
+
+	assert.Empty(t, result["applied"])
+	conflicts := result["conflicts"].([]interface{})
+	require.Len(t, conflicts, 2)
+	for _, conflict := range conflicts {
+		assert.Equal(t, "invalid_note", conflict.(map[string]interface{})["reason"])
+	}
+}
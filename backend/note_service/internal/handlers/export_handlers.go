@@ -0,0 +1,83 @@
+package note_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	models "note_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportNotes is an internal endpoint used by auth_service to collect
+// every note of a user, it is guarded by the internal secret
+func ExportNotes(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := uuid.Parse(r.URL.Query().Get("user_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("export user id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		query := `SELECT id, category_id, content, created_at, updated_at, ended_at, completed
+							FROM notes
+							WHERE user_id = $1
+							ORDER BY created_at`
+
+		rows, err := db.Query(query, userId)
+		if err != nil {
+			log.Error().Err(err).Msg("export notes receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		notes := make([]models.NoteInfo, 0)
+
+		for rows.Next() {
+			var noteInfo models.NoteInfo
+			var categoryId sql.NullString
+			var updatedAt, endedAt sql.NullInt64
+
+			err := rows.Scan(
+				&noteInfo.Id,
+				&categoryId,
+				&noteInfo.Content,
+				&noteInfo.Created_at,
+				&updatedAt,
+				&endedAt,
+				&noteInfo.Completed,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("export note scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if categoryId.Valid {
+				noteInfo.Category_id, _ = uuid.Parse(categoryId.String)
+			}
+			noteInfo.Updated_at = updatedAt.Int64
+			noteInfo.Ended_at = endedAt.Int64
+
+			notes = append(notes, noteInfo)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("export notes receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(notes); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
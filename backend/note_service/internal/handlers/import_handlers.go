@@ -0,0 +1,456 @@
+package note_handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	models "note_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	importMaxBytes = 5 << 20 // 5MB
+	importMaxRows  = 5000
+)
+
+// importNamespace scopes non uuid client ids, so the same spreadsheet
+// imported twice by one user maps every row to the same note id
+var importNamespace = uuid.MustParse("6f1c2a0e-4d6b-4a43-9b0e-8c7d3f1e5a21")
+
+var importColumns = []string{"client_id", "category", "content", "created_at", "ended_at", "completed"}
+
+func isCsvRequest(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "csv" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "text/csv"
+}
+
+func importRowFromMap(values map[string]string) models.NoteImportRow {
+	return models.NoteImportRow{
+		Client_id:  values["client_id"],
+		Category:   values["category"],
+		Content:    values["content"],
+		Created_at: values["created_at"],
+		Ended_at:   values["ended_at"],
+		Completed:  values["completed"],
+	}
+}
+
+// parseNoteRows returns rows with their position in the source:
+// the line number for csv (header is line 1) and the 1-based index for json
+func parseNoteRows(w http.ResponseWriter, r *http.Request) ([]models.NoteImportRow, []int, error) {
+	body := http.MaxBytesReader(w, r.Body, importMaxBytes)
+
+	var rows []models.NoteImportRow
+	var positions []int
+
+	if !isCsvRequest(r) {
+		// values may come as numbers or booleans, keep them as text until validation
+		var raw []map[string]any
+
+		decoder := json.NewDecoder(body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, nil, fmt.Errorf("json decode: %w", err)
+		}
+
+		for i, item := range raw {
+			values := make(map[string]string, len(item))
+			for key, value := range item {
+				if value != nil {
+					values[key] = fmt.Sprint(value)
+				}
+			}
+			rows = append(rows, importRowFromMap(values))
+			positions = append(positions, i+1)
+		}
+		return rows, positions, nil
+	}
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["content"]; !ok {
+		return nil, nil, fmt.Errorf("csv header: content column not found")
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+
+		values := make(map[string]string)
+		for _, column := range importColumns {
+			if i, ok := columns[column]; ok && i < len(record) {
+				values[column] = record[i]
+			}
+		}
+		rows = append(rows, importRowFromMap(values))
+		positions = append(positions, line)
+	}
+
+	return rows, positions, nil
+}
+
+// parseImportTime accepts unix seconds, RFC 3339 and plain dates
+func parseImportTime(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return unix, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid time %q", value)
+}
+
+func importNoteId(userId uuid.UUID, clientId string) (uuid.UUID, error) {
+	if clientId == "" {
+		return uuid.NewV7()
+	}
+	if noteId, err := uuid.Parse(clientId); err == nil {
+		return noteId, nil
+	}
+	return uuid.NewSHA1(importNamespace, []byte(userId.String()+":"+clientId)), nil
+}
+
+// validateImportRow fills the note from the row or reports why the row is rejected
+func validateImportRow(row models.NoteImportRow, now time.Time) (models.NoteInfo, string) {
+	var note models.NoteInfo
+
+	note.Content = strings.TrimSpace(row.Content)
+	if note.Content == "" {
+		return note, "content is empty"
+	}
+
+	createdAt, err := parseImportTime(row.Created_at)
+	if err != nil {
+		return note, "invalid created_at"
+	}
+	if createdAt == 0 {
+		createdAt = now.Unix()
+	}
+	note.Created_at = createdAt
+	note.Updated_at = createdAt
+
+	endedAt, err := parseImportTime(row.Ended_at)
+	if err != nil {
+		return note, "invalid ended_at"
+	}
+	if endedAt != 0 && endedAt < createdAt {
+		return note, "ended_at is before created_at"
+	}
+	note.Ended_at = endedAt
+
+	if completed := strings.TrimSpace(row.Completed); completed != "" {
+		note.Completed, err = strconv.ParseBool(completed)
+		if err != nil {
+			return note, "invalid completed"
+		}
+	}
+
+	return note, ""
+}
+
+// resolveCategories maps category names to ids through category_service,
+// which creates the missing ones on behalf of the same session
+func resolveCategories(r *http.Request, names []string) (map[string]uuid.UUID, error) {
+	categories := make(map[string]uuid.UUID)
+	if len(names) == 0 {
+		return categories, nil
+	}
+
+	payload := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		payload = append(payload, map[string]string{"name": name})
+	}
+	body, _ := json.Marshal(payload)
+
+	req, err := http.NewRequestWithContext(
+		r.Context(),
+		http.MethodPost,
+		"http://category_service:8080/api/category/import",
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("category import request: %w", err)
+	}
+
+	session_cookie, err := r.Cookie("session_token")
+	if err != nil {
+		return nil, fmt.Errorf("category import cookie: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: session_cookie.Value})
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("category import request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("category import status code: %d", resp.StatusCode)
+	}
+
+	var report models.CategoryImportReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("category import decode: %w", err)
+	}
+
+	for _, row := range report.Rows {
+		if row.Category_id != uuid.Nil {
+			categories[row.Name] = row.Category_id
+		}
+	}
+
+	return categories, nil
+}
+
+func ImportNotes(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		rows, positions, err := parseNoteRows(w, r)
+		if err != nil {
+			log.Error().Err(err).Msg("note import parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if len(rows) > importMaxRows {
+			log.Error().Int("rows", len(rows)).Msg("note import too large")
+			http.Error(w, "Too many rows", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		now := time.Now()
+		report := models.NoteImportReport{Rows: make([]models.NoteImportResult, len(rows))}
+		notes := make([]models.NoteInfo, len(rows))
+
+		var categoryNames []string
+		seenNames := make(map[string]bool)
+		seenIds := make(map[uuid.UUID]bool)
+
+		for i, row := range rows {
+			result := &report.Rows[i]
+			result.Row = positions[i]
+			result.Client_id = strings.TrimSpace(row.Client_id)
+
+			note, reason := validateImportRow(row, now)
+			if reason != "" {
+				result.Status = models.ImportStatusRejected
+				result.Error = reason
+				continue
+			}
+
+			note.Id, err = importNoteId(userInfo.User_id, result.Client_id)
+			if err != nil {
+				log.Error().Err(err).Msg("import note id generation")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if seenIds[note.Id] {
+				result.Status = models.ImportStatusDuplicate
+				continue
+			}
+			seenIds[note.Id] = true
+
+			name := strings.TrimSpace(row.Category)
+			if name != "" && !seenNames[name] {
+				seenNames[name] = true
+				categoryNames = append(categoryNames, name)
+			}
+
+			notes[i] = note
+		}
+
+		categories, err := resolveCategories(r, categoryNames)
+		if err != nil {
+			log.Error().Err(err).Msg("import categories resolving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error().Err(err).Msg("import transaction begin")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := lockChanges(tx, userInfo.User_id, false); err != nil {
+			log.Error().Err(err).Msg("import lock")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		query := `INSERT INTO notes (id, user_id, category_id, content, created_at, updated_at, ended_at, completed)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+							ON CONFLICT (id) DO NOTHING`
+
+		for i, row := range rows {
+			result := &report.Rows[i]
+			if result.Status != "" {
+				continue
+			}
+
+			note := notes[i]
+
+			var categoryId sql.NullString
+			if name := strings.TrimSpace(row.Category); name != "" {
+				id, ok := categories[name]
+				if !ok {
+					result.Status = models.ImportStatusRejected
+					result.Error = "invalid category"
+					continue
+				}
+				note.Category_id = id
+				categoryId = sql.NullString{String: id.String(), Valid: true}
+			}
+
+			var endedAt sql.NullInt64
+			if note.Ended_at != 0 {
+				endedAt = sql.NullInt64{Int64: note.Ended_at, Valid: true}
+			}
+
+			// a deleted note comes back only with a newer version, like in pushNote
+			ownerId, deletedAt, deleted, err := getTombstone(tx, note.Id)
+			if err != nil {
+				log.Error().Err(err).Msg("import tombstone receiving")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if deleted && ownerId != userInfo.User_id {
+				result.Status = models.ImportStatusRejected
+				result.Error = "client_id is taken"
+				continue
+			}
+			if deleted && deletedAt >= note.Updated_at {
+				result.Status = models.ImportStatusRejected
+				result.Error = "note was deleted"
+				continue
+			}
+
+			res, err := tx.Exec(
+				query,
+				note.Id,
+				userInfo.User_id,
+				categoryId,
+				note.Content,
+				note.Created_at,
+				note.Updated_at,
+				endedAt,
+				note.Completed,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("note importing")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if affected, _ := res.RowsAffected(); affected == 0 {
+				// the same id of another user is not a duplicate of this one, as in AddNote
+				_, storedUserId, err := getNote(tx, note.Id)
+				if err != nil {
+					log.Error().Err(err).Msg("existing note receiving")
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				if storedUserId != userInfo.User_id {
+					result.Status = models.ImportStatusRejected
+					result.Error = "client_id is taken"
+					continue
+				}
+
+				result.Status = models.ImportStatusDuplicate
+				continue
+			}
+
+			if deleted {
+				if _, err := tx.Exec(`DELETE FROM note_tombstones WHERE id = $1`, note.Id); err != nil {
+					log.Error().Err(err).Msg("import tombstone removing")
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+			}
+
+			result.Status = models.ImportStatusImported
+			result.Note = &note
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("import transaction commit")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		for _, result := range report.Rows {
+			switch result.Status {
+			case models.ImportStatusImported:
+				report.Imported++
+			case models.ImportStatusDuplicate:
+				report.Duplicates++
+			case models.ImportStatusRejected:
+				report.Rejected++
+			}
+		}
+
+		log.Info().
+			Int("imported", report.Imported).
+			Int("duplicates", report.Duplicates).
+			Int("rejected", report.Rejected).
+			Msg("Notes imported")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
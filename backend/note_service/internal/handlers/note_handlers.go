@@ -13,6 +13,7 @@ import (
 
 	models "note_service/internal/models"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/net/publicsuffix"
@@ -20,8 +21,26 @@ import (
 
 const (
 	pageLimit = 10
+
+	// accepted range for client supplied created_at of a new note
+	maxClockSkew = 5 * time.Minute
+	maxBackdate  = 30 * 24 * time.Hour
 )
 
+// validNewNote is the rule for a note the server does not have yet, AddNote and
+// the sync push both apply it; edits of a stored note keep its created_at
+func validNewNote(note models.NoteInfo, now time.Time) bool {
+	createdAt := time.Unix(note.Created_at, 0)
+	if createdAt.After(now.Add(maxClockSkew)) || createdAt.Before(now.Add(-maxBackdate)) {
+		return false
+	}
+	return validEndedAt(note)
+}
+
+func validEndedAt(note models.NoteInfo) bool {
+	return note.Ended_at == 0 || note.Ended_at >= note.Created_at
+}
+
 type httpError struct {
 	Code  int    `json:"code"`
 	Error error  `json:"error"`
@@ -35,6 +54,44 @@ func checkNoteExistence(db *sql.DB, noteId uuid.UUID) (bool, error) {
 	return exists, err
 }
 
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getNote(db queryRower, noteId uuid.UUID) (models.NoteInfo, uuid.UUID, error) {
+	var noteInfo models.NoteInfo
+	var userId uuid.UUID
+	var categoryId sql.NullString
+	var updatedAt, endedAt sql.NullInt64
+
+	query := `SELECT id, user_id, category_id, content, created_at, updated_at, ended_at, completed
+						FROM notes
+						WHERE id = $1`
+
+	err := db.QueryRow(query, noteId).Scan(
+		&noteInfo.Id,
+		&userId,
+		&categoryId,
+		&noteInfo.Content,
+		&noteInfo.Created_at,
+		&updatedAt,
+		&endedAt,
+		&noteInfo.Completed,
+	)
+	if err != nil {
+		return noteInfo, userId, err
+	}
+
+	if categoryId.Valid {
+		noteInfo.Category_id, _ = uuid.Parse(categoryId.String)
+	}
+	noteInfo.Updated_at = updatedAt.Int64
+	noteInfo.Ended_at = endedAt.Int64
+
+	return noteInfo, userId, nil
+}
+
 func getUserIdFromToken(r *http.Request) (models.UserInfo, httpError) {
 	var userInfo models.UserInfo
 	var httpErr httpError
@@ -96,10 +153,16 @@ func getUserIdFromToken(r *http.Request) (models.UserInfo, httpError) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Error().Msg("auth service check token")
-		httpErr.Code = http.StatusInternalServerError
-		httpErr.Error = fmt.Errorf("auth service check token")
-		httpErr.Msg = "Internal server error"
+		log.Error().Int("status", resp.StatusCode).Msg("auth service check token")
+		httpErr.Error = fmt.Errorf("auth service check token: status %d", resp.StatusCode)
+		// a revoked or expired token is for the client to renew, e.g. the bot asks for a new one
+		if resp.StatusCode == http.StatusUnauthorized {
+			httpErr.Code = http.StatusUnauthorized
+			httpErr.Msg = "Unauthorized"
+		} else {
+			httpErr.Code = http.StatusInternalServerError
+			httpErr.Msg = "Internal server error"
+		}
 		return userInfo, httpErr
 	}
 
@@ -130,22 +193,71 @@ func AddNote(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		query := `INSERT INTO notes (id, user_id, category_id, content, created_at)
-							VALUES ($1, $2, $3, $4, $5)`
-		noteId, err := uuid.NewV7()
+		// clients generate ids and timestamps offline, the server only mints missing ones
+		if noteInfo.Id == uuid.Nil {
+			noteId, err := uuid.NewV7()
+			if err != nil {
+				log.Error().Err(err).Msg("new note id generation")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			noteInfo.Id = noteId
+		}
+
+		now := time.Now()
+		if noteInfo.Created_at == 0 {
+			noteInfo.Created_at = now.Unix()
+		}
+
+		if !validNewNote(noteInfo, now) {
+			log.Error().
+				Int64("created_at", noteInfo.Created_at).
+				Int64("ended_at", noteInfo.Ended_at).
+				Msg("note times out of range")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		noteInfo.Updated_at = noteInfo.Created_at
+
+		var categoryId sql.NullString
+		if noteInfo.Category_id != uuid.Nil {
+			categoryId = sql.NullString{String: noteInfo.Category_id.String(), Valid: true}
+		}
+
+		var endedAt sql.NullInt64
+		if noteInfo.Ended_at != 0 {
+			endedAt = sql.NullInt64{Int64: noteInfo.Ended_at, Valid: true}
+		}
+
+		tx, err := db.Begin()
 		if err != nil {
-			log.Error().Err(err).Msg("new note id generation")
+			log.Error().Err(err).Msg("note creating transaction begin")
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
 
-		_, err = db.Exec(
+		if err := lockChanges(tx, userInfo.User_id, false); err != nil {
+			log.Error().Err(err).Msg("note creating lock")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		query := `INSERT INTO notes (id, user_id, category_id, content, created_at, updated_at, ended_at, completed)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+							ON CONFLICT (id) DO NOTHING`
+
+		res, err := tx.Exec(
 			query,
-			noteId,
+			noteInfo.Id,
 			userInfo.User_id,
-			noteInfo.Category_id,
+			categoryId,
 			noteInfo.Content,
-			time.Now().Unix(),
+			noteInfo.Created_at,
+			noteInfo.Updated_at,
+			endedAt,
+			noteInfo.Completed,
 		)
 		if err != nil {
 			log.Error().Err(err).Msg("note creating")
@@ -153,9 +265,38 @@ func AddNote(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		log.Info().Msg("Note inserted successfully")
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			// retried request: the same id with the same content is not an error
+			stored, ownerId, err := getNote(tx, noteInfo.Id)
+			if err != nil {
+				log.Error().Err(err).Msg("existing note receiving")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
 
+			if ownerId != userInfo.User_id || stored.Content != noteInfo.Content {
+				log.Error().Str("note_id", noteInfo.Id.String()).Msg("note id conflict")
+				http.Error(w, "Conflict", http.StatusConflict)
+				return
+			}
+
+			noteInfo = stored
+		} else {
+			log.Info().Msg("Note inserted successfully")
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("note creating transaction commit")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(noteInfo); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
 	}
 }
 
@@ -184,20 +325,49 @@ func DeleteNote(db *sql.DB) http.HandlerFunc {
 
 		if !exists {
 			log.Error().Err(fmt.Errorf("note not found"))
-			http.Error(w, "Bad request", http.StatusBadRequest)
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error().Err(err).Msg("note deleting transaction begin")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := lockChanges(tx, userInfo.User_id, false); err != nil {
+			log.Error().Err(err).Msg("note deleting lock")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
 		query := `DELETE FROM notes WHERE id = $1 and user_id = $2`
-		res, err := db.Exec(query, noteInfo.Note_id, userInfo.User_id)
+		res, err := tx.Exec(query, noteInfo.Note_id, userInfo.User_id)
 		if err != nil {
 			log.Error().Err(err).Msg("note deleting")
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		// a note of another user is not found either
 		affected, _ := res.RowsAffected()
 		if affected == 0 {
-			http.Error(w, "Bad request", http.StatusBadRequest)
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		// tombstone lets other devices learn about the deletion from the changes feed
+		err = addTombstone(tx, noteInfo.Note_id, userInfo.User_id, time.Now().Unix())
+		if err != nil {
+			log.Error().Err(err).Msg("note tombstone creating")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("note deleting transaction commit")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
@@ -301,3 +471,37 @@ func GetNote(db *sql.DB) http.HandlerFunc {
 		// check author valid
 	}
 }
+
+// GetNoteById returns one note of the user, a note of someone else is not found either
+func GetNoteById(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		noteId, err := uuid.Parse(chi.URLParam(r, "note_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("note id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		noteInfo, ownerId, err := getNote(db, noteId)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && ownerId != userInfo.User_id) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("note receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(noteInfo); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
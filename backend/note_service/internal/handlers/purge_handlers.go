@@ -0,0 +1,72 @@
+package note_handlers
+
+import (
+	"database/sql"
+	"time"
+
+	models "note_service/internal/models"
+
+	"shared/events"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	purgeBatchSize = 500
+)
+
+func PurgeUserNotes(db *sql.DB, data []byte) (models.UserPurgedEvent, error) {
+	var event models.UserPurgedEvent
+
+	userId, err := events.ParseDeletedUserId(data)
+	if err != nil {
+		log.Error().Err(err).Msg("user-deleted parse failed")
+		return event, err
+	}
+	log.Info().Str("user_id", userId.String()).Msg("processing user-deleted")
+
+	query := `DELETE FROM notes
+						WHERE id IN (
+							SELECT id FROM notes
+							WHERE user_id = $1
+							LIMIT $2
+						)`
+
+	var purged int64
+	for {
+		res, err := db.Exec(query, userId, purgeBatchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("user notes purge error")
+			return event, err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			log.Error().Err(err).Msg("user notes purge rows affected")
+			return event, err
+		}
+
+		purged += affected
+		if affected < purgeBatchSize {
+			break
+		}
+	}
+
+	query = `DELETE FROM note_tombstones WHERE user_id = $1`
+	if _, err := db.Exec(query, userId); err != nil {
+		log.Error().Err(err).Msg("user tombstones purge error")
+		return event, err
+	}
+
+	log.Info().
+		Str("user_id", userId.String()).
+		Int64("purged", purged).
+		Msg("User notes purged successfully")
+
+	event.User_id = userId
+	event.Service = "note_service"
+	event.Purged_rows = purged
+	event.Purged_at = time.Now().Unix()
+
+	return event, nil
+}
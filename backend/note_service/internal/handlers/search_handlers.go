@@ -0,0 +1,136 @@
+package note_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	models "note_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const searchMaxTerms = 16
+
+// prefixTsQuery turns free text into a to_tsquery expression where every word
+// is a prefix, so results can follow the user while typing: "read bo" -> "read:* & bo:*"
+func prefixTsQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > searchMaxTerms {
+		words = words[:searchMaxTerms]
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SearchNotes finds notes of the user by content, best matches first,
+// paged like GetNote; category_id narrows the search to one category
+func SearchNotes(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		tsQuery := prefixTsQuery(r.URL.Query().Get("q"))
+		if tsQuery == "" {
+			log.Error().Msg("empty search query")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		page := 1
+		if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+			p, err := strconv.Atoi(pageStr)
+			if err != nil || p < 1 {
+				log.Error().Err(err).Msg("page parse")
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+			page = p
+		}
+
+		var categoryId uuid.NullUUID
+		if categoryStr := r.URL.Query().Get("category_id"); categoryStr != "" {
+			id, err := uuid.Parse(categoryStr)
+			if err != nil {
+				log.Error().Err(err).Msg("category id parse")
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+			categoryId = uuid.NullUUID{UUID: id, Valid: true}
+		}
+
+		query := `SELECT id, category_id, content, created_at, updated_at, ended_at, completed
+							FROM notes
+							WHERE user_id = $1
+								AND search_vector @@ to_tsquery('simple', $2)
+								AND ($3::uuid IS NULL OR category_id = $3)
+							ORDER BY ts_rank(search_vector, to_tsquery('simple', $2)) DESC, created_at DESC
+							LIMIT $4 OFFSET $5`
+
+		rows, err := db.Query(query, userInfo.User_id, tsQuery, categoryId, pageLimit, (page-1)*pageLimit)
+		if err != nil {
+			log.Error().Err(err).Msg("note search")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		notes := make([]models.NoteInfo, 0)
+
+		for rows.Next() {
+			var noteInfo models.NoteInfo
+			var category sql.NullString
+			var updatedAt, endedAt sql.NullInt64
+
+			err := rows.Scan(
+				&noteInfo.Id,
+				&category,
+				&noteInfo.Content,
+				&noteInfo.Created_at,
+				&updatedAt,
+				&endedAt,
+				&noteInfo.Completed,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("note search scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if category.Valid {
+				noteInfo.Category_id, _ = uuid.Parse(category.String)
+			}
+			noteInfo.Updated_at = updatedAt.Int64
+			noteInfo.Ended_at = endedAt.Int64
+
+			notes = append(notes, noteInfo)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("note search")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(notes); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
@@ -0,0 +1,493 @@
+package note_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	models "note_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	syncMaxItems = 1000
+	// changesLimit is the default page of the changes feed, syncMaxItems the largest
+	changesLimit = 500
+
+	conflictNewerOnServer = "newer_on_server"
+	conflictDeleted       = "deleted"
+	conflictForeign       = "foreign_note"
+	conflictInvalid       = "invalid_note"
+)
+
+// lockChanges orders the writes of a user against the changes feed: writers share
+// the lock and the feed takes it alone, so once a pull has read up to a change_seq
+// no write holding a lower one can still commit
+func lockChanges(tx *sql.Tx, userId uuid.UUID, feed bool) error {
+	query := `SELECT pg_advisory_xact_lock_shared(hashtext($1))`
+	if feed {
+		query = `SELECT pg_advisory_xact_lock(hashtext($1))`
+	}
+
+	_, err := tx.Exec(query, userId.String())
+	return err
+}
+
+func addTombstone(tx *sql.Tx, noteId uuid.UUID, userId uuid.UUID, deletedAt int64) error {
+	query := `INSERT INTO note_tombstones (id, user_id, deleted_at)
+						VALUES ($1, $2, $3)
+						ON CONFLICT (id)
+						DO UPDATE SET
+							deleted_at = GREATEST(note_tombstones.deleted_at, EXCLUDED.deleted_at),
+							change_seq = nextval('note_change_seq')`
+
+	_, err := tx.Exec(query, noteId, userId, deletedAt)
+	return err
+}
+
+func getTombstone(tx *sql.Tx, noteId uuid.UUID) (uuid.UUID, int64, bool, error) {
+	var userId uuid.UUID
+	var deletedAt int64
+
+	query := `SELECT user_id, deleted_at FROM note_tombstones WHERE id = $1`
+	err := tx.QueryRow(query, noteId).Scan(&userId, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userId, 0, false, nil
+	}
+	if err != nil {
+		return userId, 0, false, err
+	}
+
+	return userId, deletedAt, true, nil
+}
+
+// GetNoteChanges is the changes feed for offline clients: the first limit notes
+// stored and deleted after cursor, in the order the server took them, clients pass
+// the returned cursor back until has_more is false; the order of client timestamps
+// does not matter, so an offline edit pushed late still reaches the other devices
+func GetNoteChanges(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var cursor int64
+		if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+			var err error
+			cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+			if err != nil || cursor < 0 {
+				log.Error().Err(err).Msg("cursor parse")
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		limit := changesLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			var err error
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil || limit < 1 || limit > syncMaxItems {
+				log.Error().Err(err).Msg("limit parse")
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		changes := models.NoteChanges{
+			Notes:   make([]models.NoteInfo, 0),
+			Deleted: make([]models.NoteTombstone, 0),
+			Cursor:  cursor,
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error().Err(err).Msg("note changes transaction begin")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := lockChanges(tx, userInfo.User_id, true); err != nil {
+			log.Error().Err(err).Msg("note changes lock")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		query := `SELECT id, category_id, content, created_at, updated_at, ended_at, completed, change_seq
+							FROM notes
+							WHERE user_id = $1 AND change_seq > $2
+							ORDER BY change_seq
+							LIMIT $3`
+
+		// one more than the page tells whether the feed goes on
+		rows, err := tx.Query(query, userInfo.User_id, cursor, limit+1)
+		if err != nil {
+			log.Error().Err(err).Msg("note changes receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		var noteSeqs, tombSeqs []int64
+		for rows.Next() {
+			var noteInfo models.NoteInfo
+			var categoryId sql.NullString
+			var updatedAt, endedAt sql.NullInt64
+			var changeSeq int64
+
+			err := rows.Scan(
+				&noteInfo.Id,
+				&categoryId,
+				&noteInfo.Content,
+				&noteInfo.Created_at,
+				&updatedAt,
+				&endedAt,
+				&noteInfo.Completed,
+				&changeSeq,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("note changes scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if categoryId.Valid {
+				noteInfo.Category_id, _ = uuid.Parse(categoryId.String)
+			}
+			noteInfo.Updated_at = updatedAt.Int64
+			if noteInfo.Updated_at == 0 {
+				noteInfo.Updated_at = noteInfo.Created_at
+			}
+			noteInfo.Ended_at = endedAt.Int64
+
+			changes.Notes = append(changes.Notes, noteInfo)
+			noteSeqs = append(noteSeqs, changeSeq)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("note changes receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		query = `SELECT id, deleted_at, change_seq
+						 FROM note_tombstones
+						 WHERE user_id = $1 AND change_seq > $2
+						 ORDER BY change_seq
+						 LIMIT $3`
+
+		tombRows, err := tx.Query(query, userInfo.User_id, cursor, limit+1)
+		if err != nil {
+			log.Error().Err(err).Msg("note tombstones receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer tombRows.Close()
+
+		for tombRows.Next() {
+			var tombstone models.NoteTombstone
+			var changeSeq int64
+			if err := tombRows.Scan(&tombstone.Note_id, &tombstone.Deleted_at, &changeSeq); err != nil {
+				log.Error().Err(err).Msg("note tombstone scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			changes.Deleted = append(changes.Deleted, tombstone)
+			tombSeqs = append(tombSeqs, changeSeq)
+		}
+
+		if err := tombRows.Err(); err != nil {
+			log.Error().Err(err).Msg("note tombstones receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		pageChanges(&changes, noteSeqs, tombSeqs, limit)
+
+		if err := tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("note changes transaction commit")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(changes); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+// pageChanges keeps the first limit changes of the notes and tombstones, both
+// ordered by change_seq from the one sequence, and moves the cursor past them
+func pageChanges(changes *models.NoteChanges, noteSeqs []int64, tombSeqs []int64, limit int) {
+	seqs := append(append(make([]int64, 0, len(noteSeqs)+len(tombSeqs)), noteSeqs...), tombSeqs...)
+	if len(seqs) == 0 {
+		return
+	}
+	slices.Sort(seqs)
+
+	last := seqs[len(seqs)-1]
+	if len(seqs) > limit {
+		last = seqs[limit-1]
+		changes.Has_more = true
+	}
+
+	notes := changes.Notes[:0]
+	for i, note := range changes.Notes {
+		if noteSeqs[i] <= last {
+			notes = append(notes, note)
+		}
+	}
+	changes.Notes = notes
+
+	deleted := changes.Deleted[:0]
+	for i, tombstone := range changes.Deleted {
+		if tombSeqs[i] <= last {
+			deleted = append(deleted, tombstone)
+		}
+	}
+	changes.Deleted = deleted
+
+	changes.Cursor = last
+}
+
+// pushNote applies a client version of a note when it is newer than the stored one
+// (last writer wins on updated_at), otherwise it reports the conflict
+func pushNote(tx *sql.Tx, userId uuid.UUID, note models.NoteInfo) (*models.NoteSyncConflict, error) {
+	if note.Updated_at == 0 {
+		note.Updated_at = note.Created_at
+	}
+
+	ownerId, deletedAt, deleted, err := getTombstone(tx, note.Id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted && ownerId == userId && deletedAt >= note.Updated_at {
+		return &models.NoteSyncConflict{Note_id: note.Id, Reason: conflictDeleted}, nil
+	}
+
+	// a new note passes the same checks as in AddNote, an edit only needs consistent times
+	_, _, err = getNote(tx, note.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	isNew := errors.Is(err, sql.ErrNoRows)
+	if (isNew && !validNewNote(note, time.Now())) || !validEndedAt(note) {
+		return &models.NoteSyncConflict{Note_id: note.Id, Reason: conflictInvalid}, nil
+	}
+
+	var categoryId sql.NullString
+	if note.Category_id != uuid.Nil {
+		categoryId = sql.NullString{String: note.Category_id.String(), Valid: true}
+	}
+
+	var endedAt sql.NullInt64
+	if note.Ended_at != 0 {
+		endedAt = sql.NullInt64{Int64: note.Ended_at, Valid: true}
+	}
+
+	query := `INSERT INTO notes (id, user_id, category_id, content, created_at, updated_at, ended_at, completed)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+						ON CONFLICT (id) DO UPDATE SET
+							category_id = EXCLUDED.category_id,
+							content = EXCLUDED.content,
+							updated_at = EXCLUDED.updated_at,
+							ended_at = EXCLUDED.ended_at,
+							completed = EXCLUDED.completed,
+							change_seq = nextval('note_change_seq')
+						WHERE notes.user_id = EXCLUDED.user_id
+							AND COALESCE(notes.updated_at, notes.created_at) < EXCLUDED.updated_at`
+
+	res, err := tx.Exec(
+		query,
+		note.Id,
+		userId,
+		categoryId,
+		note.Content,
+		note.Created_at,
+		note.Updated_at,
+		endedAt,
+		note.Completed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 1 {
+		if deleted && ownerId == userId {
+			_, err = tx.Exec(`DELETE FROM note_tombstones WHERE id = $1`, note.Id)
+		}
+		return nil, err
+	}
+
+	stored, storedUserId, err := getNote(tx, note.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if storedUserId != userId {
+		return &models.NoteSyncConflict{Note_id: note.Id, Reason: conflictForeign}, nil
+	}
+
+	return &models.NoteSyncConflict{
+		Note_id: note.Id,
+		Reason:  conflictNewerOnServer,
+		Note:    &stored,
+	}, nil
+}
+
+// pushDelete removes a note unless it was changed on the server after the client deleted it
+func pushDelete(tx *sql.Tx, userId uuid.UUID, tombstone models.NoteTombstone) (*models.NoteSyncConflict, error) {
+	stored, storedUserId, err := getNote(tx, tombstone.Note_id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err == nil {
+		if storedUserId != userId {
+			return &models.NoteSyncConflict{Note_id: tombstone.Note_id, Reason: conflictForeign}, nil
+		}
+
+		updatedAt := stored.Updated_at
+		if updatedAt == 0 {
+			updatedAt = stored.Created_at
+		}
+
+		if updatedAt > tombstone.Deleted_at {
+			return &models.NoteSyncConflict{
+				Note_id: tombstone.Note_id,
+				Reason:  conflictNewerOnServer,
+				Note:    &stored,
+			}, nil
+		}
+
+		_, err = tx.Exec(`DELETE FROM notes WHERE id = $1 AND user_id = $2`, tombstone.Note_id, userId)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ownerId, _, deleted, err := getTombstone(tx, tombstone.Note_id)
+		if err != nil {
+			return nil, err
+		}
+		if deleted && ownerId != userId {
+			return &models.NoteSyncConflict{Note_id: tombstone.Note_id, Reason: conflictForeign}, nil
+		}
+	}
+
+	return nil, addTombstone(tx, tombstone.Note_id, userId, tombstone.Deleted_at)
+}
+
+// PushNoteChanges applies a batch of offline changes in one transaction
+func PushNoteChanges(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var request models.NoteSyncRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Error().Err(err).Msg("note sync json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if len(request.Notes)+len(request.Deleted) > syncMaxItems {
+			log.Error().Msg("note sync batch too large")
+			http.Error(w, "Too many changes", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		for _, note := range request.Notes {
+			if note.Id == uuid.Nil || note.Created_at == 0 {
+				log.Error().Msg("note sync invalid note")
+				http.Error(w, "Bad request", http.StatusBadRequest)
+				return
+			}
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error().Err(err).Msg("note sync transaction begin")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := lockChanges(tx, userInfo.User_id, false); err != nil {
+			log.Error().Err(err).Msg("note sync lock")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response := models.NoteSyncResponse{
+			Applied:   make([]uuid.UUID, 0),
+			Conflicts: make([]models.NoteSyncConflict, 0),
+		}
+
+		for _, note := range request.Notes {
+			conflict, err := pushNote(tx, userInfo.User_id, note)
+			if err != nil {
+				log.Error().Err(err).Msg("note sync applying")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if conflict != nil {
+				response.Conflicts = append(response.Conflicts, *conflict)
+			} else {
+				response.Applied = append(response.Applied, note.Id)
+			}
+		}
+
+		for _, tombstone := range request.Deleted {
+			conflict, err := pushDelete(tx, userInfo.User_id, tombstone)
+			if err != nil {
+				log.Error().Err(err).Msg("note sync deleting")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if conflict != nil {
+				response.Conflicts = append(response.Conflicts, *conflict)
+			} else {
+				response.Applied = append(response.Applied, tombstone.Note_id)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("note sync transaction commit")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		response.Server_time = time.Now().Unix()
+
+		log.Info().
+			Int("applied", len(response.Applied)).
+			Int("conflicts", len(response.Conflicts)).
+			Msg("Note changes pushed")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
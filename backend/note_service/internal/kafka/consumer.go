@@ -0,0 +1,74 @@
+package kafka_listener
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	handlers "note_service/internal/handlers"
+	models "note_service/internal/models"
+
+	"shared/events"
+
+	"github.com/rs/zerolog/log"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func getKafkaReader(kafkaURL string, topics []string, groupID string) *kafka.Reader {
+	brokers := strings.Split(kafkaURL, ",")
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		GroupTopics: topics,
+		MinBytes:    10e3, // 10KB
+		MaxBytes:    10e6, // 10MB
+	})
+}
+
+func RunKafkaListener(db *sql.DB, writer *kafka.Writer) {
+	kafkaURL := fmt.Sprintf("%v:%v", os.Getenv("KAFKA_HOST"), os.Getenv("KAFKA_PORT"))
+	topics := []string{"user-deleted"}
+	groupID := "note_service"
+
+	reader := getKafkaReader(kafkaURL, topics, groupID)
+
+	defer reader.Close()
+
+	log.Info().Msg("Start consuming kafka topic")
+
+	for {
+		m, err := reader.FetchMessage(context.Background())
+		if err != nil {
+			log.Fatal().Err(err).Msg("kafka message reading fatal")
+		}
+
+		switch m.Topic {
+		case "user-deleted":
+			log.Info().Msg("user-deleted message received")
+
+			// committing the message before the purge and the user-purged event went
+			// through would drop them for good, so failures are retried
+			var event models.UserPurgedEvent
+			err := events.Retry("user-deleted processing", func() error {
+				var err error
+				event, err = handlers.PurgeUserNotes(db, m.Value)
+				return err
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("user-deleted processing failed")
+			} else {
+				events.Retry("user-purged sending", func() error {
+					return sendUserPurgedEvent(context.Background(), writer, event)
+				})
+			}
+		default:
+			log.Error().Msg("topic undefined")
+		}
+
+		if err := reader.CommitMessages(context.Background(), m); err != nil {
+			log.Fatal().Err(err).Msg("kafka message commit fatal")
+		}
+	}
+}
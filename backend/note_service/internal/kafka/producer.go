@@ -0,0 +1,43 @@
+package kafka_listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	models "note_service/internal/models"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func GetKafkaWriter(kafkaURL string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(kafkaURL),
+		Balancer:     &kafka.LeastBytes{},
+		BatchTimeout: 10 * time.Millisecond,
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+func sendUserPurgedEvent(
+	ctx context.Context,
+	writer *kafka.Writer,
+	event models.UserPurgedEvent,
+) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("user-purged marshal error: %w", err)
+	}
+
+	err = writer.WriteMessages(ctx, kafka.Message{
+		Topic: "user-purged",
+		Key:   []byte(event.User_id.String()),
+		Value: data,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka user-purged message error: %w", err)
+	}
+
+	return nil
+}
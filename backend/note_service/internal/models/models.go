@@ -25,3 +25,81 @@ type UserInfo struct {
 // type NoteGetInfo struct {
 // 	User_id uuid.UUID ``
 // }
+
+type UserPurgedEvent struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Service     string    `json:"service"`
+	Purged_rows int64     `json:"purged_rows"`
+	Purged_at   int64     `json:"purged_at"`
+}
+
+const (
+	ImportStatusImported  = "imported"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusRejected  = "rejected"
+)
+
+type NoteImportRow struct {
+	Client_id  string `json:"client_id"`
+	Category   string `json:"category"`
+	Content    string `json:"content"`
+	Created_at string `json:"created_at"`
+	Ended_at   string `json:"ended_at"`
+	Completed  string `json:"completed"`
+}
+
+type NoteImportResult struct {
+	Row       int       `json:"row"`
+	Client_id string    `json:"client_id,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Note      *NoteInfo `json:"note,omitempty"`
+}
+
+type NoteImportReport struct {
+	Imported   int                `json:"imported"`
+	Duplicates int                `json:"duplicates"`
+	Rejected   int                `json:"rejected"`
+	Rows       []NoteImportResult `json:"rows"`
+}
+
+type CategoryImportResult struct {
+	Name        string    `json:"name"`
+	Category_id uuid.UUID `json:"category_id"`
+	Status      string    `json:"status"`
+}
+
+type CategoryImportReport struct {
+	Rows []CategoryImportResult `json:"rows"`
+}
+
+type NoteTombstone struct {
+	Note_id    uuid.UUID `json:"note_id"`
+	Deleted_at int64     `json:"deleted_at"`
+}
+
+// NoteChanges is one pull of the changes feed, Cursor is the change_seq of the
+// last change in it and the cursor of the next pull, Has_more asks for that pull
+type NoteChanges struct {
+	Notes    []NoteInfo      `json:"notes"`
+	Deleted  []NoteTombstone `json:"deleted"`
+	Cursor   int64           `json:"cursor"`
+	Has_more bool            `json:"has_more"`
+}
+
+type NoteSyncRequest struct {
+	Notes   []NoteInfo      `json:"notes"`
+	Deleted []NoteTombstone `json:"deleted"`
+}
+
+type NoteSyncConflict struct {
+	Note_id uuid.UUID `json:"note_id"`
+	Reason  string    `json:"reason"`
+	Note    *NoteInfo `json:"note,omitempty"`
+}
+
+type NoteSyncResponse struct {
+	Applied     []uuid.UUID        `json:"applied"`
+	Conflicts   []NoteSyncConflict `json:"conflicts"`
+	Server_time int64              `json:"server_time"`
+}
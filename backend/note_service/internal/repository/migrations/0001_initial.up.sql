@@ -1,6 +1,7 @@
+-- IF NOT EXISTS adopts databases created by the old db/init/schema.sql
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-CREATE TABLE notes (
+CREATE TABLE IF NOT EXISTS notes (
   id UUID PRIMARY KEY,
   user_id UUID NOT NULL,
   category_id UUID,
@@ -10,4 +11,3 @@ CREATE TABLE notes (
   ended_at BIGINT,
   completed BOOLEAN DEFAULT FALSE
 );
-
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS note_tombstones;
+DROP INDEX IF EXISTS notes_user_updated_at;
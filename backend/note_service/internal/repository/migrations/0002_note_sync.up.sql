@@ -0,0 +1,9 @@
+CREATE INDEX IF NOT EXISTS notes_user_updated_at ON notes(user_id, updated_at);
+
+CREATE TABLE IF NOT EXISTS note_tombstones (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  deleted_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS note_tombstones_user_deleted_at ON note_tombstones(user_id, deleted_at);
@@ -0,0 +1,5 @@
+-- 'simple' does no stemming, so russian and english notes are matched alike
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+  GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS notes_search_vector ON notes USING GIN (search_vector);
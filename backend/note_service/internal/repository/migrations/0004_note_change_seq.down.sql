@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS note_tombstones_user_change_seq;
+DROP INDEX IF EXISTS notes_user_change_seq;
+ALTER TABLE note_tombstones DROP COLUMN IF EXISTS change_seq;
+ALTER TABLE notes DROP COLUMN IF EXISTS change_seq;
+DROP SEQUENCE IF EXISTS note_change_seq;
@@ -0,0 +1,9 @@
+-- the changes feed pages on the order the server stored changes in, client
+-- timestamps are only compared for last writer wins
+CREATE SEQUENCE IF NOT EXISTS note_change_seq;
+
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('note_change_seq');
+ALTER TABLE note_tombstones ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('note_change_seq');
+
+CREATE INDEX IF NOT EXISTS notes_user_change_seq ON notes(user_id, change_seq);
+CREATE INDEX IF NOT EXISTS note_tombstones_user_change_seq ON note_tombstones(user_id, change_seq);
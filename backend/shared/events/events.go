@@ -0,0 +1,56 @@
+// Package events holds what the backend services share about the kafka events
+// they consume.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrMalformed marks a message no retry can handle, it is committed and skipped
+var ErrMalformed = errors.New("malformed event")
+
+// retryDelay is the pause between the attempts of Retry
+const retryDelay = 5 * time.Second
+
+type userDeleted struct {
+	User_id uuid.UUID `json:"id"`
+}
+
+// ParseDeletedUserId reads a user-deleted message, auth_service publishes the
+// bare user id as the value, older producers sent {"id": "..."}, so both forms are accepted
+func ParseDeletedUserId(data []byte) (uuid.UUID, error) {
+	if userId, err := uuid.ParseBytes(data); err == nil {
+		return userId, nil
+	}
+
+	var event userDeleted
+	if err := json.Unmarshal(data, &event); err != nil {
+		return uuid.UUID{}, fmt.Errorf("%w: user-deleted: %v", ErrMalformed, err)
+	}
+
+	if event.User_id == uuid.Nil {
+		return uuid.UUID{}, fmt.Errorf("%w: user-deleted: empty user id", ErrMalformed)
+	}
+
+	return event.User_id, nil
+}
+
+// Retry runs fn until it succeeds or reports ErrMalformed; the consumers commit
+// a message only once it is handled, so a failed purge is not lost with it
+func Retry(name string, fn func() error) error {
+	for {
+		err := fn()
+		if err == nil || errors.Is(err, ErrMalformed) {
+			return err
+		}
+
+		log.Error().Err(err).Msg(name + " failed, retrying")
+		time.Sleep(retryDelay)
+	}
+}
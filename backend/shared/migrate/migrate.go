@@ -0,0 +1,338 @@
+// Package migrate is the schema migration runner of the backend services, each
+// service embeds its migrations/NNNN_name.{up,down}.sql files and passes them in.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Migration is a pair of embedded files migrations/NNNN_name.up.sql and NNNN_name.down.sql
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type AppliedMigration struct {
+	Version    int
+	Name       string
+	Applied_at int64
+}
+
+// loadMigrations reads the migrations directory of files, every service embeds its own
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %q: expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+
+		versionStr, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+
+		data, err := fs.ReadFile(files, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.up = string(data)
+		} else {
+			migration.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// withMigrationLock runs fn on one connection holding a session advisory lock,
+// so replicas starting together migrate one after another
+func withMigrationLock(db *sql.DB, service string, fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockKey := "schema_migrations:" + service
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockKey); err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, lockKey); err != nil {
+			log.Error().Err(err).Msg("migration unlock")
+		}
+	}()
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+							version INTEGER PRIMARY KEY,
+							name TEXT NOT NULL,
+							applied_at BIGINT NOT NULL
+						)`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func appliedMigrations(conn *sql.Conn) ([]AppliedMigration, error) {
+	rows, err := conn.QueryContext(
+		context.Background(),
+		`SELECT version, name, applied_at FROM schema_migrations ORDER BY version`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make([]AppliedMigration, 0)
+	for rows.Next() {
+		var migration AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.Applied_at); err != nil {
+			return nil, err
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, rows.Err()
+}
+
+// runMigration applies one direction of a migration and records it in the same
+// transaction, a failed migration leaves no trace
+func runMigration(conn *sql.Conn, migration Migration, up bool) error {
+	ctx := context.Background()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.ExecContext(ctx, migration.up); err != nil {
+			return err
+		}
+		query := `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`
+		if _, err := tx.ExecContext(ctx, query, migration.Version, migration.Name, time.Now().Unix()); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, migration.down); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Up applies every pending migration in order
+func Up(db *sql.DB, service string, files fs.FS) ([]Migration, error) {
+	migrations, err := loadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	err = withMigrationLock(db, service, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int]bool)
+		for _, migration := range migrations {
+			known[migration.Version] = true
+		}
+		isApplied := make(map[int]bool)
+		for _, migration := range applied {
+			// a newer binary already migrated this database, an older one must not run on it
+			if !known[migration.Version] {
+				return fmt.Errorf("database has unknown migration %04d_%s", migration.Version, migration.Name)
+			}
+			isApplied[migration.Version] = true
+		}
+
+		for _, migration := range migrations {
+			if isApplied[migration.Version] {
+				continue
+			}
+			if err := runMigration(conn, migration, true); err != nil {
+				return fmt.Errorf("migration %04d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			log.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("Migration applied")
+			done = append(done, migration)
+		}
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first
+func Down(db *sql.DB, service string, files fs.FS, steps int) ([]Migration, error) {
+	migrations, err := loadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]Migration)
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	done := make([]Migration, 0)
+	err = withMigrationLock(db, service, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			migration, ok := byVersion[applied[i].Version]
+			if !ok {
+				return fmt.Errorf("no down migration for %04d_%s", applied[i].Version, applied[i].Name)
+			}
+			if err := runMigration(conn, migration, false); err != nil {
+				return fmt.Errorf("migration %04d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			log.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("Migration reverted")
+			done = append(done, migration)
+		}
+		return nil
+	})
+
+	return done, err
+}
+
+// Status returns the applied migrations and the pending ones
+func Status(db *sql.DB, service string, files fs.FS) ([]AppliedMigration, []Migration, error) {
+	migrations, err := loadMigrations(files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var applied []AppliedMigration
+	err = withMigrationLock(db, service, func(conn *sql.Conn) error {
+		applied, err = appliedMigrations(conn)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isApplied := make(map[int]bool)
+	for _, migration := range applied {
+		isApplied[migration.Version] = true
+	}
+
+	pending := make([]Migration, 0)
+	for _, migration := range migrations {
+		if !isApplied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return applied, pending, nil
+}
+
+// RunCommand is the `migrate` subcommand of the service binaries:
+// migrate [up], migrate down [steps], migrate status
+func RunCommand(db *sql.DB, service string, files fs.FS, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		done, err := Up(db, service, files)
+		for _, migration := range done {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+			steps = n
+		}
+		done, err := Down(db, service, files, steps)
+		for _, migration := range done {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		applied, pending, err := Status(db, service, files)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			fmt.Printf(
+				"applied  %04d_%s  %s\n",
+				migration.Version,
+				migration.Name,
+				time.Unix(migration.Applied_at, 0).Format(time.RFC3339),
+			)
+		}
+		for _, migration := range pending {
+			fmt.Printf("pending  %04d_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q, expected up, down [steps] or status", command)
+}
@@ -0,0 +1,34 @@
+// Package secret guards the routes the backend services and the telegram bot
+// call on each other, nginx proxies /api only.
+package secret
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// Internal guards the internal routes other services call, they send
+// INTERNAL_SECRET in X-Internal-Secret, without a configured secret the routes are closed
+func Internal(key string) func(next http.Handler) http.Handler {
+	return require("X-Internal-Secret", key)
+}
+
+// Bot guards the internal routes of the telegram bot, it sends
+// TELEGRAM_BOT_SECRET in X-Bot-Secret, without a configured secret the routes are closed
+func Bot(key string) func(next http.Handler) http.Handler {
+	return require("X-Bot-Secret", key)
+}
+
+func require(header string, key string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := r.Header.Get(header)
+			if key == "" || subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
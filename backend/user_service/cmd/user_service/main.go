@@ -2,11 +2,18 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"os"
+	// timezones of the digest settings are checked without tzdata in the image
+	_ "time/tzdata"
 	handlers "user_service/internal/handlers"
 	user_kafka "user_service/internal/kafka"
 	dbconn "user_service/internal/repository"
 
+	"shared/migrate"
+	"shared/secret"
+
 	"github.com/rs/zerolog/log"
 
 	"github.com/go-chi/chi/v5"
@@ -16,11 +23,48 @@ func main() {
 	var db *sql.DB = dbconn.GetDbConnection()
 	defer db.Close()
 
-	go user_kafka.RunKafkaListener(db)
+	// `user_service migrate [up | down [steps] | status]` runs instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.RunCommand(db, "user_service", dbconn.Migrations, os.Args[2:]); err != nil {
+			log.Fatal().
+				Err(err).
+				Str("service", "user service").
+				Msg("Migrate failed")
+		}
+		return
+	}
+
+	if _, err := migrate.Up(db, "user_service", dbconn.Migrations); err != nil {
+		log.Fatal().
+			Err(err).
+			Str("service", "user service").
+			Msg("Migrations failed")
+	}
+
+	// kafka
+	kafkaUrl := fmt.Sprintf("%v:%v", os.Getenv("KAFKA_HOST"), os.Getenv("KAFKA_PORT"))
+	writer := user_kafka.GetKafkaWriter(kafkaUrl)
+	defer writer.Close()
+
+	go user_kafka.RunKafkaListener(db, writer)
 
 	r := chi.NewRouter()
 
 	r.Get("/api/user/existence", handlers.CheckUserExistence(db))
+	r.Get("/api/user/profile", handlers.GetProfile(db))
+	r.Put("/api/user/locale", handlers.SetLocale(db))
+	r.Get("/api/user/settings", handlers.GetSettings(db))
+	r.Put("/api/user/settings", handlers.SetSettings(db))
+
+	r.Group(func(r chi.Router) {
+		r.Use(secret.Internal(os.Getenv("INTERNAL_SECRET")))
+		r.Get("/internal/user/export", handlers.ExportUser(db))
+		r.Get("/internal/user/timezones", handlers.UserTimezones(db))
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(secret.Bot(os.Getenv("TELEGRAM_BOT_SECRET")))
+		r.Get("/internal/user/digests", handlers.DigestSubscribers(db))
+	})
 
 	log.Info().Msg("User service is running")
 	http.ListenAndServe(":8080", r)
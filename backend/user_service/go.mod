@@ -15,5 +15,9 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
 )
+
+require shared v0.0.0
+
+replace shared => ../shared
@@ -0,0 +1,154 @@
+package user_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	models "user_service/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+const authCheckUrl = "http://auth_service:8080/api/auth/check_token"
+
+type httpError struct {
+	Code  int
+	Msg   string
+	Error error
+}
+
+func getUserIdFromToken(r *http.Request) (models.UserInfo, httpError) {
+	var userInfo models.UserInfo
+	var httpErr httpError
+
+	sessionCookie, err := r.Cookie("session_token")
+	if err != nil || sessionCookie.Value == "" {
+		log.Error().Err(err).Msg("cookie parse error")
+		httpErr.Code = http.StatusUnauthorized
+		httpErr.Msg = "Unauthorized"
+		httpErr.Error = fmt.Errorf("no session cookie")
+		return userInfo, httpErr
+	}
+
+	req, err := http.NewRequest("GET", authCheckUrl, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("check token request")
+		httpErr.Code = http.StatusInternalServerError
+		httpErr.Msg = "Internal server error"
+		httpErr.Error = err
+		return userInfo, httpErr
+	}
+	req.AddCookie(&http.Cookie{Name: "session_token", Value: sessionCookie.Value})
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Msg("check token in id parse from token")
+		httpErr.Code = http.StatusInternalServerError
+		httpErr.Msg = "Internal server error"
+		httpErr.Error = err
+		return userInfo, httpErr
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error().Int("status", resp.StatusCode).Msg("check token in id parse from token")
+		httpErr.Code = resp.StatusCode
+		httpErr.Msg = http.StatusText(resp.StatusCode)
+		httpErr.Error = fmt.Errorf("check token error")
+		return userInfo, httpErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+		log.Error().Err(err).Msg("user info json decode")
+		httpErr.Code = http.StatusInternalServerError
+		httpErr.Msg = "Internal server error"
+		httpErr.Error = err
+		return userInfo, httpErr
+	}
+
+	return userInfo, httpErr
+}
+
+// GetProfile returns the profile of the logged in user, clients read the locale from it
+func GetProfile(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var profile models.UserProfile
+		var username, email, locale sql.NullString
+
+		query := `SELECT id, username, email, locale
+							FROM users
+							WHERE id = $1`
+		err := db.QueryRow(query, userInfo.User_id).Scan(&profile.User_id, &username, &email, &locale)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+			log.Error().Err(err).Msg("profile receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		profile.Username = username.String
+		profile.Email = email.String
+		profile.Locale = locale.String
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(profile); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+// SetLocale stores the language of the user, an empty locale clears the choice
+func SetLocale(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var req models.UserLocaleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("locale json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if req.Locale != "" && !slices.Contains(models.Locales, req.Locale) {
+			log.Error().Str("locale", req.Locale).Msg("unsupported locale")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		var locale sql.NullString
+		if req.Locale != "" {
+			locale = sql.NullString{String: req.Locale, Valid: true}
+		}
+
+		query := `UPDATE users SET locale = $1 WHERE id = $2`
+		res, err := db.Exec(query, locale, userInfo.User_id)
+		if err != nil {
+			log.Error().Err(err).Msg("locale update")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,189 @@
+package user_handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	models "user_service/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetSettings returns the digest settings of the logged in user
+func GetSettings(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var settings models.UserSettings
+
+		query := `SELECT timezone, daily_digest, weekly_digest
+							FROM users
+							WHERE id = $1`
+		err := db.QueryRow(query, userInfo.User_id).
+			Scan(&settings.Timezone, &settings.Daily_digest, &settings.Weekly_digest)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+			log.Error().Err(err).Msg("settings receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(settings); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+// SetSettings replaces the digest settings, the timezone must be an IANA name or empty for UTC
+func SetSettings(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userInfo, errInfo := getUserIdFromToken(r)
+		if errInfo.Error != nil {
+			http.Error(w, errInfo.Msg, errInfo.Code)
+			return
+		}
+
+		var settings models.UserSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			log.Error().Err(err).Msg("settings json decode")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		// "Local" names the zone of the server, not a zone of the user
+		if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "Local" {
+			log.Error().Str("timezone", settings.Timezone).Msg("unknown timezone")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		query := `UPDATE users SET timezone = $1, daily_digest = $2, weekly_digest = $3
+							WHERE id = $4`
+		res, err := db.Exec(query, settings.Timezone, settings.Daily_digest, settings.Weekly_digest, userInfo.User_id)
+		if err != nil {
+			log.Error().Err(err).Msg("settings update")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(settings); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+// DigestSubscribers lists the users with a digest turned on for the telegram bot, guarded by the bot secret
+func DigestSubscribers(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := `SELECT id, locale, timezone, daily_digest, weekly_digest
+							FROM users
+							WHERE daily_digest OR weekly_digest`
+		rows, err := db.Query(query)
+		if err != nil {
+			log.Error().Err(err).Msg("digest subscribers receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		subscribers := make([]models.DigestSubscriber, 0)
+
+		for rows.Next() {
+			var subscriber models.DigestSubscriber
+			var locale sql.NullString
+
+			err := rows.Scan(
+				&subscriber.User_id,
+				&locale,
+				&subscriber.Timezone,
+				&subscriber.Daily_digest,
+				&subscriber.Weekly_digest,
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("digest subscriber scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			subscriber.Locale = locale.String
+
+			subscribers = append(subscribers, subscriber)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("digest subscribers receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(subscribers); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
+
+// UserTimezones lists the users who set a timezone, category_service reminds
+// of habits in them; guarded by the internal secret
+func UserTimezones(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := `SELECT id, timezone
+							FROM users
+							WHERE timezone <> ''`
+		rows, err := db.Query(query)
+		if err != nil {
+			log.Error().Err(err).Msg("user timezones receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		defer rows.Close()
+
+		timezones := make([]models.UserTimezone, 0)
+
+		for rows.Next() {
+			var timezone models.UserTimezone
+			if err := rows.Scan(&timezone.User_id, &timezone.Timezone); err != nil {
+				log.Error().Err(err).Msg("user timezone scan")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			timezones = append(timezones, timezone)
+		}
+
+		if err := rows.Err(); err != nil {
+			log.Error().Err(err).Msg("user timezones receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(timezones); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
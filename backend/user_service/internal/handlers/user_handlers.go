@@ -3,10 +3,15 @@ package user_handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 	models "user_service/internal/models"
 
+	"shared/events"
+
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
@@ -29,24 +34,32 @@ func AddUser(db *sql.DB, data []byte) error {
 	return nil
 }
 
-func DeleteUser(db *sql.DB, data []byte) error {
-	var event models.UserDeleteInfo
+func DeleteUser(db *sql.DB, data []byte) (models.UserPurgedEvent, error) {
+	var purgedEvent models.UserPurgedEvent
 
-	if err := json.Unmarshal(data, &event); err != nil {
+	userId, err := events.ParseDeletedUserId(data)
+	if err != nil {
 		log.Error().Err(err).Msg("user-deleted parse failed")
-		return err
+		return purgedEvent, err
 	}
-	log.Info().Msg("processing user-created")
+	log.Info().Msg("processing user-deleted")
 
 	query := `DELETE FROM users
 						WHERE id = $1`
-	_, err := db.Exec(query, event.User_id)
+	res, err := db.Exec(query, userId)
 	if err != nil {
 		log.Error().Err(err).Msg("user info remove error")
-		return err
+		return purgedEvent, err
 	}
 
-	return nil
+	affected, _ := res.RowsAffected()
+
+	purgedEvent.User_id = userId
+	purgedEvent.Service = "user_service"
+	purgedEvent.Purged_rows = affected
+	purgedEvent.Purged_at = time.Now().Unix()
+
+	return purgedEvent, nil
 }
 
 func CheckUserExistence(db *sql.DB) http.HandlerFunc {
@@ -54,3 +67,42 @@ func CheckUserExistence(db *sql.DB) http.HandlerFunc {
 		fmt.Print("asdf")
 	}
 }
+
+// ExportUser is an internal endpoint used by auth_service to collect
+// the profile of a user, it is guarded by the internal secret
+func ExportUser(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := uuid.Parse(r.URL.Query().Get("user_id"))
+		if err != nil {
+			log.Error().Err(err).Msg("export user id parse")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		var profile models.UserRegisterInfo
+		var username, email sql.NullString
+
+		query := `SELECT id, username, email
+							FROM users
+							WHERE id = $1`
+		err = db.QueryRow(query, userId).Scan(&profile.User_id, &username, &email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "Not found", http.StatusNotFound)
+				return
+			}
+			log.Error().Err(err).Msg("export user receiving")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		profile.Username = username.String
+		profile.Email = email.String
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(profile); err != nil {
+			log.Error().Err(err).Msg("failed to write json response")
+		}
+	}
+}
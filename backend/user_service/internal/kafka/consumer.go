@@ -7,6 +7,9 @@ import (
 	"os"
 	"strings"
 	handlers "user_service/internal/handlers"
+	models "user_service/internal/models"
+
+	"shared/events"
 
 	"github.com/rs/zerolog/log"
 	kafka "github.com/segmentio/kafka-go"
@@ -23,7 +26,7 @@ func getKafkaReader(kafkaURL string, topics []string, groupID string) *kafka.Rea
 	})
 }
 
-func RunKafkaListener(db *sql.DB) {
+func RunKafkaListener(db *sql.DB, writer *kafka.Writer) {
 	kafkaURL := fmt.Sprintf("%v:%v", os.Getenv("KAFKA_HOST"), os.Getenv("KAFKA_PORT"))
 	topics := []string{"user-created", "user-deleted"}
 	groupID := "1"
@@ -35,7 +38,7 @@ func RunKafkaListener(db *sql.DB) {
 	log.Info().Msg("Start consuming kafka topic")
 
 	for {
-		m, err := reader.ReadMessage(context.Background())
+		m, err := reader.FetchMessage(context.Background())
 		if err != nil {
 			log.Fatal().Err(err).Msg("kafka message reading fatal")
 		}
@@ -51,12 +54,27 @@ func RunKafkaListener(db *sql.DB) {
 		case "user-deleted":
 			log.Info().Msg("user-deleted message received")
 
-			err = handlers.DeleteUser(db, m.Value)
+			// committing the message before the purge and the user-purged event went
+			// through would drop them for good, so failures are retried
+			var event models.UserPurgedEvent
+			err := events.Retry("user-deleted processing", func() error {
+				var err error
+				event, err = handlers.DeleteUser(db, m.Value)
+				return err
+			})
 			if err != nil {
-				log.Error().Msg("user-deleted processing failed")
+				log.Error().Err(err).Msg("user-deleted processing failed")
+			} else {
+				events.Retry("user-purged sending", func() error {
+					return sendUserPurgedEvent(context.Background(), writer, event)
+				})
 			}
 		default:
 			log.Error().Msg("topic undefined")
 		}
+
+		if err := reader.CommitMessages(context.Background(), m); err != nil {
+			log.Fatal().Err(err).Msg("kafka message commit fatal")
+		}
 	}
 }
@@ -8,6 +8,49 @@ type UserRegisterInfo struct {
 	Email    string    `json:"email"`
 }
 
-type UserDeleteInfo struct {
-	User_id uuid.UUID `json:"id"`
+type UserPurgedEvent struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Service     string    `json:"service"`
+	Purged_rows int64     `json:"purged_rows"`
+	Purged_at   int64     `json:"purged_at"`
+}
+
+// locales the clients have messages for
+var Locales = []string{"en", "ru"}
+
+type UserProfile struct {
+	User_id  uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Locale   string    `json:"locale"`
+}
+
+type UserLocaleRequest struct {
+	Locale string `json:"locale"`
+}
+
+type UserInfo struct {
+	User_id uuid.UUID `json:"user_id"`
+}
+
+// UserSettings are the digest settings of the user, the digests go out in the timezone
+type UserSettings struct {
+	Timezone      string `json:"timezone"`
+	Daily_digest  bool   `json:"daily_digest"`
+	Weekly_digest bool   `json:"weekly_digest"`
+}
+
+// DigestSubscriber is a user who opted into a digest, the bot schedules them by timezone
+type DigestSubscriber struct {
+	User_id       uuid.UUID `json:"user_id"`
+	Locale        string    `json:"locale"`
+	Timezone      string    `json:"timezone"`
+	Daily_digest  bool      `json:"daily_digest"`
+	Weekly_digest bool      `json:"weekly_digest"`
+}
+
+// UserTimezone is the timezone of a user for the reminders of category_service, users without one are in UTC
+type UserTimezone struct {
+	User_id  uuid.UUID `json:"user_id"`
+	Timezone string    `json:"timezone"`
 }
@@ -0,0 +1,9 @@
+package connection
+
+import "embed"
+
+// Migrations holds migrations/NNNN_name.up.sql and NNNN_name.down.sql,
+// run by shared/migrate on startup and by the migrate subcommand
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
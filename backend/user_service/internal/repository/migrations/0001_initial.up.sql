@@ -1,6 +1,7 @@
+-- IF NOT EXISTS adopts databases created by the old db/init/schema.sql
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
   id UUID PRIMARY KEY,
   username VARCHAR(255),
   email VARCHAR(255) UNIQUE
@@ -0,0 +1,2 @@
+-- NULL means the user never chose, clients fall back to their own language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(8);
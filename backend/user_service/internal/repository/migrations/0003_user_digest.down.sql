@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_digest;
+ALTER TABLE users DROP COLUMN IF EXISTS daily_digest;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
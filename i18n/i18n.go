@@ -0,0 +1,144 @@
+// Package i18n is the locale engine of tCli and the telegram bot, each of them
+// passes in its own catalogue of messages.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Locale string
+
+const (
+	En Locale = "en"
+	Ru Locale = "ru"
+
+	Default = En
+)
+
+var Supported = []Locale{En, Ru}
+
+// Parse maps values like "ru", "ru-RU" or "ru_RU.UTF-8" to a supported locale
+func Parse(value string) (Locale, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, "-_.@"); i >= 0 {
+		value = value[:i]
+	}
+	for _, locale := range Supported {
+		if value == string(locale) {
+			return locale, true
+		}
+	}
+	return Default, false
+}
+
+// Resolve picks the first supported of the values, the default without one
+func Resolve(values ...string) Locale {
+	for _, value := range values {
+		if locale, ok := Parse(value); ok {
+			return locale
+		}
+	}
+	return Default
+}
+
+// pluralForm is the index of the form for n: one, few, many for ru and one, other for en
+func pluralForm(locale Locale, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	switch locale {
+	case Ru:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	}
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// pluralForms is how many forms a plural message has in the locale
+var pluralForms = map[Locale]int{En: 2, Ru: 3}
+
+// Catalogue holds the messages of an application by key and locale, plural
+// messages hold their forms separated by "|"; Duration needs the
+// duration.less_than_minute, duration.minutes and duration.hours messages
+type Catalogue map[string]map[Locale]string
+
+func (c Catalogue) lookup(locale Locale, key string) string {
+	if text, ok := c[key][locale]; ok {
+		return text
+	}
+	if text, ok := c[key][Default]; ok {
+		return text
+	}
+	// a missing message shows up in the output instead of panicking
+	return key
+}
+
+// Check returns the messages missing in a supported locale or with a wrong
+// number of plural forms, the tests of the catalogues run it
+func (c Catalogue) Check() []error {
+	var errs []error
+	for key, texts := range c {
+		for _, locale := range Supported {
+			text, ok := texts[locale]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s has no %s message", key, locale))
+				continue
+			}
+			forms := strings.Count(text, "|") + 1
+			if forms > 1 && forms != pluralForms[locale] {
+				errs = append(errs, fmt.Errorf("%s in %s has %d plural forms, want %d", key, locale, forms, pluralForms[locale]))
+			}
+		}
+	}
+	return errs
+}
+
+// Printer returns the printer of the catalogue in the locale
+func (c Catalogue) Printer(locale Locale) Printer {
+	return Printer{Locale: locale, catalogue: c}
+}
+
+// Printer formats the messages of one catalogue in one locale
+type Printer struct {
+	Locale    Locale
+	catalogue Catalogue
+}
+
+func (p Printer) T(key string, args ...any) string {
+	text := p.catalogue.lookup(p.Locale, key)
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N picks the plural form for n, the forms are separated by "|" and get n as the first argument
+func (p Printer) N(key string, n int, args ...any) string {
+	forms := strings.Split(p.catalogue.lookup(p.Locale, key), "|")
+	form := forms[min(pluralForm(p.Locale, n), len(forms)-1)]
+	return fmt.Sprintf(form, append([]any{n}, args...)...)
+}
+
+// Duration spells the duration out in hours and minutes, e.g. "1 hour 5 minutes"
+func (p Printer) Duration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes < 1:
+		return p.T("duration.less_than_minute")
+	case minutes < 60:
+		return p.N("duration.minutes", minutes)
+	case minutes%60 == 0:
+		return p.N("duration.hours", minutes/60)
+	}
+	return p.N("duration.hours", minutes/60) + " " + p.N("duration.minutes", minutes%60)
+}
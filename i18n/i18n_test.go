@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+var testCatalogue = Catalogue{
+	"duration.less_than_minute": {
+		En: "less than a minute",
+		Ru: "меньше минуты",
+	},
+	"duration.minutes": {
+		En: "%d minute|%d minutes",
+		Ru: "%d минуту|%d минуты|%d минут",
+	},
+	"duration.hours": {
+		En: "%d hour|%d hours",
+		Ru: "%d час|%d часа|%d часов",
+	},
+	"greeting": {
+		En: "Hello, %s",
+	},
+}
+
+func TestPluralForm(t *testing.T) {
+	cases := []struct {
+		locale Locale
+		n      int
+		want   int
+	}{
+		{En, 0, 1}, {En, 1, 0}, {En, 2, 1}, {En, 11, 1},
+		{Ru, 0, 2}, {Ru, 1, 0}, {Ru, 2, 1}, {Ru, 4, 1}, {Ru, 5, 2},
+		{Ru, 11, 2}, {Ru, 12, 2}, {Ru, 14, 2}, {Ru, 21, 0}, {Ru, 22, 1},
+		{Ru, 101, 0}, {Ru, 111, 2}, {Ru, 112, 2}, {Ru, 124, 1},
+	}
+	for _, c := range cases {
+		if got := pluralForm(c.locale, c.n); got != c.want {
+			t.Errorf("pluralForm(%s, %d) = %d, want %d", c.locale, c.n, got, c.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for value, want := range map[string]Locale{
+		"ru": Ru, "ru-RU": Ru, "ru_RU.UTF-8": Ru, "EN": En, "en_US": En,
+	} {
+		if got, ok := Parse(value); !ok || got != want {
+			t.Errorf("Parse(%q) = %s, %t, want %s", value, got, ok, want)
+		}
+	}
+	for _, value := range []string{"", "C", "de_DE.UTF-8"} {
+		if _, ok := Parse(value); ok {
+			t.Errorf("Parse(%q) accepted an unsupported locale", value)
+		}
+	}
+	if got := Resolve("", "C.UTF-8", "ru_RU.UTF-8"); got != Ru {
+		t.Errorf("Resolve = %s, want ru", got)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	ru := testCatalogue.Printer(Ru)
+	en := testCatalogue.Printer(En)
+
+	if got := ru.Duration(65 * time.Minute); got != "1 час 5 минут" {
+		t.Errorf("ru 65m = %q", got)
+	}
+	if got := en.Duration(2*time.Hour + time.Minute); got != "2 hours 1 minute" {
+		t.Errorf("en 2h1m = %q", got)
+	}
+	if got := ru.Duration(21 * time.Minute); got != "21 минуту" {
+		t.Errorf("ru 21m = %q", got)
+	}
+	if got := ru.T("greeting", "Halo"); got != "Hello, Halo" {
+		t.Errorf("ru without a ru message = %q", got)
+	}
+	if got := en.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	catalogue := Catalogue{
+		"complete": {En: "%d note|%d notes", Ru: "%d заметка|%d заметки|%d заметок"},
+		"no_ru":    {En: "note"},
+		"two_ru":   {En: "%d note|%d notes", Ru: "%d заметка|%d заметок"},
+	}
+	if errs := catalogue.Check(); len(errs) != 2 {
+		t.Errorf("Check = %v, want the missing ru message and the wrong plural forms", errs)
+	}
+}
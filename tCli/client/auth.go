@@ -18,8 +18,8 @@ func Login(login, password string) (string, error) {
 
 	body, _ := json.Marshal(data)
 
-	resp, err := http.Post(
-		"http://localhost:8080/api/auth/login",
+	resp, err := httpClient.Post(
+		apiUrl("/api/auth/login"),
 		"application/json",
 		bytes.NewBuffer(body),
 	)
@@ -62,8 +62,8 @@ func Register(login, password, username, email string) (string, error) {
 
 	body, _ := json.Marshal(data)
 
-	resp, err := http.Post(
-		"http://localhost:8080/api/auth/register",
+	resp, err := httpClient.Post(
+		apiUrl("/api/auth/register"),
 		"application/json",
 		bytes.NewBuffer(body),
 	)
@@ -90,3 +90,34 @@ func Register(login, password, username, email string) (string, error) {
 
 	return session_token, nil
 }
+
+// Logout revokes the session token on the server
+func Logout(sessionToken string) error {
+	req, err := http.NewRequest("POST", apiUrl("/api/auth/logout"), nil)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("Client logout request")
+		return err
+	}
+	defer resp.Body.Close()
+
+	// an unknown or expired token is already revoked
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		logger.Logger.Error().
+			Err(fmt.Errorf("status %d", resp.StatusCode)).
+			Msg("Client logout status code")
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return nil
+}
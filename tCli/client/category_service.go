@@ -0,0 +1,150 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"halo/logger"
+	"halo/models"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// categoryPageSize is the page size of GET /api/category
+const categoryPageSize = 10
+
+var (
+	ErrCategoryExists   = errors.New("category already exists")
+	ErrCategoryNotFound = errors.New("category not found")
+)
+
+// sendCategory sends a category to /api/category and decodes the category from the response when out is set
+func sendCategory(
+	sessionToken string,
+	method string,
+	category models.CategoryStruct,
+	out *models.CategoryStruct,
+) error {
+	body, err := json.Marshal(category)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("marshal category")
+		return fmt.Errorf("marshal category: %w", err)
+	}
+
+	req, err := http.NewRequest(
+		method,
+		apiUrl("/api/category"),
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusConflict:
+		return ErrCategoryExists
+	case http.StatusNotFound:
+		return ErrCategoryNotFound
+	case http.StatusBadRequest:
+		// the service answers 400 when deleting a category it does not know
+		if method == "DELETE" {
+			return ErrCategoryNotFound
+		}
+		logger.Logger.Error().Msg("request status code")
+		return fmt.Errorf("request status code: %d", resp.StatusCode)
+	default:
+		logger.Logger.Error().Msg("request status code")
+		return fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode category")
+		return fmt.Errorf("decode category: %w", err)
+	}
+	return nil
+}
+
+func AddCategory(sessionToken string, name string) (models.CategoryStruct, error) {
+	var category models.CategoryStruct
+	err := sendCategory(sessionToken, "POST", models.CategoryStruct{Name: name}, &category)
+	return category, err
+}
+
+func RenameCategory(sessionToken string, id uuid.UUID, name string) (models.CategoryStruct, error) {
+	var category models.CategoryStruct
+	err := sendCategory(sessionToken, "PUT", models.CategoryStruct{Id: id, Name: name}, &category)
+	return category, err
+}
+
+func DeleteCategory(sessionToken string, id uuid.UUID) error {
+	return sendCategory(sessionToken, "DELETE", models.CategoryStruct{Id: id}, nil)
+}
+
+// GetCategories walks all pages of the user's categories
+func GetCategories(sessionToken string) ([]models.CategoryStruct, error) {
+	categories := make([]models.CategoryStruct, 0)
+
+	for page := 1; ; page++ {
+		req, err := http.NewRequest(
+			"GET",
+			apiUrl(fmt.Sprintf("/api/category?page=%d", page)),
+			nil,
+		)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("new request")
+			return nil, fmt.Errorf("new request: %w", err)
+		}
+
+		req.AddCookie(&http.Cookie{
+			Name:  "session_token",
+			Value: sessionToken,
+		})
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("do request")
+			return nil, fmt.Errorf("do request: %w", err)
+		}
+
+		var pageCategories []models.CategoryStruct
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			logger.Logger.Error().Msg("request status code")
+			return nil, fmt.Errorf("request status code: %d", resp.StatusCode)
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&pageCategories)
+		resp.Body.Close()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("decode categories")
+			return nil, fmt.Errorf("decode categories: %w", err)
+		}
+
+		categories = append(categories, pageCategories...)
+		if len(pageCategories) < categoryPageSize {
+			return categories, nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"halo/config"
+	"net/http"
+	"os"
+	"time"
+)
+
+// httpClient and baseUrl are shared by every request of the client package,
+// Init replaces the defaults with the active profile
+var (
+	httpClient = &http.Client{Timeout: config.DefaultTimeout * time.Second}
+	baseUrl    = config.DefaultServer
+)
+
+func Init(profile config.Profile) error {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: profile.Tls_insecure_skip_verify,
+	}
+
+	if profile.Tls_ca_file != "" {
+		pem, err := os.ReadFile(profile.Tls_ca_file)
+		if err != nil {
+			return fmt.Errorf("read tls ca file: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", profile.Tls_ca_file)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = time.Duration(profile.Timeout_seconds) * time.Second
+
+	httpClient = &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(profile.Timeout_seconds) * time.Second,
+	}
+	baseUrl = profile.Server
+
+	return nil
+}
+
+func apiUrl(path string) string {
+	return baseUrl + path
+}
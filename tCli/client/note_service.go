@@ -3,22 +3,139 @@ package client
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"halo/logger"
 	"halo/models"
 	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
 )
 
-func SendNoteToService(sessionToken string, noteInfo models.NoteStruct) error {
+// ErrNoteNotFound means the service has no such note of the user,
+// e.g. it was created offline and never synced
+var ErrNoteNotFound = errors.New("note not found")
+
+// ErrNoteRejected means the service refused the note itself, sending it again does not help
+var ErrNoteRejected = errors.New("note rejected by the service")
+
+// rejected tells a request the service refused from one worth repeating later,
+// an expired session or a rate limit is not the fault of the note
+func rejected(statusCode int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return statusCode >= 400 && statusCode < 500
+}
+
+// SendNoteToService returns the note as stored by the service, which is the
+// existing one when the same note was already sent
+func SendNoteToService(sessionToken string, noteInfo models.NoteStruct) (models.NoteStruct, error) {
+	var stored models.NoteStruct
+
 	noteBody, err := json.Marshal(noteInfo)
 	if err != nil {
 		logger.Logger.Error().Err(err).Msg("marshal note info")
-		return fmt.Errorf("marshal note info: %w", err)
+		return stored, fmt.Errorf("marshal note info: %w", err)
 	}
 
 	req, err := http.NewRequest(
 		"POST",
-		"http://localhost:8080/api/note",
+		apiUrl("/api/note"),
+		bytes.NewBuffer(noteBody),
+	)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return stored, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return stored, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if rejected(resp.StatusCode) {
+		logger.Logger.Error().Int("status", resp.StatusCode).Msg("note rejected")
+		return stored, fmt.Errorf("%w: status code %d", ErrNoteRejected, resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return stored, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&stored); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode stored note")
+		return stored, fmt.Errorf("decode stored note: %w", err)
+	}
+
+	return stored, nil
+}
+
+func ImportNotes(
+	sessionToken string,
+	data []byte,
+	contentType string,
+) (models.NoteImportReport, error) {
+	var report models.NoteImportReport
+
+	req, err := http.NewRequest(
+		"POST",
+		apiUrl("/api/note/import"),
+		bytes.NewBuffer(data),
+	)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return report, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return report, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return report, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode import report")
+		return report, fmt.Errorf("decode import report: %w", err)
+	}
+
+	return report, nil
+}
+
+func DeleteNote(sessionToken string, noteId uuid.UUID) error {
+	noteBody, err := json.Marshal(map[string]uuid.UUID{"note_id": noteId})
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("marshal note id")
+		return fmt.Errorf("marshal note id: %w", err)
+	}
+
+	req, err := http.NewRequest(
+		"DELETE",
+		apiUrl("/api/note"),
 		bytes.NewBuffer(noteBody),
 	)
 	if err != nil {
@@ -32,14 +149,17 @@ func SendNoteToService(sessionToken string, noteInfo models.NoteStruct) error {
 		Value: sessionToken,
 	})
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		logger.Logger.Error().Err(err).Msg("do request")
 		return fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNoteNotFound
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		logger.Logger.Error().Msg("request status code")
 		return fmt.Errorf("request status code: %d", resp.StatusCode)
@@ -47,3 +167,46 @@ func SendNoteToService(sessionToken string, noteInfo models.NoteStruct) error {
 
 	return nil
 }
+
+// SearchNotes returns one page of the notes matching the text, best matches first,
+// categoryId narrows the search when it is not uuid.Nil
+func SearchNotes(sessionToken string, text string, categoryId uuid.UUID, page int) ([]models.NoteStruct, error) {
+	var notes []models.NoteStruct
+
+	params := url.Values{}
+	params.Set("q", text)
+	params.Set("page", strconv.Itoa(page))
+	if categoryId != uuid.Nil {
+		params.Set("category_id", categoryId.String())
+	}
+
+	req, err := http.NewRequest("GET", apiUrl("/api/note/search?"+params.Encode()), nil)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return notes, fmt.Errorf("new request: %w", err)
+	}
+
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return notes, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return notes, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&notes); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode notes")
+		return notes, fmt.Errorf("decode notes: %w", err)
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"halo/logger"
+	"halo/models"
+	"net/http"
+)
+
+// GetNoteChanges returns the changes stored after cursor, zero pulls every note
+func GetNoteChanges(sessionToken string, cursor int64) (models.NoteChanges, error) {
+	var changes models.NoteChanges
+
+	req, err := http.NewRequest(
+		"GET",
+		apiUrl(fmt.Sprintf("/api/note/changes?cursor=%d", cursor)),
+		nil,
+	)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return changes, fmt.Errorf("new request: %w", err)
+	}
+
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return changes, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return changes, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode note changes")
+		return changes, fmt.Errorf("decode note changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+func PushNoteChanges(
+	sessionToken string,
+	request models.NoteSyncRequest,
+) (models.NoteSyncResponse, error) {
+	var response models.NoteSyncResponse
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("marshal note changes")
+		return response, fmt.Errorf("marshal note changes: %w", err)
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		apiUrl("/api/note/sync"),
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return response, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return response, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return response, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode sync response")
+		return response, fmt.Errorf("decode sync response: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"halo/logger"
+	"halo/models"
+	"net/http"
+)
+
+// ErrLinkCodeNotFound means the code is wrong, expired or was already used
+var ErrLinkCodeNotFound = errors.New("link code not found or expired")
+
+// LinkTelegram confirms the one-time code the bot sent to a telegram chat
+func LinkTelegram(sessionToken string, code string) (models.TelegramLink, error) {
+	var link models.TelegramLink
+
+	body, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("marshal link code")
+		return link, fmt.Errorf("marshal link code: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", apiUrl("/api/auth/telegram/link"), bytes.NewBuffer(body))
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return link, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return link, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return link, ErrLinkCodeNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return link, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&link); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode telegram link")
+		return link, fmt.Errorf("decode telegram link: %w", err)
+	}
+
+	return link, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"halo/logger"
+	"halo/models"
+	"net/http"
+)
+
+// GetProfile returns the profile of the logged in user
+func GetProfile(sessionToken string) (models.UserProfile, error) {
+	var profile models.UserProfile
+
+	req, err := http.NewRequest("GET", apiUrl("/api/user/profile"), nil)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return profile, fmt.Errorf("new request: %w", err)
+	}
+
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return profile, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return profile, fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		logger.Logger.Error().Err(err).Msg("decode profile")
+		return profile, fmt.Errorf("decode profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// SetLocale saves the language to the profile, an empty locale clears it
+func SetLocale(sessionToken string, locale string) error {
+	body, err := json.Marshal(models.UserLocaleRequest{Locale: locale})
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("marshal locale")
+		return fmt.Errorf("marshal locale: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", apiUrl("/api/user/locale"), bytes.NewBuffer(body))
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("new request")
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("do request")
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Logger.Error().Msg("request status code")
+		return fmt.Errorf("request status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v3"
+)
+
+// refreshCategories fetches the categories from the server into the local cache
+func refreshCategories(token string) ([]models.CategoryStruct, error) {
+	categories, err := client.GetCategories(token)
+	if err != nil {
+		return nil, fmt.Errorf("get categories: %w", err)
+	}
+
+	if err := localstore.SaveCategoriesLocally(categories); err != nil {
+		logger.Logger.Error().Err(err).Msg("save categories")
+	}
+
+	return categories, nil
+}
+
+// resolveCategory accepts a category id or name, unknown names refresh the cache once
+func resolveCategory(token string, value string) (models.CategoryStruct, error) {
+	if id, err := uuid.Parse(value); err == nil {
+		return models.CategoryStruct{Id: id}, nil
+	}
+
+	category, found, err := localstore.GetCategoryByName(value)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get local category")
+		return category, fmt.Errorf("get local category: %w", err)
+	}
+	if found {
+		return category, nil
+	}
+
+	if token != "" {
+		if _, err := refreshCategories(token); err != nil {
+			logger.Logger.Error().Err(err).Msg("refresh categories")
+		} else if category, found, err = localstore.GetCategoryByName(value); err == nil && found {
+			return category, nil
+		}
+	}
+
+	return category, fmt.Errorf("category %q not found", value)
+}
+
+func printCategoryNames(cmd *cli.Command) {
+	categories, err := localstore.GetCategoriesLocally()
+	if err != nil {
+		return
+	}
+	for _, category := range categories {
+		fmt.Fprintln(cmd.Root().Writer, category.Name)
+	}
+}
+
+// completeCategoryArg suggests cached category names for the first argument
+func completeCategoryArg(ctx context.Context, cmd *cli.Command) {
+	if cmd.Args().Len() > 0 {
+		return
+	}
+	printCategoryNames(cmd)
+}
+
+// completeCategoryFlag suggests cached category names after --category
+func completeCategoryFlag(ctx context.Context, cmd *cli.Command) {
+	args := os.Args
+	if len(args) >= 2 {
+		switch args[len(args)-2] {
+		case "--category", "-c":
+			printCategoryNames(cmd)
+			return
+		}
+	}
+	cli.DefaultCompleteWithFlags(ctx, cmd)
+}
+
+var CategoryCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "category",
+	Usage:                  "Manage categories",
+	Commands: []*cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Add category",
+			ArgsUsage: "<name>",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 1 {
+					return fmt.Errorf("no category name")
+				}
+
+				token, err := config.LoadToken()
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("get session token")
+					return fmt.Errorf("get session token: %w", err)
+				}
+
+				category, err := client.AddCategory(token, cmd.Args().Get(0))
+				if errors.Is(err, client.ErrCategoryExists) {
+					return fmt.Errorf("category %q already exists", cmd.Args().Get(0))
+				}
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("add category")
+					return fmt.Errorf("add category: %w", err)
+				}
+
+				if err := localstore.AddCategoryLocally(category); err != nil {
+					logger.Logger.Error().Err(err).Msg("save category")
+				}
+
+				fmt.Println(i18n.T("category.added"))
+				return nil
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "List categories",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				var categories []models.CategoryStruct
+
+				token, err := config.LoadToken()
+				if err == nil {
+					categories, err = refreshCategories(token)
+				}
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("refresh categories")
+
+					categories, err = localstore.GetCategoriesLocally()
+					if err != nil {
+						logger.Logger.Error().Err(err).Msg("get local categories")
+						return fmt.Errorf("get local categories: %w", err)
+					}
+					fmt.Println(i18n.T("category.cached"))
+				}
+
+				if len(categories) == 0 {
+					fmt.Println(i18n.T("category.none"))
+					return nil
+				}
+
+				for _, category := range categories {
+					fmt.Printf("%s  %s\n", category.Id, category.Name)
+				}
+				return nil
+			},
+		},
+		{
+			Name:          "rename",
+			Usage:         "Rename category",
+			ArgsUsage:     "<name|id> <new name>",
+			ShellComplete: completeCategoryArg,
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 2 {
+					return fmt.Errorf("expected category and new name")
+				}
+
+				token, err := config.LoadToken()
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("get session token")
+					return fmt.Errorf("get session token: %w", err)
+				}
+
+				category, err := resolveCategory(token, cmd.Args().Get(0))
+				if err != nil {
+					return err
+				}
+
+				newName := cmd.Args().Get(1)
+				renamed, err := client.RenameCategory(token, category.Id, newName)
+				switch {
+				case errors.Is(err, client.ErrCategoryExists):
+					return fmt.Errorf("category %q already exists", newName)
+				case errors.Is(err, client.ErrCategoryNotFound):
+					_ = localstore.DeleteCategoryLocally(category.Id)
+					return fmt.Errorf("category %q not found", cmd.Args().Get(0))
+				case err != nil:
+					logger.Logger.Error().Err(err).Msg("rename category")
+					return fmt.Errorf("rename category: %w", err)
+				}
+
+				if err := localstore.AddCategoryLocally(renamed); err != nil {
+					logger.Logger.Error().Err(err).Msg("save category")
+				}
+
+				fmt.Println(i18n.T("category.renamed"))
+				return nil
+			},
+		},
+		{
+			Name:          "delete",
+			Usage:         "Delete category",
+			ArgsUsage:     "<name|id>",
+			ShellComplete: completeCategoryArg,
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 1 {
+					return fmt.Errorf("no category")
+				}
+
+				token, err := config.LoadToken()
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("get session token")
+					return fmt.Errorf("get session token: %w", err)
+				}
+
+				category, err := resolveCategory(token, cmd.Args().Get(0))
+				if err != nil {
+					return err
+				}
+
+				err = client.DeleteCategory(token, category.Id)
+				if err != nil && !errors.Is(err, client.ErrCategoryNotFound) {
+					logger.Logger.Error().Err(err).Msg("delete category")
+					return fmt.Errorf("delete category: %w", err)
+				}
+
+				if err := localstore.DeleteCategoryLocally(category.Id); err != nil {
+					logger.Logger.Error().Err(err).Msg("delete local category")
+				}
+
+				fmt.Println(i18n.T("category.deleted"))
+				return nil
+			},
+		},
+	},
+}
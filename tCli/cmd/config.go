@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
+	"halo/logger"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+func printProfile(name string, profile config.Profile) {
+	fmt.Printf("profile:                  %s\n", name)
+	fmt.Printf("server:                   %s\n", profile.Server)
+	fmt.Printf("tls-ca-file:              %s\n", profile.Tls_ca_file)
+	fmt.Printf("tls-insecure-skip-verify: %t\n", profile.Tls_insecure_skip_verify)
+	fmt.Printf("token-path:               %s\n", profile.Token_path)
+	fmt.Printf("secret-store:             %s\n", profile.Secret_store)
+	fmt.Printf("db-path:                  %s\n", profile.Db_path)
+	fmt.Printf("timeout:                  %ds\n", profile.Timeout_seconds)
+	fmt.Printf("locale:                   %s\n", profile.Locale)
+}
+
+func showConfig(ctx context.Context, cmd *cli.Command) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("config:                   %s\n", path)
+	printProfile(config.ActiveProfile())
+	return nil
+}
+
+var ConfigCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "config",
+	Usage:                  "Show and change server profiles",
+	Action:                 showConfig,
+	Commands: []*cli.Command{
+		{
+			Name:   "show",
+			Usage:  "Show the active profile",
+			Action: showConfig,
+		},
+		{
+			Name:  "profiles",
+			Usage: "List profiles",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+
+				for _, name := range config.ProfileNames(cfg) {
+					current := " "
+					if name == cfg.Current_profile {
+						current = "*"
+					}
+					fmt.Printf("%s %s  %s\n", current, name, cfg.Profiles[name].Server)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "add",
+			Usage:     "Add profile",
+			ArgsUsage: "<name> <server url>",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 2 {
+					return fmt.Errorf("expected profile name and server url")
+				}
+
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+
+				name := cmd.Args().Get(0)
+				if _, ok := cfg.Profiles[name]; ok {
+					return fmt.Errorf("profile %q already exists", name)
+				}
+
+				var profile config.Profile
+				if err := config.SetProfileValue(&profile, "server", cmd.Args().Get(1)); err != nil {
+					return err
+				}
+				cfg.Profiles[name] = profile
+
+				if err := config.SaveConfig(cfg); err != nil {
+					return fmt.Errorf("save config: %w", err)
+				}
+
+				fmt.Println(i18n.T("config.profile_added"))
+				return nil
+			},
+		},
+		{
+			Name:      "use",
+			Usage:     "Switch the current profile",
+			ArgsUsage: "<name>",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 1 {
+					return fmt.Errorf("no profile name")
+				}
+
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+
+				name := cmd.Args().Get(0)
+				if _, ok := cfg.Profiles[name]; !ok && name != config.DefaultProfile {
+					return fmt.Errorf("profile %q not found", name)
+				}
+				cfg.Current_profile = name
+
+				if err := config.SaveConfig(cfg); err != nil {
+					return fmt.Errorf("save config: %w", err)
+				}
+
+				fmt.Println(i18n.T("config.using_profile", name))
+				return nil
+			},
+		},
+		{
+			Name:      "set",
+			Usage:     "Change a setting of the active profile",
+			ArgsUsage: "<key> <value>",
+			Description: "keys: " + strings.Join(config.ProfileKeys, ", ") + "\n" +
+				"secret-store is auto, keyring or file; timeout is in seconds\n" +
+				"locale is en or ru, it is saved to the server profile too when logged in\n" +
+				"use --profile to change another profile",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() < 2 {
+					return fmt.Errorf("expected key and value")
+				}
+
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					return err
+				}
+
+				name, _ := config.ActiveProfile()
+				profile := cfg.Profiles[name]
+
+				if err := config.SetProfileValue(&profile, cmd.Args().Get(0), cmd.Args().Get(1)); err != nil {
+					return err
+				}
+				cfg.Profiles[name] = profile
+
+				if err := config.SaveConfig(cfg); err != nil {
+					return fmt.Errorf("save config: %w", err)
+				}
+
+				if cmd.Args().Get(0) == "locale" {
+					i18n.SetLocale(i18n.Resolve(profile.Locale, string(i18n.FromEnv())))
+					// the telegram bot speaks the language of the server profile
+					if token := loadTokenQuietly(); token != "" {
+						if err := client.SetLocale(token, profile.Locale); err != nil {
+							logger.Logger.Error().Err(err).Msg("save locale to profile")
+							fmt.Println(i18n.T("config.locale_not_synced"))
+						}
+					}
+				}
+
+				fmt.Println(i18n.T("config.updated"))
+				return nil
+			},
+		},
+	},
+}
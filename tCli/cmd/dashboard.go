@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+	"halo/logger"
+	"halo/ui"
+
+	"github.com/urfave/cli/v3"
+)
+
+var DashboardCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "dashboard",
+	Usage:                  "Habit heatmap, streaks and weekly summary",
+	Before:                 syncBefore,
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if err := ui.StartDashboard(); err != nil {
+			logger.Logger.Error().Err(err).Msg("ui error")
+			return err
+		}
+		return nil
+	},
+}
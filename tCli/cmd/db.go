@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+var DbCommand = &cli.Command{
+	Name:  "db",
+	Usage: "Local database maintenance",
+	Commands: []*cli.Command{
+		{
+			Name:  "status",
+			Usage: "Show the schema version and pending migrations",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				status, err := localstore.GetMigrationStatus()
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("migration status")
+					return fmt.Errorf("migration status: %w", err)
+				}
+
+				if path, err := localstore.DbPath(); err == nil {
+					fmt.Println(i18n.T("db.path", path))
+				}
+				fmt.Println(i18n.T("db.version", status.Current, status.Latest))
+
+				for _, migration := range status.Applied {
+					fmt.Printf(
+						"  applied  %04d_%s  %s\n",
+						migration.Version,
+						migration.Name,
+						time.Unix(migration.Applied_at, 0).Format("2006-01-02 15:04"),
+					)
+				}
+				for _, migration := range status.Pending {
+					fmt.Printf("  pending  %04d_%s\n", migration.Version, migration.Name)
+				}
+
+				if status.Current > status.Latest {
+					fmt.Println(i18n.T("db.newer"))
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "migrate",
+			Usage: "Back up the database and apply pending migrations",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				applied, backup, err := localstore.Migrate()
+				for _, migration := range applied {
+					fmt.Println(i18n.T("db.applied", migration.Version, migration.Name))
+				}
+				if backup != "" {
+					fmt.Println(i18n.T("db.backup", backup))
+				}
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("migrate local db")
+					return fmt.Errorf("migrate local db: %w", err)
+				}
+
+				if len(applied) == 0 {
+					fmt.Println(i18n.T("db.up_to_date"))
+				}
+				return nil
+			},
+		},
+	},
+}
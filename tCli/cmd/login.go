@@ -12,6 +12,7 @@ import (
 
 	client "halo/client"
 	config "halo/config"
+	i18n "halo/i18n"
 	logger "halo/logger"
 )
 
@@ -21,10 +22,10 @@ var LoginCommand = &cli.Command{
 	Action: func(ctx context.Context, c *cli.Command) error {
 		reader := bufio.NewReader(os.Stdin)
 
-		fmt.Print("Enter login: ")
+		fmt.Print(i18n.T("login.prompt_login"))
 		login, _ := reader.ReadString('\n')
 
-		fmt.Print("Enter password: ")
+		fmt.Print(i18n.T("login.prompt_password"))
 		passwordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
 		fmt.Println()
 		logger.Logger.Err(err).Msg("Read password")
@@ -42,7 +43,33 @@ var LoginCommand = &cli.Command{
 			return fmt.Errorf("failed to save token: %w", err)
 		}
 
-		fmt.Println("Logged in successfully.")
+		adoptProfileLocale(token)
+
+		fmt.Println(i18n.T("login.success", config.SecretStoreName()))
 		return nil
 	},
 }
+
+// adoptProfileLocale takes the language of the server profile unless this
+// profile has its own, the server is optional here so failures are only logged
+func adoptProfileLocale(token string) {
+	if _, profile := config.ActiveProfile(); profile.Locale != "" {
+		return
+	}
+
+	userProfile, err := client.GetProfile(token)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get profile")
+		return
+	}
+
+	locale, ok := i18n.Parse(userProfile.Locale)
+	if !ok {
+		return
+	}
+	if err := config.SetProfileLocale(locale); err != nil {
+		logger.Logger.Error().Err(err).Msg("save profile locale")
+		return
+	}
+	i18n.SetLocale(locale)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	client "halo/client"
+	config "halo/config"
+	i18n "halo/i18n"
+	logger "halo/logger"
+)
+
+var LogoutCommand = &cli.Command{
+	Name:  "logout",
+	Usage: "Revoke the session and remove the stored token",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "local",
+			Usage: "only remove the stored token, e.g. when the server is unreachable",
+		},
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		token, err := config.LoadToken()
+		if errors.Is(err, config.ErrSecretNotFound) {
+			fmt.Println(i18n.T("logout.not_logged_in"))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("load token: %w", err)
+		}
+
+		if !c.Bool("local") {
+			if err := client.Logout(token); err != nil {
+				logger.Logger.Error().Err(err).Msg("Revoke token")
+				return fmt.Errorf("revoke token: %w (use --local to only remove it here)", err)
+			}
+		}
+
+		if err := config.DeleteToken(); err != nil {
+			return fmt.Errorf("remove token: %w", err)
+		}
+
+		fmt.Println(i18n.T("logout.success"))
+		return nil
+	},
+}
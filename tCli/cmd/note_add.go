@@ -2,21 +2,52 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"halo/client"
 	"halo/config"
+	"halo/i18n"
 	"halo/localstore"
 	"halo/logger"
 	"halo/models"
+	"halo/syncer"
+	"halo/utils"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/urfave/cli/v3"
 )
 
+// note_service takes new notes created at most maxBackdate ago,
+// older ones go through halo import
+const maxBackdate = 30 * 24 * time.Hour
+
 var AddNoteCommand = &cli.Command{
 	UseShortOptionHandling: true,
 	Name:                   "add",
 	Usage:                  "Add note",
+	ArgsUsage:              "<content>",
+	ShellComplete:          completeCategoryFlag,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "at",
+			Usage: `start time: "21:00", "30m ago", "yesterday 21:00", "2025-03-12T21:00" (default: now)`,
+		},
+		&cli.StringFlag{
+			Name:  "end",
+			Usage: `end time, same formats as --at: "in 2h", "now"`,
+		},
+		&cli.StringFlag{
+			Name:    "category",
+			Aliases: []string{"c"},
+			Usage:   "category name or id",
+		},
+		&cli.BoolFlag{
+			Name:    "done",
+			Aliases: []string{"d"},
+			Usage:   "mark the note completed",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		var noteInfo models.NoteStruct
 
@@ -34,26 +65,130 @@ var AddNoteCommand = &cli.Command{
 			noteInfo.Id = noteId
 		}
 
-		token, err := config.LoadToken()
+		now := time.Now()
+
+		createdAt, err := utils.ParseHumanTimeAt(cmd.String("at"), now)
 		if err != nil {
-			logger.Logger.Error().Err(err).Msg("get session token")
-			return fmt.Errorf("get session token: %w", err)
+			return fmt.Errorf("--at: %w", err)
+		}
+		if createdAt == 0 {
+			createdAt = now.Unix()
+		}
+		if createdAt > now.Unix() {
+			return fmt.Errorf("--at is in the future")
+		}
+		if createdAt < now.Add(-maxBackdate).Unix() {
+			return fmt.Errorf("--at is more than %d days ago, use halo import for older notes", int(maxBackdate.Hours()/24))
 		}
 
-		err = client.SendNoteToService(token, noteInfo)
+		endedAt, err := utils.ParseHumanTimeAt(cmd.String("end"), now)
 		if err != nil {
-			logger.Logger.Error().Err(err).Msg("send note to service")
-			return fmt.Errorf("send note to service: %w", err)
+			return fmt.Errorf("--end: %w", err)
+		}
+		if endedAt != 0 && endedAt < createdAt {
+			return fmt.Errorf("--end is before --at")
 		}
 
-		if err := localstore.AddNoteLocally(noteInfo); err != nil {
-			logger.Logger.Error().Err(err).Msg("save note")
-			return fmt.Errorf("save note error: %w", err)
+		// without a token a category can still be found in the local cache
+		token, tokenErr := config.LoadToken()
+
+		if name := cmd.String("category"); name != "" {
+			category, err := resolveCategory(token, name)
+			if err != nil {
+				return fmt.Errorf("--category: %w", err)
+			}
+			noteInfo.Category_id = category.Id
 		}
 
-		// go localstore.SyncNotes(note)
+		noteInfo.Created_at = int(createdAt)
+		noteInfo.Updated_at = int(now.Unix())
+		noteInfo.Ended_at = int(endedAt)
+		noteInfo.Completed = cmd.Bool("done")
 
-		fmt.Println("Note added successfully.")
+		if tokenErr != nil {
+			logger.Logger.Error().Err(tokenErr).Msg("get session token")
+		}
+
+		synced, err := saveNewNote(token, noteInfo)
+		if err != nil {
+			return err
+		}
+
+		if !synced {
+			fmt.Println(i18n.T("note.saved_locally"))
+			return nil
+		}
+
+		fmt.Println(i18n.T("note.added"))
 		return nil
 	},
 }
+
+// saveNewNote stores the note locally first, so it is never lost when the server
+// is unreachable, and then sends it; it reports whether the server has the note
+func saveNewNote(token string, noteInfo models.NoteStruct) (bool, error) {
+	if err := localstore.AddNoteLocally(noteInfo); err != nil {
+		logger.Logger.Error().Err(err).Msg("save note")
+		return false, fmt.Errorf("save note error: %w", err)
+	}
+
+	if err := localstore.QueueChange(noteInfo.Id, localstore.ChangeUpsert, int64(noteInfo.Updated_at)); err != nil {
+		logger.Logger.Error().Err(err).Msg("queue note change")
+		return false, fmt.Errorf("queue note change: %w", err)
+	}
+
+	if token == "" {
+		return false, nil
+	}
+
+	if _, err := client.SendNoteToService(token, noteInfo); err != nil {
+		logger.Logger.Error().Err(err).Msg("send note to service")
+		if !errors.Is(err, client.ErrNoteRejected) {
+			return false, nil
+		}
+
+		// a queued note would be rejected again by every sync
+		if err := localstore.ClearPendingChange(noteInfo.Id, int64(noteInfo.Updated_at)); err != nil {
+			logger.Logger.Error().Err(err).Msg("clear pending change")
+		}
+		if err := localstore.DeleteNoteLocally(noteInfo.Id.String()); err != nil {
+			logger.Logger.Error().Err(err).Msg("delete rejected note")
+		}
+		return false, fmt.Errorf("save note: %w", err)
+	}
+
+	if err := localstore.ClearPendingChange(noteInfo.Id, int64(noteInfo.Updated_at)); err != nil {
+		logger.Logger.Error().Err(err).Msg("clear pending change")
+	}
+
+	syncQuietly()
+	return true, nil
+}
+
+// saveNoteUpdate stores a changed note locally and pushes it with the other pending changes
+func saveNoteUpdate(token string, note models.NoteStruct) (bool, error) {
+	// updated_at has a one second resolution, an update must still win over the previous version
+	note.Updated_at = max(int(time.Now().Unix()), note.Updated_at+1)
+
+	if err := localstore.UpdateNoteLocally(note); err != nil {
+		logger.Logger.Error().Err(err).Msg("update note")
+		return false, fmt.Errorf("update note: %w", err)
+	}
+
+	if err := localstore.QueueChange(note.Id, localstore.ChangeUpsert, int64(note.Updated_at)); err != nil {
+		logger.Logger.Error().Err(err).Msg("queue note change")
+		return false, fmt.Errorf("queue note change: %w", err)
+	}
+
+	if token == "" {
+		return false, nil
+	}
+
+	if _, err := syncer.Push(token); err != nil {
+		logger.Logger.Error().Err(err).Msg("push note changes")
+		return false, nil
+	}
+
+	stored, found, err := localstore.GetNoteLocally(note.Id)
+	return err == nil && found && stored.Synced, nil
+}
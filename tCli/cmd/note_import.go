@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+var ImportCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "import",
+	Usage:                  "Import notes from a csv or json file",
+	ArgsUsage:              "<file>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "file format: csv or json (default: from file extension)",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() < 1 {
+			logger.Logger.Error().Msg("no file")
+			return fmt.Errorf("no file")
+		}
+
+		path := cmd.Args().Get(0)
+
+		format := strings.ToLower(cmd.String("format"))
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+
+		var contentType string
+		switch format {
+		case "csv":
+			contentType = "text/csv"
+		case "json":
+			contentType = "application/json"
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("read import file")
+			return fmt.Errorf("read import file: %w", err)
+		}
+
+		token, err := config.LoadToken()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get session token")
+			return fmt.Errorf("get session token: %w", err)
+		}
+
+		report, err := client.ImportNotes(token, data, contentType)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("import notes")
+			return fmt.Errorf("import notes: %w", err)
+		}
+
+		for _, row := range report.Rows {
+			switch {
+			case row.Note != nil:
+				if err := localstore.AddNoteLocally(*row.Note); err != nil {
+					logger.Logger.Error().Err(err).Msg("save imported note")
+				}
+			case row.Error != "":
+				fmt.Println(i18n.T("import.row_error", row.Row, row.Error))
+			}
+		}
+
+		fmt.Println(strings.Join([]string{
+			i18n.N("import.imported", report.Imported),
+			i18n.N("import.duplicates", report.Duplicates),
+			i18n.N("import.rejected", report.Rejected),
+		}, ", "))
+		return nil
+	},
+}
@@ -12,6 +12,7 @@ var NoteListCommand = &cli.Command{
 	UseShortOptionHandling: true,
 	Name:                   "list",
 	Usage:                  "note list",
+	Before:                 syncBefore,
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		err := ui.StartNoteSelector()
 		if err != nil {
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"halo/output"
+	"halo/utils"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v3"
+)
+
+var noteFields = []string{
+	"id",
+	"category",
+	"category_id",
+	"content",
+	"created_at",
+	"ended_at",
+	"duration",
+	"completed",
+	"synced",
+	"updated_at",
+}
+
+var tableNoteFields = []string{"created_at", "category", "content", "completed"}
+
+func parseFields(value string, known []string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		found := false
+		for _, k := range known {
+			if k == field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New(i18n.T("notes.unknown_field", field, strings.Join(known, ", ")))
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New(i18n.T("notes.no_fields"))
+	}
+	return fields, nil
+}
+
+// noteRecord formats times for people in tables and as RFC 3339 elsewhere
+func noteRecord(note models.NoteStruct, categories map[uuid.UUID]string, format string) output.Record {
+	formatTime := func(unix int) any {
+		if unix == 0 {
+			return nil
+		}
+		t := time.Unix(int64(unix), 0)
+		if format == output.FormatTable {
+			return t.Format("2006-01-02 15:04")
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	record := output.Record{
+		"id":         note.Id.String(),
+		"content":    note.Content,
+		"created_at": formatTime(note.Created_at),
+		"ended_at":   formatTime(note.Ended_at),
+		"updated_at": formatTime(note.Updated_at),
+		"completed":  note.Completed,
+		"synced":     note.Synced,
+	}
+
+	if note.Category_id != uuid.Nil {
+		record["category_id"] = note.Category_id.String()
+		if name, ok := categories[note.Category_id]; ok {
+			record["category"] = name
+		}
+	}
+
+	if note.Ended_at != 0 {
+		duration := time.Duration(note.Ended_at-note.Created_at) * time.Second
+		if format == output.FormatTable {
+			record["duration"] = duration.String()
+		} else {
+			record["duration"] = int64(duration.Seconds())
+		}
+	}
+
+	return record
+}
+
+// noteQueryFlags are the output and filter flags shared by ls and search
+func noteQueryFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   output.FormatTable,
+			Usage:   "output format: " + strings.Join(output.Formats, ", "),
+		},
+		&cli.StringFlag{
+			Name:    "fields",
+			Aliases: []string{"f"},
+			Usage: "comma separated fields: " + strings.Join(noteFields, ", ") +
+				" (default: all, a short set for table)",
+		},
+		&cli.StringFlag{
+			Name:    "category",
+			Aliases: []string{"c"},
+			Usage:   "only notes of the category (name or id)",
+		},
+		&cli.StringFlag{
+			Name:  "completed",
+			Usage: "only completed (true) or pending (false) notes",
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: `created at or after, e.g. "yesterday", "2025-03-01", "7d ago"`,
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: `created before, same formats as --from`,
+		},
+		&cli.IntFlag{
+			Name:    "limit",
+			Aliases: []string{"n"},
+			Usage:   "at most this many notes",
+		},
+	}
+}
+
+var NoteLsCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "ls",
+	Usage:                  "Print notes without the interactive list",
+	ShellComplete:          completeCategoryFlag,
+	Flags: append(noteQueryFlags(), &cli.BoolFlag{
+		Name:  "sync",
+		Usage: "sync with the server before printing",
+	}),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		format, fields, err := noteOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("sync") {
+			syncQuietly()
+		}
+
+		filter, err := noteFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		notes, err := localstore.QueryNotes(filter)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("query notes")
+			return fmt.Errorf("query notes: %w", err)
+		}
+
+		return writeNotes(format, fields, notes)
+	},
+}
+
+// noteOutput reads the --output and --fields flags of noteQueryFlags
+func noteOutput(cmd *cli.Command) (string, []string, error) {
+	format := strings.ToLower(cmd.String("output"))
+	if err := output.CheckFormat(format); err != nil {
+		return "", nil, err
+	}
+
+	fields := noteFields
+	if format == output.FormatTable {
+		fields = tableNoteFields
+	}
+	if cmd.IsSet("fields") {
+		var err error
+		if fields, err = parseFields(cmd.String("fields"), noteFields); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return format, fields, nil
+}
+
+// noteFilterFromFlags reads the filter flags of noteQueryFlags
+func noteFilterFromFlags(cmd *cli.Command) (models.NoteFilter, error) {
+	var filter models.NoteFilter
+	now := time.Now()
+
+	if name := cmd.String("category"); name != "" {
+		token, _ := config.LoadToken()
+		category, err := resolveCategory(token, name)
+		if err != nil {
+			return filter, fmt.Errorf("--category: %w", err)
+		}
+		filter.Category_id = &category.Id
+	}
+
+	if value := cmd.String("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errors.New(i18n.T("notes.completed_invalid"))
+		}
+		filter.Completed = &completed
+	}
+
+	var err error
+	if filter.From, err = utils.ParseHumanTimeAt(cmd.String("from"), now); err != nil {
+		return filter, fmt.Errorf("--from: %w", err)
+	}
+	if filter.To, err = utils.ParseHumanTimeAt(cmd.String("to"), now); err != nil {
+		return filter, fmt.Errorf("--to: %w", err)
+	}
+	filter.Limit = int(cmd.Int("limit"))
+
+	return filter, nil
+}
+
+func writeNotes(format string, fields []string, notes []models.NoteStruct) error {
+	categories := make(map[uuid.UUID]string)
+	if cached, err := localstore.GetCategoriesLocally(); err == nil {
+		for _, category := range cached {
+			categories[category.Id] = category.Name
+		}
+	}
+
+	records := make([]output.Record, 0, len(notes))
+	for _, note := range notes {
+		records = append(records, noteRecord(note, categories, format))
+	}
+
+	return output.Write(os.Stdout, format, fields, records)
+}
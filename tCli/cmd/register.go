@@ -13,6 +13,7 @@ import (
 
 	client "halo/client"
 	config "halo/config"
+	i18n "halo/i18n"
 )
 
 var RegisterCommand = &cli.Command{
@@ -21,21 +22,21 @@ var RegisterCommand = &cli.Command{
 	Action: func(ctx context.Context, c *cli.Command) error {
 		reader := bufio.NewReader(os.Stdin)
 
-		fmt.Print("Enter login: ")
+		fmt.Print(i18n.T("login.prompt_login"))
 		login, _ := reader.ReadString('\n')
 
 		var passwordBytes []byte
 		var err error
 
 		for {
-			fmt.Print("Enter password: ")
+			fmt.Print(i18n.T("login.prompt_password"))
 			passwordBytes, err = term.ReadPassword(int(os.Stdin.Fd()))
 			fmt.Println()
 			if err != nil {
 				return fmt.Errorf("failed to read password: %w", err)
 			}
 
-			fmt.Print("Confirm password: ")
+			fmt.Print(i18n.T("register.prompt_confirm"))
 			confirmPasswordBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
 			fmt.Println()
 			if err != nil {
@@ -43,7 +44,7 @@ var RegisterCommand = &cli.Command{
 			}
 
 			if passwordBytes == nil {
-				fmt.Println("Password is empty")
+				fmt.Println(i18n.T("register.password_empty"))
 				continue
 			}
 
@@ -51,13 +52,13 @@ var RegisterCommand = &cli.Command{
 				break
 			}
 
-			fmt.Println("Passwords don't match")
+			fmt.Println(i18n.T("register.password_mismatch"))
 		}
 
-		fmt.Print("Enter username (optional): ")
+		fmt.Print(i18n.T("register.prompt_username"))
 		username, _ := reader.ReadString('\n')
 
-		fmt.Print("Enter email (optional): ")
+		fmt.Print(i18n.T("register.prompt_email"))
 		email, _ := reader.ReadString('\n')
 
 		login = strings.TrimSpace(login)
@@ -75,7 +76,7 @@ var RegisterCommand = &cli.Command{
 			return fmt.Errorf("failed to save token: %w", err)
 		}
 
-		fmt.Println("Registered successfully.")
+		fmt.Println(i18n.T("register.success"))
 		return nil
 	},
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v3"
+)
+
+// the server answers in pages of this size
+const searchPageSize = 10
+
+// matchesFilter applies the filter flags the server does not know to its results
+func matchesFilter(note models.NoteStruct, filter models.NoteFilter) bool {
+	if filter.Completed != nil && note.Completed != *filter.Completed {
+		return false
+	}
+	if filter.From != 0 && int64(note.Created_at) < filter.From {
+		return false
+	}
+	if filter.To != 0 && int64(note.Created_at) >= filter.To {
+		return false
+	}
+	return true
+}
+
+func searchRemote(text string, filter models.NoteFilter) ([]models.NoteStruct, error) {
+	token, err := config.LoadToken()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get session token")
+		return nil, fmt.Errorf("get session token: %w", err)
+	}
+
+	categoryId := uuid.Nil
+	if filter.Category_id != nil {
+		categoryId = *filter.Category_id
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = searchPageSize
+	}
+
+	notes := make([]models.NoteStruct, 0, limit)
+	for page := 1; len(notes) < limit; page++ {
+		found, err := client.SearchNotes(token, text, categoryId, page)
+		if err != nil {
+			return nil, fmt.Errorf("search notes: %w", err)
+		}
+		for _, note := range found {
+			if matchesFilter(note, filter) && len(notes) < limit {
+				note.Synced = true
+				notes = append(notes, note)
+			}
+		}
+		if len(found) < searchPageSize {
+			break
+		}
+	}
+
+	return notes, nil
+}
+
+var SearchCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "search",
+	Usage:                  "Search notes by content, every word matches as a prefix",
+	ArgsUsage:              "<text>",
+	ShellComplete:          completeCategoryFlag,
+	Flags: append(noteQueryFlags(), &cli.BoolFlag{
+		Name:  "remote",
+		Usage: "search on the server instead of the local notes",
+	}),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		text := strings.Join(cmd.Args().Slice(), " ")
+		if strings.TrimSpace(text) == "" {
+			return errors.New(i18n.T("search.no_text"))
+		}
+
+		format, fields, err := noteOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		filter, err := noteFilterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		var notes []models.NoteStruct
+		if cmd.Bool("remote") {
+			notes, err = searchRemote(text, filter)
+		} else {
+			notes, err = localstore.SearchNotes(text, filter)
+		}
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("search notes")
+			return fmt.Errorf("search notes: %w", err)
+		}
+
+		return writeNotes(format, fields, notes)
+	},
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/syncer"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+var SyncCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "sync",
+	Usage:                  "Sync local notes with the server",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		token, err := config.LoadToken()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get session token")
+			return fmt.Errorf("get session token: %w", err)
+		}
+
+		result, err := syncer.Run(token)
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("sync notes")
+			return fmt.Errorf("sync notes: %w", err)
+		}
+
+		fmt.Println(strings.Join([]string{
+			i18n.N("sync.pushed", result.Pushed),
+			i18n.N("sync.pulled", result.Pulled),
+			i18n.N("sync.deleted", result.Deleted),
+			i18n.N("sync.conflicts", result.Conflicts),
+		}, ", "))
+		if result.Rejected > 0 {
+			fmt.Println(i18n.N("sync.rejected", result.Rejected))
+		}
+		return nil
+	},
+}
+
+// syncQuietly runs a sync in the background of another command,
+// being offline is expected so failures are only logged
+func syncQuietly() {
+	token, err := config.LoadToken()
+	if err != nil {
+		logger.Logger.Debug().Err(err).Msg("sync skipped: no session token")
+		return
+	}
+
+	if _, err := syncer.Run(token); err != nil {
+		logger.Logger.Debug().Err(err).Msg("sync skipped")
+		return
+	}
+
+	if pending, err := localstore.GetNumberOfPendingChanges(); err == nil && pending > 0 {
+		logger.Logger.Debug().Int("pending", pending).Msg("changes left unsynced")
+	}
+}
+
+func syncBefore(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	syncQuietly()
+	return ctx, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
+	"halo/logger"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+var TelegramCommand = &cli.Command{
+	Name:  "telegram",
+	Usage: "Telegram bot integration",
+	Commands: []*cli.Command{
+		{
+			Name:      "link",
+			Usage:     "Link a telegram chat with the code the bot sent after /link",
+			ArgsUsage: "<code>",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				code := strings.ToUpper(strings.TrimSpace(cmd.Args().First()))
+				if code == "" {
+					return fmt.Errorf("no code, send /link to the bot to get one")
+				}
+
+				token, err := config.LoadToken()
+				if err != nil {
+					logger.Logger.Error().Err(err).Msg("get session token")
+					return fmt.Errorf("get session token: %w", err)
+				}
+
+				link, err := client.LinkTelegram(token, code)
+				if err != nil {
+					return fmt.Errorf("link telegram: %w", err)
+				}
+
+				fmt.Println(i18n.T("telegram.linked", link.Chat_id))
+				return nil
+			},
+		},
+	},
+}
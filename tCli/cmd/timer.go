@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"halo/config"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"halo/output"
+	"halo/utils"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v3"
+)
+
+// formatElapsed is short enough for a shell prompt: 45s, 12m, 1h05m, 2d03h
+func formatElapsed(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// stopTimer closes the running activity at the given time and reports whether the server has it
+func stopTimer(token string, note models.NoteStruct, endedAt int64) (bool, error) {
+	if endedAt < int64(note.Created_at) {
+		return false, fmt.Errorf("end time is before the start of %q", note.Content)
+	}
+
+	note.Ended_at = int(endedAt)
+	note.Completed = true
+
+	synced, err := saveNoteUpdate(token, note)
+	if err != nil {
+		return false, err
+	}
+
+	if err := localstore.ClearActiveTimer(); err != nil {
+		logger.Logger.Error().Err(err).Msg("clear active timer")
+		return synced, fmt.Errorf("clear active timer: %w", err)
+	}
+	return synced, nil
+}
+
+// loadTokenQuietly returns an empty token when the user is not logged in,
+// timers work offline and are synced later
+func loadTokenQuietly() string {
+	token, err := config.LoadToken()
+	if err != nil {
+		logger.Logger.Debug().Err(err).Msg("no session token")
+		return ""
+	}
+	return token
+}
+
+var StartCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "start",
+	Usage:                  "Start an activity, it runs until halo stop",
+	ArgsUsage:              "<content>",
+	ShellComplete:          completeCategoryFlag,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "category",
+			Aliases: []string{"c"},
+			Usage:   "category name or id",
+		},
+		&cli.StringFlag{
+			Name:  "at",
+			Usage: `start time: "10m ago", "21:00" (default: now)`,
+		},
+		&cli.BoolFlag{
+			Name:    "switch",
+			Aliases: []string{"s"},
+			Usage:   "stop the running activity first",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() < 1 {
+			logger.Logger.Error().Msg("no content")
+			return fmt.Errorf("no content")
+		}
+
+		now := time.Now()
+
+		startedAt, err := utils.ParseHumanTimeAt(cmd.String("at"), now)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		if startedAt == 0 {
+			startedAt = now.Unix()
+		}
+
+		token := loadTokenQuietly()
+
+		running, found, err := localstore.GetActiveTimer()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get active timer")
+			return fmt.Errorf("get active timer: %w", err)
+		}
+		if found {
+			if !cmd.Bool("switch") {
+				return fmt.Errorf("%q is already running, stop it first or use --switch", running.Content)
+			}
+			if _, err := stopTimer(token, running, startedAt); err != nil {
+				return err
+			}
+			fmt.Println(i18n.T("timer.stopped", running.Content, i18n.Duration(time.Duration(startedAt-int64(running.Created_at))*time.Second)))
+		}
+
+		noteInfo := models.NoteStruct{
+			Content:    cmd.Args().Get(0),
+			Created_at: int(startedAt),
+			Updated_at: int(now.Unix()),
+		}
+
+		if noteId, err := uuid.NewV7(); err != nil {
+			logger.Logger.Error().Err(err).Msg("new note id generation")
+			return fmt.Errorf("new note id generation: %w", err)
+		} else {
+			noteInfo.Id = noteId
+		}
+
+		if name := cmd.String("category"); name != "" {
+			category, err := resolveCategory(token, name)
+			if err != nil {
+				return fmt.Errorf("--category: %w", err)
+			}
+			noteInfo.Category_id = category.Id
+		}
+
+		if _, err := saveNewNote(token, noteInfo); err != nil {
+			return err
+		}
+
+		if err := localstore.SetActiveTimer(noteInfo.Id); err != nil {
+			logger.Logger.Error().Err(err).Msg("set active timer")
+			return fmt.Errorf("set active timer: %w", err)
+		}
+
+		fmt.Println(i18n.T("timer.started", noteInfo.Content))
+		return nil
+	},
+}
+
+var StopCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "stop",
+	Usage:                  "Stop the running activity",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "at",
+			Usage: `end time: "5m ago", "21:30" (default: now)`,
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		now := time.Now()
+
+		endedAt, err := utils.ParseHumanTimeAt(cmd.String("at"), now)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		if endedAt == 0 {
+			endedAt = now.Unix()
+		}
+
+		running, found, err := localstore.GetActiveTimer()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get active timer")
+			return fmt.Errorf("get active timer: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("no activity is running")
+		}
+
+		synced, err := stopTimer(loadTokenQuietly(), running, endedAt)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(i18n.T("timer.stopped", running.Content, i18n.Duration(time.Duration(endedAt-int64(running.Created_at))*time.Second)))
+		if !synced {
+			fmt.Println(i18n.T("timer.saved_locally"))
+		}
+		return nil
+	},
+}
+
+// StatusCommand never touches the network, so it is cheap enough for a shell prompt
+var StatusCommand = &cli.Command{
+	UseShortOptionHandling: true,
+	Name:                   "status",
+	Usage:                  "Show the running activity",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "short",
+			Usage: `one line for a shell prompt, e.g. "reading 1h05m", nothing when idle`,
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   output.FormatTable,
+			Usage:   "output format: " + strings.Join(output.Formats, ", "),
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		format := cmd.String("output")
+		if err := output.CheckFormat(format); err != nil {
+			return err
+		}
+
+		running, found, err := localstore.GetActiveTimer()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get active timer")
+			return fmt.Errorf("get active timer: %w", err)
+		}
+
+		elapsed := time.Since(time.Unix(int64(running.Created_at), 0)).Truncate(time.Second)
+
+		if cmd.Bool("short") {
+			if found {
+				fmt.Printf("%s %s\n", running.Content, formatElapsed(elapsed))
+			}
+			return nil
+		}
+
+		if format == output.FormatTable {
+			if !found {
+				fmt.Println(i18n.T("timer.idle"))
+				return nil
+			}
+			fmt.Println(i18n.T(
+				"timer.running",
+				running.Content,
+				i18n.Duration(elapsed),
+				time.Unix(int64(running.Created_at), 0).Format("2006-01-02 15:04"),
+			))
+			return nil
+		}
+
+		fields := []string{"running", "id", "content", "category_id", "started_at", "elapsed"}
+		record := output.Record{"running": found}
+		if found {
+			record["id"] = running.Id.String()
+			record["content"] = running.Content
+			record["started_at"] = time.Unix(int64(running.Created_at), 0).Format(time.RFC3339)
+			record["elapsed"] = int64(elapsed.Seconds())
+			if running.Category_id != uuid.Nil {
+				record["category_id"] = running.Category_id.String()
+			}
+		}
+		return output.Write(os.Stdout, format, fields, []output.Record{record})
+	},
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"halo/i18n"
+	"halo/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultProfile = "default"
+	DefaultServer  = "http://localhost:8080"
+	DefaultTimeout = 10
+)
+
+type Profile struct {
+	Server                   string `json:"server"`
+	Tls_ca_file              string `json:"tls_ca_file,omitempty"`
+	Tls_insecure_skip_verify bool   `json:"tls_insecure_skip_verify,omitempty"`
+	Token_path               string `json:"token_path,omitempty"`
+	Secret_store             string `json:"secret_store,omitempty"`
+	Db_path                  string `json:"db_path,omitempty"`
+	Timeout_seconds          int    `json:"timeout_seconds,omitempty"`
+	Locale                   string `json:"locale,omitempty"`
+}
+
+type Config struct {
+	Current_profile string             `json:"current_profile"`
+	Profiles        map[string]Profile `json:"profiles"`
+}
+
+// profile keys accepted by `halo config set`
+var ProfileKeys = []string{
+	"server",
+	"tls-ca-file",
+	"tls-insecure-skip-verify",
+	"token-path",
+	"secret-store",
+	"db-path",
+	"timeout",
+	"locale",
+}
+
+var (
+	activeName    = DefaultProfile
+	activeProfile = withDefaults(DefaultProfile, Profile{})
+)
+
+func HaloDir() (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("User home dir")
+		return "", err
+	}
+	return filepath.Join(dir, "halo"), nil
+}
+
+func ConfigPath() (string, error) {
+	dir, err := HaloDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Current_profile: DefaultProfile,
+		Profiles: map[string]Profile{
+			DefaultProfile: {Server: DefaultServer},
+		},
+	}
+}
+
+// LoadConfig returns the default config when the file does not exist yet
+func LoadConfig() (Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return Config{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultConfig(), nil
+	}
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("Read config file")
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		logger.Logger.Error().Err(err).Msg("Parse config file")
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]Profile)
+	}
+	if cfg.Current_profile == "" {
+		cfg.Current_profile = DefaultProfile
+	}
+
+	return cfg, nil
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logger.Logger.Error().Err(err).Msg("Mkdir config path")
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Init selects the profile used by the rest of the run: the given name, or the
+// current profile of the config file; HALO_SERVER overrides its server url
+func Init(profileName string) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	if profileName == "" {
+		profileName = cfg.Current_profile
+	}
+
+	profile, ok := cfg.Profiles[profileName]
+	if !ok && profileName != DefaultProfile {
+		return fmt.Errorf("profile %q not found", profileName)
+	}
+
+	if server := os.Getenv("HALO_SERVER"); server != "" {
+		profile.Server = server
+	}
+
+	activeName = profileName
+	activeProfile = withDefaults(profileName, profile)
+
+	return nil
+}
+
+func withDefaults(name string, profile Profile) Profile {
+	if profile.Server == "" {
+		profile.Server = DefaultServer
+	}
+	profile.Server = strings.TrimRight(profile.Server, "/")
+
+	if profile.Timeout_seconds <= 0 {
+		profile.Timeout_seconds = DefaultTimeout
+	}
+
+	dir, err := HaloDir()
+	if err != nil {
+		return profile
+	}
+
+	if profile.Secret_store == "" {
+		profile.Secret_store = SecretStoreAuto
+	}
+
+	// every profile but the default one keeps its own token and notes
+	if profile.Token_path == "" {
+		profile.Token_path = filepath.Join(dir, "token.enc")
+		if name != DefaultProfile {
+			profile.Token_path = filepath.Join(dir, "profiles", name, "token.enc")
+		}
+	}
+	if profile.Db_path == "" {
+		profile.Db_path = filepath.Join(dir, "db.sqlite")
+		if name != DefaultProfile {
+			profile.Db_path = filepath.Join(dir, "profiles", name, "db.sqlite")
+		}
+	}
+
+	return profile
+}
+
+func ActiveProfile() (string, Profile) {
+	return activeName, activeProfile
+}
+
+func ProfileNames(cfg Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func localeNames() []string {
+	names := make([]string, 0, len(i18n.Supported))
+	for _, locale := range i18n.Supported {
+		names = append(names, string(locale))
+	}
+	return names
+}
+
+// SetProfileLocale stores the locale in the active profile, e.g. the one of the server profile after login
+func SetProfileLocale(locale i18n.Locale) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := cfg.Profiles[activeName]
+	profile.Locale = string(locale)
+	cfg.Profiles[activeName] = profile
+	activeProfile.Locale = string(locale)
+
+	return SaveConfig(cfg)
+}
+
+// SetProfileValue changes one key of the profile, see ProfileKeys
+func SetProfileValue(profile *Profile, key string, value string) error {
+	switch key {
+	case "server":
+		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			return fmt.Errorf("server must start with http:// or https://")
+		}
+		profile.Server = strings.TrimRight(value, "/")
+	case "tls-ca-file":
+		profile.Tls_ca_file = value
+	case "tls-insecure-skip-verify":
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", value)
+		}
+		profile.Tls_insecure_skip_verify = skip
+	case "token-path":
+		profile.Token_path = value
+	case "secret-store":
+		switch value {
+		case SecretStoreAuto, SecretStoreKeyring, SecretStoreFile:
+			profile.Secret_store = value
+		default:
+			return fmt.Errorf("secret store must be one of: auto, keyring, file")
+		}
+	case "db-path":
+		profile.Db_path = value
+	case "timeout":
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("invalid timeout %q", value)
+		}
+		profile.Timeout_seconds = seconds
+	case "locale":
+		if value == "" {
+			profile.Locale = ""
+			break
+		}
+		locale, ok := i18n.Parse(value)
+		if !ok {
+			return fmt.Errorf("locale must be one of: %s", strings.Join(localeNames(), ", "))
+		}
+		profile.Locale = string(locale)
+	default:
+		return fmt.Errorf("unknown key %q, expected one of: %s", key, strings.Join(ProfileKeys, ", "))
+	}
+	return nil
+}
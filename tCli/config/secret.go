@@ -0,0 +1,280 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"halo/logger"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	SecretStoreAuto    = "auto"
+	SecretStoreKeyring = "keyring"
+	SecretStoreFile    = "file"
+
+	keyringService = "halo"
+
+	kdfPassphrase = "passphrase"
+	kdfMachine    = "machine"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore keeps the session token of one profile
+type SecretStore interface {
+	Name() string
+	Load() (string, error)
+	Save(secret string) error
+	Delete() error
+}
+
+// NewSecretStore picks the store configured for the profile,
+// auto prefers the system keyring and falls back to the encrypted file
+func NewSecretStore(profileName string, profile Profile) SecretStore {
+	file := &fileStore{path: profile.Token_path}
+	ring := &keyringStore{user: profileName}
+
+	switch profile.Secret_store {
+	case SecretStoreKeyring:
+		return ring
+	case SecretStoreFile:
+		return file
+	}
+
+	if ring.available() {
+		return &autoStore{stores: []SecretStore{ring, file}}
+	}
+	return file
+}
+
+// keyringStore uses the Secret Service over D-Bus (or the platform keychain)
+type keyringStore struct {
+	user string
+}
+
+func (s *keyringStore) Name() string {
+	return SecretStoreKeyring
+}
+
+func (s *keyringStore) available() bool {
+	_, err := keyring.Get(keyringService, "probe")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (s *keyringStore) Load() (string, error) {
+	secret, err := keyring.Get(keyringService, s.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrSecretNotFound
+	}
+	return secret, err
+}
+
+func (s *keyringStore) Save(secret string) error {
+	return keyring.Set(keyringService, s.user, secret)
+}
+
+func (s *keyringStore) Delete() error {
+	err := keyring.Delete(keyringService, s.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// autoStore writes to the first store and reads from any of them,
+// so a token saved while the keyring was unavailable is still found
+type autoStore struct {
+	stores []SecretStore
+}
+
+func (s *autoStore) Name() string {
+	return s.stores[0].Name()
+}
+
+func (s *autoStore) Load() (string, error) {
+	for _, store := range s.stores {
+		secret, err := store.Load()
+		if err == nil {
+			return secret, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			logger.Logger.Error().Err(err).Str("store", store.Name()).Msg("Load secret")
+		}
+	}
+	return "", ErrSecretNotFound
+}
+
+func (s *autoStore) Save(secret string) error {
+	if err := s.stores[0].Save(secret); err != nil {
+		return err
+	}
+	for _, store := range s.stores[1:] {
+		if err := store.Delete(); err != nil {
+			logger.Logger.Error().Err(err).Str("store", store.Name()).Msg("Delete secret")
+		}
+	}
+	return nil
+}
+
+func (s *autoStore) Delete() error {
+	var errs []error
+	for _, store := range s.stores {
+		errs = append(errs, store.Delete())
+	}
+	return errors.Join(errs...)
+}
+
+// fileStore keeps the secret encrypted with AES-GCM, the key is derived with scrypt
+// from HALO_PASSPHRASE when it is set, otherwise from the machine id and user
+type fileStore struct {
+	path string
+}
+
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Kdf     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (s *fileStore) Name() string {
+	return SecretStoreFile
+}
+
+func machineSecret() string {
+	var id string
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			id = strings.TrimSpace(string(data))
+			break
+		}
+	}
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+
+	home, _ := os.UserHomeDir()
+	return strings.Join([]string{"halo", id, strconv.Itoa(os.Getuid()), home}, ":")
+}
+
+func passphrase(prompt bool) (string, error) {
+	if value := os.Getenv("HALO_PASSPHRASE"); value != "" {
+		return value, nil
+	}
+	if !prompt || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("token file is protected by a passphrase, set HALO_PASSPHRASE")
+	}
+
+	fmt.Fprint(os.Stderr, "Token passphrase: ")
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+func fileCipher(secret string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(secret), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileStore) Load() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return "", fmt.Errorf("parse token file: %w", err)
+	}
+
+	secret := machineSecret()
+	if file.Kdf == kdfPassphrase {
+		if secret, err = passphrase(true); err != nil {
+			return "", err
+		}
+	}
+
+	aead, err := fileCipher(secret, file.Salt)
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt token file: wrong passphrase or another machine")
+	}
+
+	return string(plain), nil
+}
+
+func (s *fileStore) Save(value string) error {
+	file := encryptedFile{Version: 1, Kdf: kdfMachine}
+
+	secret := machineSecret()
+	if pass, err := passphrase(false); err == nil {
+		file.Kdf = kdfPassphrase
+		secret = pass
+	}
+
+	file.Salt = make([]byte, 16)
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	aead, err := fileCipher(secret, file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, []byte(value), nil)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		logger.Logger.Error().Err(err).Msg("Mkdir token path")
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func (s *fileStore) Delete() error {
+	err := os.Remove(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
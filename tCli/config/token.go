@@ -1,47 +1,91 @@
 package config
 
 import (
+	"errors"
 	"halo/logger"
 	"os"
 	"path/filepath"
 )
 
-func tokenPath() (string, error) {
-	dir, err := os.UserHomeDir()
+// legacyTokenPath is where older versions kept the token in plain text
+func legacyTokenPath() (string, error) {
+	dir, err := HaloDir()
 	if err != nil {
-		logger.Logger.Error().Err(err).Msg("User home dir")
 		return "", err
 	}
-	return filepath.Join(dir, "halo", "token"), nil
+	if activeName != DefaultProfile {
+		return filepath.Join(dir, "profiles", activeName, "token"), nil
+	}
+	return filepath.Join(dir, "token"), nil
 }
 
-func SaveToken(token string) error {
-	path, err := tokenPath()
+func removeLegacyToken() {
+	path, err := legacyTokenPath()
 	if err != nil {
-		logger.Logger.Error().Err(err).Msg("Token path")
-		return err
+		return
 	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Logger.Error().Err(err).Msg("Remove plain token file")
+	}
+}
 
-	err = os.MkdirAll(filepath.Dir(path), 0700)
-	if err != nil {
-		logger.Logger.Error().Err(err).Msg("Mkdir token path")
+func secretStore() SecretStore {
+	return NewSecretStore(activeName, activeProfile)
+}
+
+// SecretStoreName reports where tokens of the active profile are kept
+func SecretStoreName() string {
+	return secretStore().Name()
+}
+
+func SaveToken(token string) error {
+	if err := secretStore().Save(token); err != nil {
+		logger.Logger.Error().Err(err).Msg("Save token")
 		return err
 	}
 
-	return os.WriteFile(path, []byte(token), 0600)
+	removeLegacyToken()
+	return nil
 }
 
+// LoadToken moves a plain text token of older versions into the secret store
 func LoadToken() (string, error) {
-	path, err := tokenPath()
+	store := secretStore()
+
+	token, err := store.Load()
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, ErrSecretNotFound) {
+		logger.Logger.Error().Err(err).Msg("Load token")
+		return "", err
+	}
+
+	path, err := legacyTokenPath()
 	if err != nil {
-		logger.Logger.Error().Err(err).Msg("Token path")
 		return "", err
 	}
 
 	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
 	if err != nil {
 		logger.Logger.Error().Err(err).Msg("Read token file")
 		return "", err
 	}
-	return string(data), nil
+
+	token = string(data)
+	if err := store.Save(token); err != nil {
+		logger.Logger.Error().Err(err).Msg("Migrate plain token")
+		return token, nil
+	}
+	removeLegacyToken()
+
+	return token, nil
+}
+
+func DeleteToken() error {
+	removeLegacyToken()
+	return secretStore().Delete()
 }
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"testing"
+
+	engine "i18n"
+)
+
+// the engine is tested in the shared i18n module, the tests here cover the
+// catalogue of the command line
+func TestCatalogueIsComplete(t *testing.T) {
+	for _, err := range engine.Catalogue(messages).Check() {
+		t.Error(err)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	ru := NewPrinter(Ru)
+	en := NewPrinter(En)
+
+	if got := ru.N("import.imported", 3); got != "импортировано 3 заметки" {
+		t.Errorf("ru import 3 = %q", got)
+	}
+	if got := en.N("import.imported", 1); got != "1 note imported" {
+		t.Errorf("en import 1 = %q", got)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "ru_RU.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := FromEnv(); got != Ru {
+		t.Errorf("FromEnv = %s, want LC_MESSAGES before LANG", got)
+	}
+
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LC_MESSAGES", "")
+	if got := FromEnv(); got != En {
+		t.Errorf("FromEnv = %s, want LANG after an unsupported LC_ALL", got)
+	}
+}
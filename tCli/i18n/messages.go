@@ -0,0 +1,412 @@
+package i18n
+
+// messages of the command line, plural messages hold their forms separated by "|"
+var messages = map[string]map[Locale]string{
+	"duration.less_than_minute": {
+		En: "less than a minute",
+		Ru: "меньше минуты",
+	},
+	"duration.minutes": {
+		En: "%d minute|%d minutes",
+		Ru: "%d минуту|%d минуты|%d минут",
+	},
+	"duration.hours": {
+		En: "%d hour|%d hours",
+		Ru: "%d час|%d часа|%d часов",
+	},
+	"duration.days": {
+		En: "%d day|%d days",
+		Ru: "%d день|%d дня|%d дней",
+	},
+
+	"category.added": {
+		En: "Category added successfully.",
+		Ru: "Категория добавлена.",
+	},
+	"category.renamed": {
+		En: "Category renamed successfully.",
+		Ru: "Категория переименована.",
+	},
+	"category.deleted": {
+		En: "Category deleted successfully.",
+		Ru: "Категория удалена.",
+	},
+	"category.cached": {
+		En: "Server is unreachable, showing cached categories.",
+		Ru: "Сервер недоступен, показаны сохранённые категории.",
+	},
+	"category.none": {
+		En: "No categories.",
+		Ru: "Категорий нет.",
+	},
+
+	"config.profile_added": {
+		En: "Profile added successfully.",
+		Ru: "Профиль добавлен.",
+	},
+	"config.using_profile": {
+		En: "Using profile %s.",
+		Ru: "Используется профиль %s.",
+	},
+	"config.updated": {
+		En: "Config updated successfully.",
+		Ru: "Настройки сохранены.",
+	},
+	"config.locale_not_synced": {
+		En: "The language is saved locally, the server profile was not updated.",
+		Ru: "Язык сохранён локально, профиль на сервере не обновлён.",
+	},
+
+	"db.path": {
+		En: "Database: %s",
+		Ru: "База данных: %s",
+	},
+	"db.version": {
+		En: "Version: %d (latest %d)",
+		Ru: "Версия: %d (последняя %d)",
+	},
+	"db.applied": {
+		En: "Applied %04d_%s",
+		Ru: "Применена %04d_%s",
+	},
+	"db.backup": {
+		En: "Backup: %s",
+		Ru: "Резервная копия: %s",
+	},
+	"db.newer": {
+		En: "The database is newer than this halo, please upgrade halo.",
+		Ru: "База данных новее этой версии halo, обновите halo.",
+	},
+	"db.up_to_date": {
+		En: "Local database is up to date.",
+		Ru: "Локальная база данных в актуальном состоянии.",
+	},
+
+	"login.prompt_login": {
+		En: "Enter login: ",
+		Ru: "Логин: ",
+	},
+	"login.prompt_password": {
+		En: "Enter password: ",
+		Ru: "Пароль: ",
+	},
+	"login.success": {
+		En: "Logged in successfully, token is kept in the %s store.",
+		Ru: "Вход выполнен, токен хранится в хранилище %s.",
+	},
+	"logout.not_logged_in": {
+		En: "Not logged in.",
+		Ru: "Вход не выполнен.",
+	},
+	"logout.success": {
+		En: "Logged out successfully.",
+		Ru: "Выход выполнен.",
+	},
+
+	"register.prompt_confirm": {
+		En: "Confirm password: ",
+		Ru: "Повторите пароль: ",
+	},
+	"register.prompt_username": {
+		En: "Enter username (optional): ",
+		Ru: "Имя пользователя (необязательно): ",
+	},
+	"register.prompt_email": {
+		En: "Enter email (optional): ",
+		Ru: "Email (необязательно): ",
+	},
+	"register.password_empty": {
+		En: "Password is empty",
+		Ru: "Пароль пустой",
+	},
+	"register.password_mismatch": {
+		En: "Passwords don't match",
+		Ru: "Пароли не совпадают",
+	},
+	"register.success": {
+		En: "Registered successfully.",
+		Ru: "Регистрация выполнена.",
+	},
+
+	"note.added": {
+		En: "Note added successfully.",
+		Ru: "Заметка добавлена.",
+	},
+	"note.saved_locally": {
+		En: "Note saved locally, it will be synced when the server is reachable.",
+		Ru: "Заметка сохранена локально и будет синхронизирована, когда сервер станет доступен.",
+	},
+
+	"import.row_error": {
+		En: "row %d: %s",
+		Ru: "строка %d: %s",
+	},
+	"import.imported": {
+		En: "%d note imported|%d notes imported",
+		Ru: "импортирована %d заметка|импортировано %d заметки|импортировано %d заметок",
+	},
+	"import.duplicates": {
+		En: "%d duplicate|%d duplicates",
+		Ru: "%d дубликат|%d дубликата|%d дубликатов",
+	},
+	"import.rejected": {
+		En: "%d row rejected|%d rows rejected",
+		Ru: "отклонена %d строка|отклонено %d строки|отклонено %d строк",
+	},
+
+	"sync.pushed": {
+		En: "%d change pushed|%d changes pushed",
+		Ru: "отправлено изменений: %d|отправлено изменений: %d|отправлено изменений: %d",
+	},
+	"sync.pulled": {
+		En: "%d note pulled|%d notes pulled",
+		Ru: "получена %d заметка|получено %d заметки|получено %d заметок",
+	},
+	"sync.deleted": {
+		En: "%d deleted|%d deleted",
+		Ru: "удалена %d|удалено %d|удалено %d",
+	},
+	"sync.conflicts": {
+		En: "%d conflict|%d conflicts",
+		Ru: "%d конфликт|%d конфликта|%d конфликтов",
+	},
+	"sync.rejected": {
+		En: "%d note was rejected by the server and stays unsynced, edit it and sync again|%d notes were rejected by the server and stay unsynced, edit them and sync again",
+		Ru: "%d заметка отклонена сервером и не синхронизирована, измените её и синхронизируйте снова|%d заметки отклонены сервером и не синхронизированы, измените их и синхронизируйте снова|%d заметок отклонены сервером и не синхронизированы, измените их и синхронизируйте снова",
+	},
+
+	"telegram.linked": {
+		En: "Telegram chat %d is linked to your account.",
+		Ru: "Чат Telegram %d привязан к вашему аккаунту.",
+	},
+
+	"timer.started": {
+		En: "Started %q.",
+		Ru: "Начато «%s».",
+	},
+	"timer.stopped": {
+		En: "Stopped %q after %s.",
+		Ru: "Закончено «%s», заняло %s.",
+	},
+	"timer.saved_locally": {
+		En: "Saved locally, it will be synced when the server is reachable.",
+		Ru: "Сохранено локально и будет синхронизировано, когда сервер станет доступен.",
+	},
+	"timer.idle": {
+		En: "No activity is running.",
+		Ru: "Сейчас ничего не запущено.",
+	},
+	"timer.running": {
+		En: "%s, running for %s since %s",
+		Ru: "%s, идёт %s, с %s",
+	},
+
+	"notes.unknown_field": {
+		En: "unknown field %q, expected: %s",
+		Ru: "неизвестное поле %q, доступны: %s",
+	},
+	"notes.no_fields": {
+		En: "no fields",
+		Ru: "поля не указаны",
+	},
+	"notes.completed_invalid": {
+		En: "--completed: expected true or false",
+		Ru: "--completed: ожидается true или false",
+	},
+	"notes.no_session": {
+		En: "no session token",
+		Ru: "нет токена сессии",
+	},
+	"notes.manual": {
+		En: "Manual\n· ↑(k)/↓(j) - navigation\n· Space - select\n· ←(h)/→(l) - page\n· Enter - delete\n" +
+			"· c - toggle completed\n· e - edit content\n· t - set end time\n· g - change category\n· / - search\n· r - refresh\n· q - exit",
+		Ru: "Управление\n· ↑(k)/↓(j) - навигация\n· Space - выбрать\n· ←(h)/→(l) - страница\n· Enter - удалить\n" +
+			"· c - отметить выполненной\n· e - изменить текст\n· t - время окончания\n· g - сменить категорию\n· / - поиск\n· r - обновить\n· q - выход",
+	},
+	"notes.exit": {
+		En: "Exit...",
+		Ru: "Выход...",
+	},
+	"notes.until": {
+		En: "until %s",
+		Ru: "до %s",
+	},
+	"notes.confirm_delete": {
+		En: "Delete %d selected note? (y/n)|Delete %d selected notes? (y/n)",
+		Ru: "Удалить %d выбранную заметку? (y/n)|Удалить %d выбранные заметки? (y/n)|Удалить %d выбранных заметок? (y/n)",
+	},
+
+	"status.deleting": {
+		En: "deleting...",
+		Ru: "удаление...",
+	},
+	"status.deleted": {
+		En: "deleted",
+		Ru: "удалено",
+	},
+	"status.deleted_locally": {
+		En: "deleted locally, will be synced",
+		Ru: "удалено локально, будет синхронизировано",
+	},
+	"status.saving": {
+		En: "saving...",
+		Ru: "сохранение...",
+	},
+	"status.saved": {
+		En: "saved",
+		Ru: "сохранено",
+	},
+	"status.saved_locally": {
+		En: "saved locally, will be synced",
+		Ru: "сохранено локально, будет синхронизировано",
+	},
+	"status.server_kept": {
+		En: "changed on server, server version kept",
+		Ru: "изменено на сервере, оставлена версия сервера",
+	},
+	"status.failed": {
+		En: "failed: %s",
+		Ru: "ошибка: %s",
+	},
+
+	"edit.content_title": {
+		En: "Edit content (Enter - save, Esc - cancel)",
+		Ru: "Изменить текст (Enter - сохранить, Esc - отмена)",
+	},
+	"edit.content_placeholder": {
+		En: "note content",
+		Ru: "текст заметки",
+	},
+	"edit.content_empty": {
+		En: "content can not be empty",
+		Ru: "текст не может быть пустым",
+	},
+	"edit.end_title": {
+		En: "Set end time (Enter - save, Esc - cancel)",
+		Ru: "Время окончания (Enter - сохранить, Esc - отмена)",
+	},
+	"edit.end_placeholder": {
+		En: "now, in 2h, 21:30, yesterday 21:00 (empty clears)",
+		Ru: "now, in 2h, 21:30, yesterday 21:00 (пусто - сбросить)",
+	},
+	"edit.end_before_created": {
+		En: "end time is before the note was created",
+		Ru: "время окончания раньше создания заметки",
+	},
+	"edit.category_title": {
+		En: "Pick category (Enter - save, Esc - cancel)",
+		Ru: "Выбор категории (Enter - сохранить, Esc - отмена)",
+	},
+	"edit.categories_unavailable": {
+		En: "categories unavailable: %s",
+		Ru: "категории недоступны: %s",
+	},
+	"edit.categories_loading": {
+		En: "loading categories...",
+		Ru: "загрузка категорий...",
+	},
+	"edit.no_cached_categories": {
+		En: "no cached categories",
+		Ru: "нет сохранённых категорий",
+	},
+	"edit.no_category": {
+		En: "(no category)",
+		Ru: "(без категории)",
+	},
+
+	"search.no_text": {
+		En: "no search text",
+		Ru: "не задан текст поиска",
+	},
+	"search.placeholder": {
+		En: "search notes",
+		Ru: "поиск заметок",
+	},
+	"search.failed": {
+		En: "search failed: %s",
+		Ru: "поиск не удался: %s",
+	},
+	"search.typing": {
+		En: "Search, %d match (Enter - keep, Esc - clear)|Search, %d matches (Enter - keep, Esc - clear)",
+		Ru: "Поиск, %d совпадение (Enter - оставить, Esc - сбросить)|Поиск, %d совпадения (Enter - оставить, Esc - сбросить)|" +
+			"Поиск, %d совпадений (Enter - оставить, Esc - сбросить)",
+	},
+	"search.results": {
+		En: "Search %[2]q, %[1]d match (/ - change, Esc - clear)|Search %[2]q, %[1]d matches (/ - change, Esc - clear)",
+		Ru: "Поиск «%[2]s», %[1]d совпадение (/ - изменить, Esc - сбросить)|Поиск «%[2]s», %[1]d совпадения (/ - изменить, Esc - сбросить)|" +
+			"Поиск «%[2]s», %[1]d совпадений (/ - изменить, Esc - сбросить)",
+	},
+
+	"dashboard.manual": {
+		En: "Manual\n· ←(h)/→(l) - category\n· r - refresh\n· q - exit",
+		Ru: "Управление\n· ←(h)/→(l) - категория\n· r - обновить\n· q - выход",
+	},
+	"dashboard.all": {
+		En: "All",
+		Ru: "Все",
+	},
+	"dashboard.notes_unavailable": {
+		En: "notes unavailable: %s",
+		Ru: "заметки недоступны: %s",
+	},
+	"dashboard.months": {
+		En: "Jan Feb Mar Apr May Jun Jul Aug Sep Oct Nov Dec",
+		Ru: "Янв Фев Мар Апр Май Июн Июл Авг Сен Окт Ноя Дек",
+	},
+	"dashboard.weekdays": {
+		En: "Mon Wed Fri",
+		Ru: "Пн Ср Пт",
+	},
+	"dashboard.less": {
+		En: "Less",
+		Ru: "Меньше",
+	},
+	"dashboard.more": {
+		En: "More",
+		Ru: "Больше",
+	},
+	"dashboard.current_streak": {
+		En: "Current streak:",
+		Ru: "Текущая серия:",
+	},
+	"dashboard.longest_streak": {
+		En: "Longest streak:",
+		Ru: "Лучшая серия:",
+	},
+	"dashboard.today": {
+		En: "Today",
+		Ru: "Сегодня",
+	},
+	"dashboard.all_done": {
+		En: "all done",
+		Ru: "всё сделано",
+	},
+	"dashboard.nothing_planned": {
+		En: "nothing planned",
+		Ru: "ничего не запланировано",
+	},
+	"dashboard.week": {
+		En: "Week",
+		Ru: "Неделя",
+	},
+	"dashboard.this_week": {
+		En: "this week",
+		Ru: "текущая",
+	},
+	"dashboard.last_week": {
+		En: "last week",
+		Ru: "прошлая",
+	},
+	"dashboard.week_line": {
+		En: "%3d done  %3d pending  %2d/7 active days  %s tracked",
+		Ru: "%3d сделано  %3d в работе  %2d/7 активных дней  %s учтено",
+	},
+	"dashboard.tracked_minutes": {
+		En: "%dm",
+		Ru: "%dм",
+	},
+	"dashboard.tracked_hours": {
+		En: "%dh%02dm",
+		Ru: "%dч%02dм",
+	},
+}
@@ -0,0 +1,34 @@
+package i18n
+
+import (
+	"os"
+	"time"
+)
+
+var current = Default
+
+// FromEnv reads the language of the terminal from LC_ALL, LC_MESSAGES and LANG
+func FromEnv() Locale {
+	return Resolve(os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG"))
+}
+
+func SetLocale(locale Locale) {
+	current = locale
+}
+
+func Current() Locale {
+	return current
+}
+
+// T formats the message in the locale of the command line
+func T(key string, args ...any) string {
+	return NewPrinter(current).T(key, args...)
+}
+
+func N(key string, n int, args ...any) string {
+	return NewPrinter(current).N(key, n, args...)
+}
+
+func Duration(d time.Duration) string {
+	return NewPrinter(current).Duration(d)
+}
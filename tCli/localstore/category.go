@@ -0,0 +1,88 @@
+package localstore
+
+import (
+	"database/sql"
+	"errors"
+	"halo/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveCategoriesLocally replaces the cached categories with the server list
+func SaveCategoriesLocally(categories []models.CategoryStruct) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM categories;`); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO categories (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4);`
+	for _, category := range categories {
+		if _, err := tx.Exec(query, category.Id, category.Name, category.Created_at, category.Updated_at); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func AddCategoryLocally(category models.CategoryStruct) error {
+	query := `INSERT INTO categories (id, name, created_at, updated_at)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (id) DO UPDATE SET name = excluded.name, updated_at = excluded.updated_at;`
+
+	_, err := db.Exec(query, category.Id, category.Name, category.Created_at, category.Updated_at)
+	return err
+}
+
+func DeleteCategoryLocally(id uuid.UUID) error {
+	_, err := db.Exec(`DELETE FROM categories WHERE id = $1;`, id)
+	return err
+}
+
+func GetCategoriesLocally() ([]models.CategoryStruct, error) {
+	rows, err := db.Query(`SELECT id, name, created_at, updated_at FROM categories ORDER BY name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]models.CategoryStruct, 0)
+	for rows.Next() {
+		var category models.CategoryStruct
+		var createdAt, updatedAt sql.NullInt64
+
+		if err := rows.Scan(&category.Id, &category.Name, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		category.Created_at = createdAt.Int64
+		category.Updated_at = updatedAt.Int64
+
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetCategoryByName reports false when the name is not in the cache
+func GetCategoryByName(name string) (models.CategoryStruct, bool, error) {
+	var category models.CategoryStruct
+	var createdAt, updatedAt sql.NullInt64
+
+	query := `SELECT id, name, created_at, updated_at FROM categories WHERE name = $1;`
+	err := db.QueryRow(query, name).Scan(&category.Id, &category.Name, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return category, false, nil
+	}
+	if err != nil {
+		return category, false, err
+	}
+
+	category.Created_at = createdAt.Int64
+	category.Updated_at = updatedAt.Int64
+	return category, true, nil
+}
@@ -3,6 +3,7 @@ package localstore
 import (
 	"database/sql"
 	"fmt"
+	"halo/config"
 	"halo/logger"
 	"os"
 	"path/filepath"
@@ -13,6 +14,10 @@ import (
 var db *sql.DB
 
 func getDbPath() (string, error) {
+	if _, profile := config.ActiveProfile(); profile.Db_path != "" {
+		return profile.Db_path, nil
+	}
+
 	dir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("get user home db dir: %w", err)
@@ -20,30 +25,35 @@ func getDbPath() (string, error) {
 	return filepath.Join(dir, "halo", "db.sqlite"), nil
 }
 
+// GetLocalDbConnection only opens the database, AutoMigrate brings its schema up to date
 func GetLocalDbConnection() {
 	path, err := getDbPath()
 	if err != nil {
 		logger.Logger.Error().Err(err).Msg("local db path")
 	}
 
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logger.Logger.Error().Err(err).Msg("local db dir")
+	}
+
 	db, err = sql.Open("sqlite", path)
 	if err != nil {
 		logger.Logger.Error().Err(err).Msg("open local db")
 	}
 
-	schema := `
-	CREATE TABLE IF NOT EXISTS notes (
-  	id UUID PRIMARY KEY,
-  	category_id UUID,
-  	content TEXT NOT NULL,
-  	created_at BIGINT,
-  	updated_at BIGINT,
-  	ended_at BIGINT,
-  	completed BOOLEAN DEFAULT FALSE
-	);`
-	if _, err := db.Exec(schema); err != nil {
-		logger.Logger.Error().Err(err).Msg("create table")
-	}
-
 	logger.Logger.Info().Msg("local db connection established")
 }
+
+// AutoMigrate applies pending migrations before a command uses the store,
+// the backup path is only logged because most commands print nothing about the db
+func AutoMigrate() error {
+	applied, backup, err := Migrate()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("migrate local db")
+		return fmt.Errorf("migrate local db: %w", err)
+	}
+	if len(applied) > 0 && backup != "" {
+		logger.Logger.Info().Str("backup", backup).Int("migrations", len(applied)).Msg("local db backed up")
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package localstore
+
+import (
+	"embed"
+	"fmt"
+	"halo/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// backups older than the newest few are removed after a migration
+const keepBackups = 3
+
+// Migration is one embedded file migrations/NNNN_name.sql
+type Migration struct {
+	Version int
+	Name    string
+	sql     string
+}
+
+// AppliedMigration is a row of schema_version
+type AppliedMigration struct {
+	Version    int
+	Name       string
+	Applied_at int64
+}
+
+type MigrationStatus struct {
+	Current int
+	Latest  int
+	Applied []AppliedMigration
+	Pending []Migration
+}
+
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: expected NNNN_name.sql", entry.Name())
+		}
+
+		data, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, sql: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+func createVersionTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_version (
+						version INTEGER PRIMARY KEY,
+						name TEXT NOT NULL,
+						applied_at BIGINT NOT NULL
+						);`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func GetMigrationStatus() (MigrationStatus, error) {
+	var status MigrationStatus
+
+	if err := createVersionTable(); err != nil {
+		return status, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return status, err
+	}
+	if len(migrations) > 0 {
+		status.Latest = migrations[len(migrations)-1].Version
+	}
+
+	rows, err := db.Query(`SELECT version, name, applied_at FROM schema_version ORDER BY version;`)
+	if err != nil {
+		return status, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var migration AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.Applied_at); err != nil {
+			return status, err
+		}
+		status.Applied = append(status.Applied, migration)
+		status.Current = max(status.Current, migration.Version)
+		applied[migration.Version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return status, err
+	}
+
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// Migrate applies the pending migrations in order, each in its own transaction,
+// after a backup of the existing database; it returns the applied ones and the backup path
+func Migrate() ([]Migration, string, error) {
+	status, err := GetMigrationStatus()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// an older halo must not touch a store it does not understand
+	if status.Current > status.Latest {
+		return nil, "", fmt.Errorf(
+			"local database is at version %d, this halo knows up to %d, please upgrade halo",
+			status.Current,
+			status.Latest,
+		)
+	}
+	if len(status.Pending) == 0 {
+		return nil, "", nil
+	}
+
+	backup, err := backupDb(status.Current)
+	if err != nil {
+		return nil, "", fmt.Errorf("backup before migrating: %w", err)
+	}
+
+	applied := make([]Migration, 0, len(status.Pending))
+	for _, migration := range status.Pending {
+		if err := applyMigration(migration); err != nil {
+			return applied, backup, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		logger.Logger.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("local db migrated")
+		applied = append(applied, migration)
+	}
+
+	return applied, backup, nil
+}
+
+func applyMigration(migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.sql); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, $3);`
+	if _, err := tx.Exec(query, migration.Version, migration.Name, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// backupDb copies a database which already holds data next to it,
+// a fresh database has nothing to lose and is not copied
+func backupDb(version int) (string, error) {
+	var tables int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name != 'schema_version';`
+	if err := db.QueryRow(query).Scan(&tables); err != nil {
+		return "", err
+	}
+	if tables == 0 {
+		return "", nil
+	}
+
+	path, err := getDbPath()
+	if err != nil {
+		return "", err
+	}
+
+	backup := fmt.Sprintf("%s.v%d-%s.bak", path, version, time.Now().Format("20060102-150405"))
+	if _, err := db.Exec(`VACUUM INTO $1;`, backup); err != nil {
+		return "", err
+	}
+
+	pruneBackups(path)
+	return backup, nil
+}
+
+func pruneBackups(path string) {
+	backups, err := filepath.Glob(path + ".v*.bak")
+	if err != nil || len(backups) <= keepBackups {
+		return
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backupTime(backups[i]).Before(backupTime(backups[j]))
+	})
+	for _, old := range backups[:len(backups)-keepBackups] {
+		if err := os.Remove(old); err != nil {
+			logger.Logger.Error().Err(err).Str("path", old).Msg("remove old backup")
+		}
+	}
+}
+
+func backupTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// DbPath is the database file of the active profile
+func DbPath() (string, error) {
+	return getDbPath()
+}
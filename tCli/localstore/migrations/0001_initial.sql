@@ -0,0 +1,28 @@
+-- tables of the stores created before versioned migrations, IF NOT EXISTS adopts them
+CREATE TABLE IF NOT EXISTS notes (
+  id UUID PRIMARY KEY,
+  category_id UUID,
+  content TEXT NOT NULL,
+  created_at BIGINT,
+  updated_at BIGINT,
+  ended_at BIGINT,
+  completed BOOLEAN DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS pending_changes (
+  note_id UUID PRIMARY KEY,
+  op TEXT NOT NULL,
+  changed_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sync_meta (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+  id UUID PRIMARY KEY,
+  name TEXT NOT NULL,
+  created_at BIGINT,
+  updated_at BIGINT
+);
@@ -0,0 +1,24 @@
+-- notes_fts keeps its own copy of the content keyed by note id, the implicit
+-- rowid of notes may change on VACUUM; the triggers keep it in sync
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+  id UNINDEXED,
+  content,
+  tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_insert AFTER INSERT ON notes BEGIN
+  INSERT INTO notes_fts (id, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_delete AFTER DELETE ON notes BEGIN
+  DELETE FROM notes_fts WHERE id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_update AFTER UPDATE OF content ON notes BEGIN
+  DELETE FROM notes_fts WHERE id = old.id;
+  INSERT INTO notes_fts (id, content) VALUES (new.id, new.content);
+END;
+
+INSERT INTO notes_fts (id, content)
+SELECT id, content FROM notes
+WHERE id NOT IN (SELECT id FROM notes_fts);
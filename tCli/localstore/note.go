@@ -1,10 +1,45 @@
 package localstore
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"halo/logger"
 	"halo/models"
+
+	"github.com/google/uuid"
 )
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanNote reads the note columns followed by the synced flag
+func scanNote(row rowScanner) (models.NoteStruct, error) {
+	var noteInfo models.NoteStruct
+	var createdAt, updatedAt, endedAt sql.NullInt64
+
+	err := row.Scan(
+		&noteInfo.Id,
+		&noteInfo.Category_id,
+		&noteInfo.Content,
+		&createdAt,
+		&updatedAt,
+		&endedAt,
+		&noteInfo.Completed,
+		&noteInfo.Synced,
+	)
+	if err != nil {
+		return noteInfo, err
+	}
+
+	noteInfo.Created_at = int(createdAt.Int64)
+	noteInfo.Updated_at = int(updatedAt.Int64)
+	noteInfo.Ended_at = int(endedAt.Int64)
+
+	return noteInfo, nil
+}
+
 func AddNoteLocally(note models.NoteStruct) error {
 	query := `INSERT INTO notes (id, category_id, content, created_at, updated_at, ended_at, completed) 
 						VALUES ($1, $2, $3, $4, $5, $6, $7);`
@@ -35,9 +70,11 @@ func GetNotesLocally(currentPage int, pageSize int) []models.NoteStruct {
 
 	offset := currentPage * pageSize
 
-	query := `SELECT id, category_id, content, created_at, updated_at, ended_at, completed
-						FROM notes
-						ORDER BY created_at DESC
+	query := `SELECT n.id, n.category_id, n.content, n.created_at, n.updated_at, n.ended_at, n.completed,
+						p.note_id IS NULL
+						FROM notes n
+						LEFT JOIN pending_changes p ON p.note_id = n.id
+						ORDER BY n.created_at DESC
 						LIMIT $1 OFFSET $2;`
 	rows, err := db.Query(query, pageSize, offset)
 	if err != nil {
@@ -50,18 +87,7 @@ func GetNotesLocally(currentPage int, pageSize int) []models.NoteStruct {
 	notes := make([]models.NoteStruct, 0)
 
 	for rows.Next() {
-		var noteInfo models.NoteStruct
-		var createdAt, updatedAt, endedAt int
-
-		err := rows.Scan(
-			&noteInfo.Id,
-			&noteInfo.Category_id,
-			&noteInfo.Content,
-			&createdAt,
-			&updatedAt,
-			&endedAt,
-			&noteInfo.Completed,
-		)
+		noteInfo, err := scanNote(rows)
 		if err != nil {
 			logger.Logger.Error().Err(err).Msg("note info scan")
 			return nil
@@ -93,3 +119,89 @@ func GetNumberOfNotes() (int, error) {
 	}
 	return count, nil
 }
+
+// GetNoteLocally reports false when the note is not in the local store
+func GetNoteLocally(id uuid.UUID) (models.NoteStruct, bool, error) {
+	query := `SELECT n.id, n.category_id, n.content, n.created_at, n.updated_at, n.ended_at, n.completed,
+						p.note_id IS NULL
+						FROM notes n
+						LEFT JOIN pending_changes p ON p.note_id = n.id
+						WHERE n.id = $1`
+
+	noteInfo, err := scanNote(db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return noteInfo, false, nil
+	}
+	if err != nil {
+		return noteInfo, false, err
+	}
+	return noteInfo, true, nil
+}
+
+// UpdateNoteLocally overwrites the editable fields of a note
+func UpdateNoteLocally(note models.NoteStruct) error {
+	query := `UPDATE notes
+						SET category_id = $2, content = $3, updated_at = $4, ended_at = $5, completed = $6
+						WHERE id = $1;`
+
+	_, err := db.Exec(
+		query,
+		note.Id,
+		note.Category_id,
+		note.Content,
+		note.Updated_at,
+		note.Ended_at,
+		note.Completed,
+	)
+	return err
+}
+
+// QueryNotes returns the notes matching the filter, newest first
+func QueryNotes(filter models.NoteFilter) ([]models.NoteStruct, error) {
+	query := `SELECT n.id, n.category_id, n.content, n.created_at, n.updated_at, n.ended_at, n.completed,
+						p.note_id IS NULL
+						FROM notes n
+						LEFT JOIN pending_changes p ON p.note_id = n.id
+						WHERE 1 = 1`
+	var args []any
+
+	if filter.Category_id != nil {
+		args = append(args, *filter.Category_id)
+		query += fmt.Sprintf(" AND n.category_id = $%d", len(args))
+	}
+	if filter.Completed != nil {
+		args = append(args, *filter.Completed)
+		query += fmt.Sprintf(" AND n.completed = $%d", len(args))
+	}
+	if filter.From != 0 {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND n.created_at >= $%d", len(args))
+	}
+	if filter.To != 0 {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND n.created_at < $%d", len(args))
+	}
+
+	query += " ORDER BY n.created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]models.NoteStruct, 0)
+	for rows.Next() {
+		noteInfo, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, noteInfo)
+	}
+
+	return notes, rows.Err()
+}
@@ -0,0 +1,78 @@
+package localstore
+
+import (
+	"fmt"
+	"halo/models"
+	"strings"
+	"unicode"
+)
+
+// matchQuery turns free text into an FTS5 query where every word is a prefix,
+// words are quoted so FTS5 syntax typed by the user is taken literally
+func matchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// SearchNotes returns the notes matching the text and the filter, best matches first
+func SearchNotes(text string, filter models.NoteFilter) ([]models.NoteStruct, error) {
+	match := matchQuery(text)
+	if match == "" {
+		return make([]models.NoteStruct, 0), nil
+	}
+
+	query := `SELECT n.id, n.category_id, n.content, n.created_at, n.updated_at, n.ended_at, n.completed,
+						p.note_id IS NULL
+						FROM notes_fts f
+						JOIN notes n ON n.id = f.id
+						LEFT JOIN pending_changes p ON p.note_id = n.id
+						WHERE notes_fts MATCH $1`
+	args := []any{match}
+
+	if filter.Category_id != nil {
+		args = append(args, *filter.Category_id)
+		query += fmt.Sprintf(" AND n.category_id = $%d", len(args))
+	}
+	if filter.Completed != nil {
+		args = append(args, *filter.Completed)
+		query += fmt.Sprintf(" AND n.completed = $%d", len(args))
+	}
+	if filter.From != 0 {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND n.created_at >= $%d", len(args))
+	}
+	if filter.To != 0 {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND n.created_at < $%d", len(args))
+	}
+
+	query += " ORDER BY f.rank, n.created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]models.NoteStruct, 0)
+	for rows.Next() {
+		noteInfo, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, noteInfo)
+	}
+
+	return notes, rows.Err()
+}
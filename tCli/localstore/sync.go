@@ -0,0 +1,167 @@
+package localstore
+
+import (
+	"database/sql"
+	"errors"
+	"halo/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChangeUpsert = "upsert"
+	ChangeDelete = "delete"
+
+	// MetaChangesCursor is the cursor of the note_service changes feed, it replaced
+	// last_pull, which held a server time and is ignored now
+	MetaChangesCursor = "changes_cursor"
+)
+
+// QueueChange records a local change that still has to be pushed,
+// a later change of the same note replaces the earlier one
+func QueueChange(noteId uuid.UUID, op string, changedAt int64) error {
+	query := `INSERT INTO pending_changes (note_id, op, changed_at)
+						VALUES ($1, $2, $3)
+						ON CONFLICT (note_id) DO UPDATE SET op = excluded.op, changed_at = excluded.changed_at;`
+
+	_, err := db.Exec(query, noteId, op, changedAt)
+	return err
+}
+
+func GetPendingChanges() ([]models.PendingChange, error) {
+	query := `SELECT note_id, op, changed_at FROM pending_changes ORDER BY changed_at;`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]models.PendingChange, 0)
+	for rows.Next() {
+		var change models.PendingChange
+		if err := rows.Scan(&change.Note_id, &change.Op, &change.Changed_at); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func GetNumberOfPendingChanges() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pending_changes`).Scan(&count)
+	return count, err
+}
+
+// ClearPendingChange keeps the entry if the note was changed again after changedAt
+func ClearPendingChange(noteId uuid.UUID, changedAt int64) error {
+	query := `DELETE FROM pending_changes WHERE note_id = $1 AND changed_at <= $2;`
+
+	_, err := db.Exec(query, noteId, changedAt)
+	return err
+}
+
+func getPendingChange(noteId uuid.UUID) (models.PendingChange, bool, error) {
+	var change models.PendingChange
+
+	query := `SELECT note_id, op, changed_at FROM pending_changes WHERE note_id = $1;`
+	err := db.QueryRow(query, noteId).Scan(&change.Note_id, &change.Op, &change.Changed_at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return change, false, nil
+	}
+	if err != nil {
+		return change, false, err
+	}
+	return change, true, nil
+}
+
+// ApplyRemoteNote stores a note from the server unless the local copy was
+// changed later (last writer wins on updated_at), it reports whether the note was written
+func ApplyRemoteNote(note models.NoteStruct) (bool, error) {
+	if note.Updated_at == 0 {
+		note.Updated_at = note.Created_at
+	}
+
+	pending, found, err := getPendingChange(note.Id)
+	if err != nil {
+		return false, err
+	}
+	if found && pending.Changed_at > int64(note.Updated_at) {
+		return false, nil
+	}
+
+	query := `INSERT INTO notes (id, category_id, content, created_at, updated_at, ended_at, completed)
+						VALUES ($1, $2, $3, $4, $5, $6, $7)
+						ON CONFLICT (id) DO UPDATE SET
+							category_id = excluded.category_id,
+							content = excluded.content,
+							updated_at = excluded.updated_at,
+							ended_at = excluded.ended_at,
+							completed = excluded.completed
+						WHERE COALESCE(notes.updated_at, 0) <= excluded.updated_at;`
+
+	res, err := db.Exec(
+		query,
+		note.Id,
+		note.Category_id,
+		note.Content,
+		note.Created_at,
+		note.Updated_at,
+		note.Ended_at,
+		note.Completed,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, _ := res.RowsAffected()
+	if affected == 0 {
+		return false, nil
+	}
+
+	return true, ClearPendingChange(note.Id, int64(note.Updated_at))
+}
+
+// ApplyRemoteDelete drops a note deleted on the server unless it was edited locally afterwards
+func ApplyRemoteDelete(noteId uuid.UUID, deletedAt int64) (bool, error) {
+	pending, found, err := getPendingChange(noteId)
+	if err != nil {
+		return false, err
+	}
+	if found && pending.Op == ChangeUpsert && pending.Changed_at > deletedAt {
+		return false, nil
+	}
+
+	res, err := db.Exec(`DELETE FROM notes WHERE id = $1;`, noteId)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := db.Exec(`DELETE FROM pending_changes WHERE note_id = $1;`, noteId); err != nil {
+		return false, err
+	}
+
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func GetSyncMeta(key string) (string, error) {
+	var value string
+
+	err := db.QueryRow(`SELECT value FROM sync_meta WHERE key = $1;`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+func SetSyncMeta(key string, value string) error {
+	query := `INSERT INTO sync_meta (key, value)
+						VALUES ($1, $2)
+						ON CONFLICT (key) DO UPDATE SET value = excluded.value;`
+
+	_, err := db.Exec(query, key, value)
+	return err
+}
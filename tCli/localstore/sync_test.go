@@ -0,0 +1,157 @@
+package localstore
+
+import (
+	"halo/config"
+	"halo/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// openTestDb points the store at a fresh database in a temporary home
+func openTestDb(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	if err := config.Init(""); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	GetLocalDbConnection()
+	t.Cleanup(func() { db.Close() })
+
+	if _, _, err := Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+}
+
+// addLocalNote stores a note changed locally and not pushed yet
+func addLocalNote(t *testing.T, content string, updatedAt int) models.NoteStruct {
+	t.Helper()
+
+	note := models.NoteStruct{Id: uuid.New(), Content: content, Created_at: 1000, Updated_at: updatedAt}
+	if err := AddNoteLocally(note); err != nil {
+		t.Fatalf("AddNoteLocally: %v", err)
+	}
+	if err := QueueChange(note.Id, ChangeUpsert, int64(updatedAt)); err != nil {
+		t.Fatalf("QueueChange: %v", err)
+	}
+	return note
+}
+
+func getNote(t *testing.T, id uuid.UUID) (models.NoteStruct, bool) {
+	t.Helper()
+
+	note, found, err := GetNoteLocally(id)
+	if err != nil {
+		t.Fatalf("GetNoteLocally: %v", err)
+	}
+	return note, found
+}
+
+func TestApplyRemoteNote(t *testing.T) {
+	openTestDb(t)
+
+	local := addLocalNote(t, "local edit", 2000)
+
+	// an older server copy loses against the pending local edit
+	remote := local
+	remote.Content = "server copy"
+	remote.Updated_at = 1500
+	applied, err := ApplyRemoteNote(remote)
+	if err != nil || applied {
+		t.Fatalf("ApplyRemoteNote(older) = %v, %v, want not applied", applied, err)
+	}
+	if note, _ := getNote(t, local.Id); note.Content != "local edit" || note.Synced {
+		t.Errorf("note after an older remote copy = %+v", note)
+	}
+
+	// a newer one replaces the note and settles the pending change
+	remote.Updated_at = 2500
+	applied, err = ApplyRemoteNote(remote)
+	if err != nil || !applied {
+		t.Fatalf("ApplyRemoteNote(newer) = %v, %v, want applied", applied, err)
+	}
+	if note, _ := getNote(t, local.Id); note.Content != "server copy" || !note.Synced {
+		t.Errorf("note after a newer remote copy = %+v", note)
+	}
+
+	// a note only the server knows is added
+	fresh := models.NoteStruct{Id: uuid.New(), Content: "from another device", Created_at: 1000}
+	if applied, err := ApplyRemoteNote(fresh); err != nil || !applied {
+		t.Fatalf("ApplyRemoteNote(new) = %v, %v, want applied", applied, err)
+	}
+	if note, found := getNote(t, fresh.Id); !found || note.Updated_at != 1000 {
+		t.Errorf("new remote note = %+v, %v, want updated_at defaulting to created_at", note, found)
+	}
+}
+
+func TestApplyRemoteDelete(t *testing.T) {
+	openTestDb(t)
+
+	edited := addLocalNote(t, "edited after the delete", 2000)
+	deleted, err := ApplyRemoteDelete(edited.Id, 1500)
+	if err != nil || deleted {
+		t.Fatalf("ApplyRemoteDelete(edited later) = %v, %v, want kept", deleted, err)
+	}
+	if _, found := getNote(t, edited.Id); !found {
+		t.Error("a note edited after the remote delete was removed")
+	}
+
+	stale := addLocalNote(t, "edited before the delete", 1000)
+	deleted, err = ApplyRemoteDelete(stale.Id, 1500)
+	if err != nil || !deleted {
+		t.Fatalf("ApplyRemoteDelete(edited before) = %v, %v, want deleted", deleted, err)
+	}
+	if _, found := getNote(t, stale.Id); found {
+		t.Error("the note is still stored")
+	}
+
+	pending, err := GetPendingChanges()
+	if err != nil {
+		t.Fatalf("GetPendingChanges: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Note_id != edited.Id {
+		t.Errorf("pending changes = %+v, want only the later edit", pending)
+	}
+}
+
+func TestClearPendingChange(t *testing.T) {
+	openTestDb(t)
+
+	note := addLocalNote(t, "note", 1000)
+
+	// the note was changed again while the push was in flight
+	if err := QueueChange(note.Id, ChangeUpsert, 2000); err != nil {
+		t.Fatalf("QueueChange: %v", err)
+	}
+	if err := ClearPendingChange(note.Id, 1000); err != nil {
+		t.Fatalf("ClearPendingChange: %v", err)
+	}
+	if count, _ := GetNumberOfPendingChanges(); count != 1 {
+		t.Fatalf("pending changes = %d, want the later change kept", count)
+	}
+
+	if err := ClearPendingChange(note.Id, 2000); err != nil {
+		t.Fatalf("ClearPendingChange: %v", err)
+	}
+	if count, _ := GetNumberOfPendingChanges(); count != 0 {
+		t.Errorf("pending changes = %d, want none", count)
+	}
+}
+
+func TestSyncMeta(t *testing.T) {
+	openTestDb(t)
+
+	if value, err := GetSyncMeta(MetaChangesCursor); err != nil || value != "" {
+		t.Fatalf("GetSyncMeta on a fresh db = %q, %v", value, err)
+	}
+	for _, cursor := range []string{"7", "42"} {
+		if err := SetSyncMeta(MetaChangesCursor, cursor); err != nil {
+			t.Fatalf("SetSyncMeta: %v", err)
+		}
+		if value, _ := GetSyncMeta(MetaChangesCursor); value != cursor {
+			t.Errorf("cursor = %q, want %q", value, cursor)
+		}
+	}
+}
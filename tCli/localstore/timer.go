@@ -0,0 +1,42 @@
+package localstore
+
+import (
+	"halo/models"
+
+	"github.com/google/uuid"
+)
+
+// the running activity is a note without ended_at, its id is kept next to the sync state
+const MetaActiveTimer = "active_timer"
+
+// GetActiveTimer returns the running activity, a timer whose note was deleted
+// (locally or by a sync) is forgotten
+func GetActiveTimer() (models.NoteStruct, bool, error) {
+	value, err := GetSyncMeta(MetaActiveTimer)
+	if err != nil || value == "" {
+		return models.NoteStruct{}, false, err
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return models.NoteStruct{}, false, ClearActiveTimer()
+	}
+
+	note, found, err := GetNoteLocally(id)
+	if err != nil {
+		return models.NoteStruct{}, false, err
+	}
+	if !found || note.Ended_at != 0 {
+		return models.NoteStruct{}, false, ClearActiveTimer()
+	}
+
+	return note, true, nil
+}
+
+func SetActiveTimer(id uuid.UUID) error {
+	return SetSyncMeta(MetaActiveTimer, id.String())
+}
+
+func ClearActiveTimer() error {
+	return SetSyncMeta(MetaActiveTimer, "")
+}
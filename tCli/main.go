@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"halo/client"
 	"halo/cmd"
+	"halo/config"
+	"halo/i18n"
 	"halo/localstore"
 	"halo/logger"
 	"os"
@@ -19,27 +23,72 @@ func isDebugEnabled() bool {
 	return os.Getenv("HALO_DEBUG") == "1"
 }
 
+// initProfile applies the selected profile before any command touches the server or the local db
+func initProfile(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	if err := config.Init(cmd.String("profile")); err != nil {
+		return ctx, err
+	}
+
+	_, profile := config.ActiveProfile()
+	if err := client.Init(profile); err != nil {
+		return ctx, err
+	}
+
+	// the profile locale wins over the language of the terminal
+	i18n.SetLocale(i18n.Resolve(profile.Locale, string(i18n.FromEnv())))
+
+	localstore.GetLocalDbConnection()
+
+	// db status and migrate show and apply the pending migrations themselves
+	if cmd.Args().First() == "db" {
+		return ctx, nil
+	}
+	return ctx, localstore.AutoMigrate()
+}
+
 func main() {
 	loadEnv()
 
 	debug := isDebugEnabled()
 	logger.Init(debug)
 
-	localstore.GetLocalDbConnection()
-
 	cmd := &cli.Command{
 		Name:                   "halo",
 		Usage:                  "Terminal client for habit tracking",
 		UseShortOptionHandling: true,
+		EnableShellCompletion:  true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "profile",
+				Usage:   "config profile to use (default: current profile)",
+				Sources: cli.EnvVars("HALO_PROFILE"),
+			},
+		},
+		Before: initProfile,
 		Commands: []*cli.Command{
 			cmd.LoginCommand,
 			cmd.AddNoteCommand,
 			cmd.NoteListCommand,
+			cmd.NoteLsCommand,
 			cmd.RegisterCommand,
+			cmd.ImportCommand,
+			cmd.SyncCommand,
+			cmd.CategoryCommand,
+			cmd.ConfigCommand,
+			cmd.LogoutCommand,
+			cmd.StartCommand,
+			cmd.StopCommand,
+			cmd.StatusCommand,
+			cmd.DashboardCommand,
+			cmd.SearchCommand,
+			cmd.DbCommand,
+			cmd.TelegramCommand,
 		},
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
 		logger.Logger.Error().Err(err).Msg("Run command")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type CategoryStruct struct {
+	Id         uuid.UUID `json:"category_id"`
+	Name       string    `json:"name"`
+	Created_at int64     `json:"created_at"`
+	Updated_at int64     `json:"updated_at"`
+}
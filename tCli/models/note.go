@@ -14,3 +14,62 @@ type NoteStruct struct {
 	Completed   bool      `json:"completed"`
 	Synced      bool      `json:"synced"`
 }
+
+type NoteImportResult struct {
+	Row       int         `json:"row"`
+	Client_id string      `json:"client_id"`
+	Status    string      `json:"status"`
+	Error     string      `json:"error"`
+	Note      *NoteStruct `json:"note"`
+}
+
+type NoteImportReport struct {
+	Imported   int                `json:"imported"`
+	Duplicates int                `json:"duplicates"`
+	Rejected   int                `json:"rejected"`
+	Rows       []NoteImportResult `json:"rows"`
+}
+
+type PendingChange struct {
+	Note_id    uuid.UUID
+	Op         string
+	Changed_at int64
+}
+
+type NoteTombstone struct {
+	Note_id    uuid.UUID `json:"note_id"`
+	Deleted_at int64     `json:"deleted_at"`
+}
+
+type NoteChanges struct {
+	Notes    []NoteStruct    `json:"notes"`
+	Deleted  []NoteTombstone `json:"deleted"`
+	Cursor   int64           `json:"cursor"`
+	Has_more bool            `json:"has_more"`
+}
+
+type NoteSyncRequest struct {
+	Notes   []NoteStruct    `json:"notes"`
+	Deleted []NoteTombstone `json:"deleted"`
+}
+
+type NoteSyncConflict struct {
+	Note_id uuid.UUID   `json:"note_id"`
+	Reason  string      `json:"reason"`
+	Note    *NoteStruct `json:"note"`
+}
+
+type NoteSyncResponse struct {
+	Applied     []uuid.UUID        `json:"applied"`
+	Conflicts   []NoteSyncConflict `json:"conflicts"`
+	Server_time int64              `json:"server_time"`
+}
+
+// NoteFilter narrows local note queries, zero values mean "any"
+type NoteFilter struct {
+	Category_id *uuid.UUID
+	Completed   *bool
+	From        int64
+	To          int64
+	Limit       int
+}
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/google/uuid"
+
+type TelegramLink struct {
+	Chat_id   int64     `json:"chat_id"`
+	User_id   uuid.UUID `json:"user_id"`
+	Linked_at int64     `json:"linked_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+type UserProfile struct {
+	User_id  uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Locale   string    `json:"locale"`
+}
+
+type UserLocaleRequest struct {
+	Locale string `json:"locale"`
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	FormatTable  = "table"
+	FormatJson   = "json"
+	FormatCsv    = "csv"
+	FormatNdjson = "ndjson"
+)
+
+var Formats = []string{FormatTable, FormatJson, FormatCsv, FormatNdjson}
+
+// Record is one output row, values are strings, numbers, bools or nil
+type Record map[string]any
+
+func CheckFormat(format string) error {
+	for _, known := range Formats {
+		if format == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output %q, expected one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// Write prints the records with the given fields in the given order
+func Write(w io.Writer, format string, fields []string, records []Record) error {
+	switch format {
+	case FormatJson:
+		return writeJson(w, fields, records)
+	case FormatNdjson:
+		return writeNdjson(w, fields, records)
+	case FormatCsv:
+		return writeCsv(w, fields, records)
+	case FormatTable:
+		return writeTable(w, fields, records)
+	}
+	return CheckFormat(format)
+}
+
+// marshalOrdered keeps the field order, which encoding/json does not do for maps
+func marshalOrdered(fields []string, record Record) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(record[field])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func writeJson(w io.Writer, fields []string, records []Record) error {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, record := range records {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+		line, err := marshalOrdered(fields, record)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+	}
+	if len(records) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeNdjson(w io.Writer, fields []string, records []Record) error {
+	for _, record := range records {
+		line, err := marshalOrdered(fields, record)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func text(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func writeCsv(w io.Writer, fields []string, records []Record) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fields); err != nil {
+		return err
+	}
+
+	row := make([]string, len(fields))
+	for _, record := range records {
+		for i, field := range fields {
+			row[i] = text(record[field])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeTable(w io.Writer, fields []string, records []Record) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = strings.ToUpper(field)
+	}
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+
+	row := make([]string, len(fields))
+	for _, record := range records {
+		for i, field := range fields {
+			// tabs and newlines would break the columns
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(text(record[field]))
+		}
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
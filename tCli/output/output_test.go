@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	fields := []string{"note_id", "content", "duration", "completed", "category"}
+	records := []Record{
+		{"note_id": "n1", "content": "Run", "duration": 1800, "completed": true, "category": "Sport"},
+		{"note_id": "n2", "content": "Read,\n\"slowly\"", "duration": 0, "completed": false, "category": nil},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		fields  []string
+		records []Record
+		want    string
+	}{
+		{
+			name:    "json keeps the field order",
+			format:  FormatJson,
+			fields:  fields,
+			records: records,
+			want: "[\n" +
+				`  {"note_id":"n1","content":"Run","duration":1800,"completed":true,"category":"Sport"},` + "\n" +
+				`  {"note_id":"n2","content":"Read,\n\"slowly\"","duration":0,"completed":false,"category":null}` + "\n" +
+				"]\n",
+		},
+		{
+			name:   "json without records",
+			format: FormatJson,
+			fields: fields,
+			want:   "[]\n",
+		},
+		{
+			name:    "ndjson",
+			format:  FormatNdjson,
+			fields:  []string{"content", "note_id"},
+			records: records,
+			want: `{"content":"Run","note_id":"n1"}` + "\n" +
+				`{"content":"Read,\n\"slowly\"","note_id":"n2"}` + "\n",
+		},
+		{
+			name:   "ndjson without records",
+			format: FormatNdjson,
+			fields: fields,
+			want:   "",
+		},
+		{
+			name:    "csv quotes commas, quotes and newlines",
+			format:  FormatCsv,
+			fields:  fields,
+			records: records,
+			want: "note_id,content,duration,completed,category\n" +
+				"n1,Run,1800,true,Sport\n" +
+				"n2,\"Read,\n\"\"slowly\"\"\",0,false,\n",
+		},
+		{
+			name:   "csv without records still has the header",
+			format: FormatCsv,
+			fields: []string{"note_id"},
+			want:   "note_id\n",
+		},
+		{
+			name:    "table aligns columns and flattens newlines",
+			format:  FormatTable,
+			fields:  []string{"note_id", "content", "completed"},
+			records: records,
+			want: "NOTE_ID  CONTENT         COMPLETED\n" +
+				"n1       Run             true\n" +
+				"n2       Read, \"slowly\"  false\n",
+		},
+		{
+			name:    "a field missing from the record is empty",
+			format:  FormatTable,
+			fields:  []string{"note_id", "unknown"},
+			records: records[:1],
+			want: "NOTE_ID  UNKNOWN\n" +
+				"n1       \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Write(&b, tt.format, tt.fields, tt.records); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Write(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range Formats {
+		if err := CheckFormat(format); err != nil {
+			t.Errorf("CheckFormat(%q) = %v", format, err)
+		}
+	}
+
+	err := CheckFormat("yaml")
+	if err == nil || !strings.Contains(err.Error(), "table, json, csv, ndjson") {
+		t.Errorf("CheckFormat(yaml) = %v, want the known formats listed", err)
+	}
+	if err := Write(&bytes.Buffer{}, "yaml", nil, nil); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+}
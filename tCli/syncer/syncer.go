@@ -0,0 +1,196 @@
+package syncer
+
+import (
+	"fmt"
+	"halo/client"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// pushBatchSize stays below the note_service limit for one sync request
+const pushBatchSize = 500
+
+type Result struct {
+	Pushed    int
+	Pulled    int
+	Deleted   int
+	Conflicts int
+	// Rejected notes are refused by the server, e.g. invalid_note, they stay
+	// queued and unsynced until they are edited
+	Rejected int
+}
+
+// Run pushes local changes first, so the pull that follows already reflects them
+func Run(sessionToken string) (Result, error) {
+	pushed, err := Push(sessionToken)
+	if err != nil {
+		return pushed, err
+	}
+
+	pulled, err := Pull(sessionToken)
+	pulled.Pushed = pushed.Pushed
+	pulled.Conflicts += pushed.Conflicts
+	pulled.Rejected = pushed.Rejected
+	return pulled, err
+}
+
+func Push(sessionToken string) (Result, error) {
+	var result Result
+
+	changes, err := localstore.GetPendingChanges()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get pending changes")
+		return result, fmt.Errorf("get pending changes: %w", err)
+	}
+
+	for start := 0; start < len(changes); start += pushBatchSize {
+		end := min(start+pushBatchSize, len(changes))
+
+		batch, err := pushBatch(sessionToken, changes[start:end])
+		result.Pushed += batch.Pushed
+		result.Conflicts += batch.Conflicts
+		result.Rejected += batch.Rejected
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func pushBatch(sessionToken string, changes []models.PendingChange) (Result, error) {
+	var result Result
+
+	request := models.NoteSyncRequest{
+		Notes:   make([]models.NoteStruct, 0),
+		Deleted: make([]models.NoteTombstone, 0),
+	}
+	changedAt := make(map[uuid.UUID]int64, len(changes))
+
+	for _, change := range changes {
+		changedAt[change.Note_id] = change.Changed_at
+
+		switch change.Op {
+		case localstore.ChangeDelete:
+			request.Deleted = append(request.Deleted, models.NoteTombstone{
+				Note_id:    change.Note_id,
+				Deleted_at: change.Changed_at,
+			})
+		case localstore.ChangeUpsert:
+			note, found, err := localstore.GetNoteLocally(change.Note_id)
+			if err != nil {
+				return result, fmt.Errorf("get local note: %w", err)
+			}
+			if !found {
+				_ = localstore.ClearPendingChange(change.Note_id, change.Changed_at)
+				continue
+			}
+			if note.Updated_at == 0 {
+				note.Updated_at = note.Created_at
+			}
+			request.Notes = append(request.Notes, note)
+		}
+	}
+
+	if len(request.Notes) == 0 && len(request.Deleted) == 0 {
+		return result, nil
+	}
+
+	response, err := client.PushNoteChanges(sessionToken, request)
+	if err != nil {
+		return result, fmt.Errorf("push note changes: %w", err)
+	}
+
+	for _, noteId := range response.Applied {
+		if err := localstore.ClearPendingChange(noteId, changedAt[noteId]); err != nil {
+			logger.Logger.Error().Err(err).Msg("clear pending change")
+		}
+		result.Pushed++
+	}
+
+	for _, conflict := range response.Conflicts {
+		result.Conflicts++
+
+		var err error
+		switch {
+		case conflict.Note != nil:
+			// the server copy is newer, it replaces the local one
+			_, err = localstore.ApplyRemoteNote(*conflict.Note)
+		case conflict.Reason == "deleted":
+			_, err = localstore.ApplyRemoteDelete(conflict.Note_id, changedAt[conflict.Note_id])
+		default:
+			// clearing the change would show the note as synced while the server
+			// never took it, it stays queued and halo sync reports it
+			logger.Logger.Error().
+				Str("note_id", conflict.Note_id.String()).
+				Str("reason", conflict.Reason).
+				Msg("note can not be synced")
+			result.Rejected++
+		}
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("resolve sync conflict")
+		}
+	}
+
+	return result, nil
+}
+
+func Pull(sessionToken string) (Result, error) {
+	var result Result
+
+	stored, err := localstore.GetSyncMeta(localstore.MetaChangesCursor)
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get changes cursor")
+		return result, fmt.Errorf("get changes cursor: %w", err)
+	}
+
+	var cursor int64
+	if stored != "" {
+		cursor, _ = strconv.ParseInt(stored, 10, 64)
+	}
+
+	// the feed comes in pages, the cursor is saved after each one so an
+	// interrupted pull goes on from there
+	for {
+		changes, err := client.GetNoteChanges(sessionToken, cursor)
+		if err != nil {
+			return result, fmt.Errorf("get note changes: %w", err)
+		}
+
+		for _, note := range changes.Notes {
+			applied, err := localstore.ApplyRemoteNote(note)
+			if err != nil {
+				return result, fmt.Errorf("apply remote note: %w", err)
+			}
+			if applied {
+				result.Pulled++
+			}
+		}
+
+		for _, tombstone := range changes.Deleted {
+			deleted, err := localstore.ApplyRemoteDelete(tombstone.Note_id, tombstone.Deleted_at)
+			if err != nil {
+				return result, fmt.Errorf("apply remote delete: %w", err)
+			}
+			if deleted {
+				result.Deleted++
+			}
+		}
+
+		cursor = changes.Cursor
+		err = localstore.SetSyncMeta(localstore.MetaChangesCursor, strconv.FormatInt(cursor, 10))
+		if err != nil {
+			return result, fmt.Errorf("set changes cursor: %w", err)
+		}
+
+		if !changes.Has_more {
+			break
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,257 @@
+package syncer
+
+import (
+	"encoding/json"
+	"halo/client"
+	"halo/config"
+	"halo/localstore"
+	"halo/models"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeNoteService answers the sync routes of note_service from canned data
+type fakeNoteService struct {
+	mu       sync.Mutex
+	pushed   []models.NoteSyncRequest
+	cursors  []string
+	response models.NoteSyncResponse
+	changes  models.NoteChanges
+	// pages are served one per request before changes
+	pages     []models.NoteChanges
+	pullError bool
+}
+
+func (f *fakeNoteService) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result any
+	switch r.Method + " " + r.URL.Path {
+	case "POST /api/note/sync":
+		var request models.NoteSyncRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		f.pushed = append(f.pushed, request)
+		result = f.response
+	case "GET /api/note/changes":
+		if f.pullError {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		f.cursors = append(f.cursors, r.URL.Query().Get("cursor"))
+		result = f.changes
+		if len(f.pages) > 0 {
+			result = f.pages[0]
+			f.pages = f.pages[1:]
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// setup opens a fresh local store in a temporary home and points the client at the fake
+func setup(t *testing.T) *fakeNoteService {
+	t.Helper()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HALO_SERVER", "")
+	if err := config.Init(""); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	localstore.GetLocalDbConnection()
+	if _, _, err := localstore.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	fake := &fakeNoteService{
+		response: models.NoteSyncResponse{Applied: []uuid.UUID{}, Conflicts: []models.NoteSyncConflict{}},
+		changes:  models.NoteChanges{Notes: []models.NoteStruct{}, Deleted: []models.NoteTombstone{}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	_, profile := config.ActiveProfile()
+	profile.Server = server.URL
+	if err := client.Init(profile); err != nil {
+		t.Fatalf("client.Init: %v", err)
+	}
+
+	return fake
+}
+
+func addPending(t *testing.T, content string, updatedAt int) models.NoteStruct {
+	t.Helper()
+
+	note := models.NoteStruct{Id: uuid.New(), Content: content, Created_at: 1000, Updated_at: updatedAt}
+	if err := localstore.AddNoteLocally(note); err != nil {
+		t.Fatalf("AddNoteLocally: %v", err)
+	}
+	if err := localstore.QueueChange(note.Id, localstore.ChangeUpsert, int64(updatedAt)); err != nil {
+		t.Fatalf("QueueChange: %v", err)
+	}
+	return note
+}
+
+func pending(t *testing.T) int {
+	t.Helper()
+
+	count, err := localstore.GetNumberOfPendingChanges()
+	if err != nil {
+		t.Fatalf("GetNumberOfPendingChanges: %v", err)
+	}
+	return count
+}
+
+func TestRun(t *testing.T) {
+	fake := setup(t)
+
+	local := addPending(t, "written offline", 1500)
+	gone := addPending(t, "deleted offline", 1200)
+	if err := localstore.DeleteNoteLocally(gone.Id.String()); err != nil {
+		t.Fatalf("DeleteNoteLocally: %v", err)
+	}
+	if err := localstore.QueueChange(gone.Id, localstore.ChangeDelete, 1300); err != nil {
+		t.Fatalf("QueueChange: %v", err)
+	}
+
+	remote := models.NoteStruct{Id: uuid.New(), Content: "from another device", Created_at: 900, Updated_at: 900}
+	removed := addPending(t, "deleted on another device", 800)
+	localstore.ClearPendingChange(removed.Id, 800)
+
+	fake.response.Applied = []uuid.UUID{local.Id, gone.Id}
+	fake.changes = models.NoteChanges{
+		Notes:   []models.NoteStruct{remote},
+		Deleted: []models.NoteTombstone{{Note_id: removed.Id, Deleted_at: 950}},
+		Cursor:  7,
+	}
+
+	result, err := Run("token")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result != (Result{Pushed: 2, Pulled: 1, Deleted: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+
+	if len(fake.pushed) != 1 {
+		t.Fatalf("pushed %d batches, want 1", len(fake.pushed))
+	}
+	batch := fake.pushed[0]
+	if len(batch.Notes) != 1 || batch.Notes[0].Id != local.Id || batch.Notes[0].Content != "written offline" {
+		t.Errorf("pushed notes = %+v", batch.Notes)
+	}
+	if len(batch.Deleted) != 1 || batch.Deleted[0] != (models.NoteTombstone{Note_id: gone.Id, Deleted_at: 1300}) {
+		t.Errorf("pushed deletes = %+v", batch.Deleted)
+	}
+	if count := pending(t); count != 0 {
+		t.Errorf("%d changes pending after the push", count)
+	}
+
+	if note, found, _ := localstore.GetNoteLocally(remote.Id); !found || note.Content != remote.Content {
+		t.Errorf("remote note = %+v, %v", note, found)
+	}
+	if _, found, _ := localstore.GetNoteLocally(removed.Id); found {
+		t.Error("a note deleted on another device is still stored")
+	}
+
+	// the next pull continues after the cursor of the server, not after a client clock
+	fake.changes = models.NoteChanges{Notes: []models.NoteStruct{}, Deleted: []models.NoteTombstone{}, Cursor: 7}
+	if _, err := Pull("token"); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if len(fake.cursors) != 2 || fake.cursors[0] != "0" || fake.cursors[1] != "7" {
+		t.Errorf("pulled with cursors %v, want [0 7]", fake.cursors)
+	}
+}
+
+func TestPushConflicts(t *testing.T) {
+	fake := setup(t)
+
+	older := addPending(t, "older local edit", 1500)
+	deleted := addPending(t, "deleted on the server", 1500)
+	foreign := addPending(t, "id of another user", 1500)
+	invalid := addPending(t, "ended before it was created", 1500)
+
+	newer := older
+	newer.Content = "newer server copy"
+	newer.Updated_at = 2000
+
+	fake.response.Conflicts = []models.NoteSyncConflict{
+		{Note_id: older.Id, Reason: "newer_on_server", Note: &newer},
+		{Note_id: deleted.Id, Reason: "deleted"},
+		{Note_id: foreign.Id, Reason: "foreign_note"},
+		{Note_id: invalid.Id, Reason: "invalid_note"},
+	}
+
+	result, err := Push("token")
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if result != (Result{Conflicts: 4, Rejected: 2}) {
+		t.Errorf("result = %+v", result)
+	}
+
+	if note, _, _ := localstore.GetNoteLocally(older.Id); note.Content != "newer server copy" {
+		t.Errorf("note with a newer server copy = %+v", note)
+	}
+	if _, found, _ := localstore.GetNoteLocally(deleted.Id); found {
+		t.Error("a note deleted on the server is still stored")
+	}
+	// the refused notes are pushed again by the next sync, the others are settled
+	if count := pending(t); count != 2 {
+		t.Errorf("%d changes pending after the conflicts, want 2", count)
+	}
+	if note, found, _ := localstore.GetNoteLocally(invalid.Id); !found || note.Synced {
+		t.Errorf("refused note = %+v, %v, want it stored and unsynced", note, found)
+	}
+}
+
+func TestPullPages(t *testing.T) {
+	fake := setup(t)
+
+	first := models.NoteStruct{Id: uuid.New(), Content: "first page", Created_at: 900, Updated_at: 900}
+	second := models.NoteStruct{Id: uuid.New(), Content: "second page", Created_at: 900, Updated_at: 900}
+	fake.pages = []models.NoteChanges{
+		{Notes: []models.NoteStruct{first}, Deleted: []models.NoteTombstone{}, Cursor: 3, Has_more: true},
+		{Notes: []models.NoteStruct{second}, Deleted: []models.NoteTombstone{}, Cursor: 4},
+	}
+
+	result, err := Pull("token")
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if result.Pulled != 2 {
+		t.Errorf("pulled %d notes, want 2", result.Pulled)
+	}
+	if len(fake.cursors) != 2 || fake.cursors[0] != "0" || fake.cursors[1] != "3" {
+		t.Errorf("pulled with cursors %v, want [0 3]", fake.cursors)
+	}
+	if cursor, _ := localstore.GetSyncMeta(localstore.MetaChangesCursor); cursor != "4" {
+		t.Errorf("cursor = %q after the pull, want 4", cursor)
+	}
+}
+
+func TestPullErrorKeepsCursor(t *testing.T) {
+	fake := setup(t)
+
+	if err := localstore.SetSyncMeta(localstore.MetaChangesCursor, "5"); err != nil {
+		t.Fatalf("SetSyncMeta: %v", err)
+	}
+	fake.pullError = true
+
+	if _, err := Pull("token"); err == nil {
+		t.Fatal("Pull succeeded against a failing server")
+	}
+	if cursor, _ := localstore.GetSyncMeta(localstore.MetaChangesCursor); cursor != "5" {
+		t.Errorf("cursor = %q after a failed pull, want 5", cursor)
+	}
+}
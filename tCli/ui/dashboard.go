@@ -0,0 +1,303 @@
+package ui
+
+import (
+	"fmt"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+)
+
+const (
+	heatmapWeeks = 53
+	cellWidth    = 2
+)
+
+// heat levels from no completed notes to four and more, same palette as the paginator dots
+var heatStyles = []lipgloss.Style{
+	lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"}),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("22")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("28")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("34")),
+	lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+}
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"})
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "235", Dark: "252"})
+	headerStyle    = lipgloss.NewStyle().Bold(true)
+	mutedStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"})
+)
+
+// dashboardTab is one category, the first tab with uuid.Nil shows all notes
+type dashboardTab struct {
+	id   uuid.UUID
+	name string
+}
+
+type dashboardModel struct {
+	notes   []models.NoteStruct
+	tabs    []dashboardTab
+	current int
+	stats   habitStats
+	now     time.Time
+	width   int
+	err     string
+}
+
+func newDashboardModel() dashboardModel {
+	m := dashboardModel{width: cellWidth*heatmapWeeks + 4}
+	m.reload()
+	return m
+}
+
+// reload reads the notes of the heatmap period and the cached categories
+func (m *dashboardModel) reload() {
+	m.now = time.Now()
+	m.err = ""
+
+	today := time.Date(m.now.Year(), m.now.Month(), m.now.Day(), 0, 0, 0, 0, m.now.Location())
+	from := startOfWeek(today).AddDate(0, 0, -7*(heatmapWeeks-1))
+
+	notes, err := localstore.QueryNotes(models.NoteFilter{From: from.Unix()})
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("query notes")
+		m.err = err.Error()
+	}
+	m.notes = notes
+
+	categories, err := localstore.GetCategoriesLocally()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get categories")
+	}
+
+	m.tabs = []dashboardTab{{id: uuid.Nil, name: i18n.T("dashboard.all")}}
+	known := make(map[uuid.UUID]bool)
+	for _, category := range categories {
+		m.tabs = append(m.tabs, dashboardTab{id: category.Id, name: category.Name})
+		known[category.Id] = true
+	}
+	// categories missing in the cache still get a tab
+	for _, note := range notes {
+		if note.Category_id != uuid.Nil && !known[note.Category_id] {
+			m.tabs = append(m.tabs, dashboardTab{id: note.Category_id, name: note.Category_id.String()[:8]})
+			known[note.Category_id] = true
+		}
+	}
+
+	if m.current >= len(m.tabs) {
+		m.current = 0
+	}
+	m.refreshStats()
+}
+
+func (m *dashboardModel) refreshStats() {
+	m.stats = computeHabitStats(filterCategory(m.notes, m.tabs[m.current].id), m.now)
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "right", "l", "tab":
+			m.current = (m.current + 1) % len(m.tabs)
+			m.refreshStats()
+		case "left", "h", "shift+tab":
+			m.current = (m.current - 1 + len(m.tabs)) % len(m.tabs)
+			m.refreshStats()
+		case "r":
+			m.reload()
+		}
+	}
+	return m, nil
+}
+
+func (m dashboardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(i18n.T("dashboard.manual") + "\n\n")
+
+	tabs := make([]string, 0, len(m.tabs))
+	for i, tab := range m.tabs {
+		if i == m.current {
+			tabs = append(tabs, activeTabStyle.Render("["+tab.name+"]"))
+		} else {
+			tabs = append(tabs, tabStyle.Render(tab.name))
+		}
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tabs...) + "\n\n")
+
+	if m.err != "" {
+		b.WriteString(statusStyle(statusFailed).Render(i18n.T("dashboard.notes_unavailable", m.err)) + "\n\n")
+	}
+
+	b.WriteString(m.heatmapView() + "\n")
+	b.WriteString(m.streakView() + "\n")
+	b.WriteString(m.todayView() + "\n")
+	b.WriteString(m.weekView() + "\n")
+
+	return b.String()
+}
+
+// heatmapView draws one column per week and one row per weekday, the newest week
+// is on the right; narrow terminals get fewer weeks
+func (m dashboardModel) heatmapView() string {
+	weeks := heatmapWeeks
+	if fit := (m.width - 4) / cellWidth; fit < weeks {
+		weeks = max(fit, 1)
+	}
+
+	today := time.Date(m.now.Year(), m.now.Month(), m.now.Day(), 0, 0, 0, 0, m.now.Location())
+	first := startOfWeek(today).AddDate(0, 0, -7*(weeks-1))
+
+	var b strings.Builder
+
+	// month labels above the first week of each month, counted in runes for the cyrillic names
+	names := strings.Fields(i18n.T("dashboard.months"))
+	months := make([]rune, 0, weeks*cellWidth)
+	for w := 0; w < weeks; w++ {
+		day := first.AddDate(0, 0, 7*w)
+		if day.Day() <= 7 && len(months) <= w*cellWidth {
+			months = append(months, []rune(names[day.Month()-1])...)
+		}
+		for len(months) < (w+1)*cellWidth {
+			months = append(months, ' ')
+		}
+	}
+	b.WriteString("    " + mutedStyle.Render(strings.TrimRight(string(months), " ")) + "\n")
+
+	// every other weekday is labelled, monday, wednesday and friday
+	labels := make([]string, 7)
+	for i, name := range strings.Fields(i18n.T("dashboard.weekdays")) {
+		labels[2*i] = name
+	}
+	for weekday := 0; weekday < 7; weekday++ {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%-4s", labels[weekday])))
+		for w := 0; w < weeks; w++ {
+			day := first.AddDate(0, 0, 7*w+weekday)
+			if day.After(today) {
+				break
+			}
+			level := min(m.stats.done[day.Format(dayLayout)], len(heatStyles)-1)
+			b.WriteString(heatStyles[level].Render("■") + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	legend := "    " + i18n.T("dashboard.less") + " "
+	for _, style := range heatStyles {
+		legend += style.Render("■") + " "
+	}
+	b.WriteString(mutedStyle.Render(legend+i18n.T("dashboard.more")) + "\n")
+
+	return b.String()
+}
+
+func (m dashboardModel) streakView() string {
+	return fmt.Sprintf(
+		"%s %s   %s %s\n",
+		headerStyle.Render(i18n.T("dashboard.current_streak")),
+		i18n.N("duration.days", m.stats.currentStreak),
+		headerStyle.Render(i18n.T("dashboard.longest_streak")),
+		i18n.N("duration.days", m.stats.longestStreak),
+	)
+}
+
+// todayView lists the open notes of today, on the "All" tab also the
+// categories which have no completed note today
+func (m dashboardModel) todayView() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(i18n.T("dashboard.today")) + "\n")
+
+	today := m.now.Format(dayLayout)
+	empty := true
+
+	if m.tabs[m.current].id == uuid.Nil {
+		for _, tab := range m.tabs[1:] {
+			notes := filterCategory(m.notes, tab.id)
+			if len(notes) == 0 {
+				continue
+			}
+			if computeHabitStats(notes, m.now).done[today] == 0 {
+				b.WriteString("  ○ " + tab.name + "\n")
+				empty = false
+			}
+		}
+	}
+
+	for _, note := range m.stats.pendingToday {
+		line := "  ❌ " + note.Content
+		if m.tabs[m.current].id == uuid.Nil {
+			if name := m.tabName(note.Category_id); name != "" {
+				line += " #" + name
+			}
+		}
+		b.WriteString(line + "\n")
+		empty = false
+	}
+
+	if empty {
+		if m.stats.done[today] > 0 {
+			b.WriteString(statusStyle(statusDeleted).Render("  "+i18n.T("dashboard.all_done")) + "\n")
+		} else {
+			b.WriteString(mutedStyle.Render("  "+i18n.T("dashboard.nothing_planned")) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+func (m dashboardModel) weekView() string {
+	line := func(title string, week weekSummary) string {
+		return fmt.Sprintf("  %-10s %s\n", title, i18n.T(
+			"dashboard.week_line",
+			week.done,
+			week.pending,
+			week.days,
+			formatTracked(week.tracked),
+		))
+	}
+
+	return headerStyle.Render(i18n.T("dashboard.week")) + "\n" +
+		line(i18n.T("dashboard.this_week"), m.stats.week) +
+		line(i18n.T("dashboard.last_week"), m.stats.lastWeek)
+}
+
+func (m dashboardModel) tabName(categoryId uuid.UUID) string {
+	for _, tab := range m.tabs[1:] {
+		if tab.id == categoryId {
+			return tab.name
+		}
+	}
+	return ""
+}
+
+func formatTracked(d time.Duration) string {
+	if d < time.Hour {
+		return i18n.T("dashboard.tracked_minutes", int(d.Minutes()))
+	}
+	return i18n.T("dashboard.tracked_hours", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func StartDashboard() error {
+	p := tea.NewProgram(newDashboardModel())
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"halo/client"
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"halo/syncer"
+	"halo/utils"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+type selectorMode int
+
+const (
+	modeList selectorMode = iota
+	modeConfirmDelete
+	modeEditContent
+	modeEditEnd
+	modePickCategory
+	modeSearch
+)
+
+const (
+	statusSaving = "status.saving"
+	statusSaved  = "status.saved"
+	statusLocal  = "status.saved_locally"
+	statusServer = "status.server_kept"
+)
+
+type noteSavedMsg struct {
+	id     string
+	status string
+	note   *models.NoteStruct
+}
+
+type categoriesMsg struct {
+	categories []models.CategoryStruct
+	err        error
+}
+
+func newInput() textinput.Model {
+	input := textinput.New()
+	input.CharLimit = 512
+	input.Width = 60
+	return input
+}
+
+// pushNote sends the queued change of the note to the server, the note is
+// already saved locally so a failed push only leaves it queued for the next sync
+func pushNote(token string, noteId uuid.UUID) tea.Cmd {
+	return func() tea.Msg {
+		id := noteId.String()
+
+		if token == "" {
+			return noteSavedMsg{id: id, status: statusLocal}
+		}
+
+		if _, err := syncer.Push(token); err != nil {
+			logger.Logger.Error().Err(err).Msg("push note changes")
+			return noteSavedMsg{id: id, status: statusLocal}
+		}
+
+		note, found, err := localstore.GetNoteLocally(noteId)
+		if err != nil || !found {
+			return noteSavedMsg{id: id, status: statusSaved}
+		}
+		if !note.Synced {
+			return noteSavedMsg{id: id, status: statusLocal, note: &note}
+		}
+		return noteSavedMsg{id: id, status: statusSaved, note: &note}
+	}
+}
+
+// loadCategories prefers the server list and falls back to the local cache
+func loadCategories(token string) tea.Cmd {
+	return func() tea.Msg {
+		if token != "" {
+			categories, err := client.GetCategories(token)
+			if err == nil {
+				if err := localstore.SaveCategoriesLocally(categories); err != nil {
+					logger.Logger.Error().Err(err).Msg("save categories")
+				}
+				return categoriesMsg{categories: categories}
+			}
+			logger.Logger.Error().Err(err).Msg("get categories")
+		}
+
+		categories, err := localstore.GetCategoriesLocally()
+		if err == nil && len(categories) == 0 {
+			err = errors.New(i18n.T("edit.no_cached_categories"))
+		}
+		return categoriesMsg{categories: categories, err: err}
+	}
+}
+
+// saveNote stores the edited note at the cursor and queues it for the server
+func (m *model) saveNote(note models.NoteStruct) tea.Cmd {
+	// updated_at has a one second resolution, an edit must still win over the previous version
+	note.Updated_at = max(int(time.Now().Unix()), note.Updated_at+1)
+
+	if err := localstore.UpdateNoteLocally(note); err != nil {
+		logger.Logger.Error().Err(err).Msg("local update note")
+		m.status[note.Id.String()] = statusFailed + err.Error()
+		return nil
+	}
+
+	if err := localstore.QueueChange(note.Id, localstore.ChangeUpsert, int64(note.Updated_at)); err != nil {
+		logger.Logger.Error().Err(err).Msg("queue note change")
+		m.status[note.Id.String()] = statusFailed + err.Error()
+		return nil
+	}
+
+	note.Synced = false
+	m.Notes[m.cursor] = note
+	m.status[note.Id.String()] = statusSaving
+
+	return pushNote(m.token, note.Id)
+}
+
+func (m *model) startEdit(mode selectorMode) tea.Cmd {
+	note := m.Notes[m.cursor]
+
+	m.input = newInput()
+	m.inputErr = ""
+
+	switch mode {
+	case modeEditContent:
+		m.input.Placeholder = i18n.T("edit.content_placeholder")
+		m.input.SetValue(note.Content)
+	case modeEditEnd:
+		m.input.Placeholder = i18n.T("edit.end_placeholder")
+		if note.Ended_at != 0 {
+			m.input.SetValue(time.Unix(int64(note.Ended_at), 0).Format("02.01 15:04"))
+		}
+	}
+
+	m.mode = mode
+	return m.input.Focus()
+}
+
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.mode = modeList
+		return m, nil
+	case "enter":
+		note := m.Notes[m.cursor]
+		value := strings.TrimSpace(m.input.Value())
+
+		switch m.mode {
+		case modeEditContent:
+			if value == "" {
+				m.inputErr = i18n.T("edit.content_empty")
+				return m, nil
+			}
+			note.Content = value
+		case modeEditEnd:
+			endedAt, err := utils.ParseHumanTime(value)
+			if err != nil {
+				m.inputErr = err.Error()
+				return m, nil
+			}
+			if endedAt != 0 && endedAt < int64(note.Created_at) {
+				m.inputErr = i18n.T("edit.end_before_created")
+				return m, nil
+			}
+			note.Ended_at = int(endedAt)
+		}
+
+		m.mode = modeList
+		return m, m.saveNote(note)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// the first row of the picker clears the category
+	rows := len(m.categories) + 1
+
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.mode = modeList
+	case "up", "k":
+		if m.pickerCursor > 0 {
+			m.pickerCursor--
+		}
+	case "down", "j":
+		if m.pickerCursor < rows-1 {
+			m.pickerCursor++
+		}
+	case "enter":
+		if m.categoriesErr != "" || m.categories == nil {
+			return m, nil
+		}
+
+		note := m.Notes[m.cursor]
+		note.Category_id = uuid.Nil
+		if m.pickerCursor > 0 {
+			note.Category_id = m.categories[m.pickerCursor-1].Id
+		}
+
+		m.mode = modeList
+		return m, m.saveNote(note)
+	}
+
+	return m, nil
+}
+
+func (m model) categoryName(categoryId uuid.UUID) string {
+	for _, category := range m.categories {
+		if category.Id == categoryId {
+			return category.Name
+		}
+	}
+	return ""
+}
+
+func (m model) editView() string {
+	var b strings.Builder
+
+	switch m.mode {
+	case modeEditContent:
+		b.WriteString("\n" + i18n.T("edit.content_title") + "\n")
+		b.WriteString(m.input.View() + "\n")
+	case modeEditEnd:
+		b.WriteString("\n" + i18n.T("edit.end_title") + "\n")
+		b.WriteString(m.input.View() + "\n")
+	case modePickCategory:
+		b.WriteString("\n" + i18n.T("edit.category_title") + "\n")
+		switch {
+		case m.categoriesErr != "":
+			b.WriteString(statusStyle(statusFailed).Render(i18n.T("edit.categories_unavailable", m.categoriesErr)) + "\n")
+		case m.categories == nil:
+			b.WriteString(i18n.T("edit.categories_loading") + "\n")
+		default:
+			names := []string{i18n.T("edit.no_category")}
+			for _, category := range m.categories {
+				names = append(names, category.Name)
+			}
+			for i, name := range names {
+				cursor := " "
+				if i == m.pickerCursor {
+					cursor = ">"
+				}
+				b.WriteString(fmt.Sprintf("%s %s\n", cursor, name))
+			}
+		}
+	}
+
+	if m.inputErr != "" {
+		b.WriteString(statusStyle(statusFailed).Render(m.inputErr) + "\n")
+	}
+
+	return b.String()
+}
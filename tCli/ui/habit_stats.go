@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"halo/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dayLayout = "2006-01-02"
+
+// habitStats sums up the notes of one category (or of all of them) by local day,
+// a day counts for a streak when at least one note of it is completed
+type habitStats struct {
+	done          map[string]int
+	currentStreak int
+	longestStreak int
+	pendingToday  []models.NoteStruct
+	week          weekSummary
+	lastWeek      weekSummary
+}
+
+type weekSummary struct {
+	done    int
+	pending int
+	tracked time.Duration
+	days    int
+}
+
+func dayOf(unix int, loc *time.Location) time.Time {
+	t := time.Unix(int64(unix), 0).In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the monday of the week of the day
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// filterCategory keeps the notes of the category, uuid.Nil keeps all notes
+func filterCategory(notes []models.NoteStruct, categoryId uuid.UUID) []models.NoteStruct {
+	if categoryId == uuid.Nil {
+		return notes
+	}
+	filtered := make([]models.NoteStruct, 0)
+	for _, note := range notes {
+		if note.Category_id == categoryId {
+			filtered = append(filtered, note)
+		}
+	}
+	return filtered
+}
+
+func computeHabitStats(notes []models.NoteStruct, now time.Time) habitStats {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisWeek := startOfWeek(today)
+	previousWeek := thisWeek.AddDate(0, 0, -7)
+
+	stats := habitStats{done: make(map[string]int)}
+
+	var first time.Time
+	for _, note := range notes {
+		day := dayOf(note.Created_at, now.Location())
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+
+		if note.Completed {
+			stats.done[day.Format(dayLayout)]++
+		} else if day.Equal(today) {
+			stats.pendingToday = append(stats.pendingToday, note)
+		}
+
+		var week *weekSummary
+		switch {
+		case !day.Before(thisWeek) && !day.After(today):
+			week = &stats.week
+		case !day.Before(previousWeek) && day.Before(thisWeek):
+			week = &stats.lastWeek
+		default:
+			continue
+		}
+		if note.Completed {
+			week.done++
+		} else {
+			week.pending++
+		}
+		if note.Ended_at > note.Created_at {
+			week.tracked += time.Duration(note.Ended_at-note.Created_at) * time.Second
+		}
+	}
+
+	for day := thisWeek; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if stats.done[day.Format(dayLayout)] > 0 {
+			stats.week.days++
+		}
+	}
+	for day := previousWeek; day.Before(thisWeek); day = day.AddDate(0, 0, 1) {
+		if stats.done[day.Format(dayLayout)] > 0 {
+			stats.lastWeek.days++
+		}
+	}
+
+	if first.IsZero() {
+		return stats
+	}
+
+	run := 0
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if stats.done[day.Format(dayLayout)] > 0 {
+			run++
+			stats.longestStreak = max(stats.longestStreak, run)
+		} else {
+			run = 0
+		}
+	}
+
+	// a streak is not broken yet while today is still open
+	day := today
+	if stats.done[day.Format(dayLayout)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for stats.done[day.Format(dayLayout)] > 0 {
+		stats.currentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return stats
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"halo/models"
+	"testing"
+	"time"
+)
+
+func TestComputeHabitStats(t *testing.T) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	// Wednesday
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, zone)
+
+	note := func(month time.Month, day, hour int, completed bool) models.NoteStruct {
+		return models.NoteStruct{
+			Created_at: int(time.Date(2025, month, day, hour, 0, 0, 0, zone).Unix()),
+			Completed:  completed,
+		}
+	}
+
+	notes := []models.NoteStruct{
+		// a four day streak at the end of february
+		note(time.February, 24, 9, true),
+		note(time.February, 25, 9, true),
+		note(time.February, 26, 9, true),
+		note(time.February, 27, 9, true),
+		// last week
+		note(time.March, 8, 9, true),
+		note(time.March, 9, 9, true),
+		// this week, today is still open
+		note(time.March, 10, 9, true),
+		note(time.March, 10, 20, true),
+		note(time.March, 11, 9, true),
+		note(time.March, 12, 9, false),
+	}
+
+	stats := computeHabitStats(notes, now)
+
+	if stats.currentStreak != 4 {
+		t.Errorf("currentStreak = %d, want 4", stats.currentStreak)
+	}
+	if stats.longestStreak != 4 {
+		t.Errorf("longestStreak = %d, want 4", stats.longestStreak)
+	}
+	if stats.done["2025-03-10"] != 2 {
+		t.Errorf("done on 2025-03-10 = %d, want 2", stats.done["2025-03-10"])
+	}
+	if len(stats.pendingToday) != 1 {
+		t.Errorf("pendingToday = %d notes, want 1", len(stats.pendingToday))
+	}
+	if stats.week.done != 3 || stats.week.pending != 1 || stats.week.days != 2 {
+		t.Errorf("week = %+v, want 3 done, 1 pending, 2 days", stats.week)
+	}
+	if stats.lastWeek.done != 2 || stats.lastWeek.days != 2 {
+		t.Errorf("lastWeek = %+v, want 2 done, 2 days", stats.lastWeek)
+	}
+
+	// completing today extends the streak
+	notes[len(notes)-1].Completed = true
+	if got := computeHabitStats(notes, now).currentStreak; got != 5 {
+		t.Errorf("currentStreak with today done = %d, want 5", got)
+	}
+}
+
+func TestComputeHabitStats_Broken(t *testing.T) {
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, time.UTC)
+	notes := []models.NoteStruct{
+		{Created_at: int(time.Date(2025, time.March, 9, 9, 0, 0, 0, time.UTC).Unix()), Completed: true},
+	}
+
+	stats := computeHabitStats(notes, now)
+	if stats.currentStreak != 0 || stats.longestStreak != 1 {
+		t.Errorf("streaks = %d/%d, want 0/1", stats.currentStreak, stats.longestStreak)
+	}
+
+	if empty := computeHabitStats(nil, now); empty.currentStreak != 0 || empty.longestStreak != 0 {
+		t.Errorf("empty streaks = %d/%d, want 0/0", empty.currentStreak, empty.longestStreak)
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"halo/i18n"
+	"halo/localstore"
+	"halo/logger"
+	"halo/models"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// at most this many matches are kept for paging through search results
+const searchLimit = 500
+
+// loadPage reads a page of all notes, or of the search results while searching
+func (m model) loadPage(page int) []models.NoteStruct {
+	if m.query == "" {
+		return localstore.GetNotesLocally(page, m.paginator.PerPage)
+	}
+
+	start := min(page*m.paginator.PerPage, len(m.results))
+	end := min(start+m.paginator.PerPage, len(m.results))
+	return m.results[start:end]
+}
+
+func (m *model) startSearch() tea.Cmd {
+	m.input = newInput()
+	m.input.Placeholder = i18n.T("search.placeholder")
+	m.input.SetValue(m.query)
+	m.inputErr = ""
+	m.mode = modeSearch
+	return m.input.Focus()
+}
+
+// applySearch filters the list as the user types, an empty query shows all notes
+func (m *model) applySearch(query string) {
+	m.query = strings.TrimSpace(query)
+	m.results = nil
+	m.inputErr = ""
+	m.paginator.Page = 0
+
+	if m.query == "" {
+		total, err := localstore.GetNumberOfNotes()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("get number of notes")
+		}
+		m.total = total
+		m.reloadPage()
+		return
+	}
+
+	results, err := localstore.SearchNotes(m.query, models.NoteFilter{Limit: searchLimit})
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("search notes")
+		m.inputErr = i18n.T("search.failed", err.Error())
+	}
+	m.results = results
+	m.total = len(results)
+	m.reloadPage()
+}
+
+func (m *model) clearSearch() {
+	m.applySearch("")
+	m.mode = modeList
+}
+
+// updateResult keeps the search results in step with edits and deletions, pages are cut from them
+func (m *model) updateResult(note models.NoteStruct, deleted bool) {
+	for i := range m.results {
+		if m.results[i].Id != note.Id {
+			continue
+		}
+		if deleted {
+			m.results = append(m.results[:i:i], m.results[i+1:]...)
+		} else {
+			m.results[i] = note
+		}
+		return
+	}
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.clearSearch()
+		return m, nil
+	case "enter", "down":
+		// keep the filter and go back to the list to act on the matches
+		m.mode = modeList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if strings.TrimSpace(m.input.Value()) != m.query {
+		m.applySearch(m.input.Value())
+	}
+	return m, cmd
+}
+
+func (m model) searchView() string {
+	switch {
+	case m.mode == modeSearch:
+		return "\n" + i18n.N("search.typing", m.total) + "\n" + m.input.View() + "\n"
+	case m.query != "":
+		return "\n" + i18n.N("search.results", m.total, m.query) + "\n"
+	}
+	return ""
+}
@@ -1,15 +1,22 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"halo/client"
+	"halo/config"
+	"halo/i18n"
 	"halo/localstore"
 	"halo/logger"
 	"halo/models"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
 )
 
 type model struct {
@@ -17,9 +24,103 @@ type model struct {
 	PickedNotes []models.NoteStruct
 	cursor      int
 	checked     map[string]bool
+	status      map[string]string
+	mode        selectorMode
+	token       string
+	input       textinput.Model
+	inputErr    string
 	paginator   paginator.Model
 	total       int
 	quitting    bool
+
+	categories    []models.CategoryStruct
+	categoriesErr string
+	pickerCursor  int
+
+	query   string
+	results []models.NoteStruct
+}
+
+// statuses are catalogue keys translated when drawn, a failure is
+// statusFailed followed by the error
+const (
+	statusDeleting = "status.deleting"
+	statusDeleted  = "status.deleted"
+	statusQueued   = "status.deleted_locally"
+	statusFailed   = "failed: "
+)
+
+type noteDeletedMsg struct {
+	id     string
+	status string
+}
+
+// deleteNote removes the note on the server and locally, when the server is
+// unreachable the deletion is queued and pushed by the next sync
+func deleteNote(token string, noteId string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := uuid.Parse(noteId)
+		if err != nil {
+			return noteDeletedMsg{id: noteId, status: statusFailed + err.Error()}
+		}
+		deletedAt := time.Now().Unix()
+
+		status := statusDeleted
+		if token != "" {
+			err = client.DeleteNote(token, id)
+		} else {
+			err = errors.New(i18n.T("notes.no_session"))
+		}
+
+		// a note unknown to the server was never synced, there is nothing to delete there
+		if err != nil && !errors.Is(err, client.ErrNoteNotFound) {
+			logger.Logger.Error().Err(err).Msg("remote delete note")
+
+			if err := localstore.QueueChange(id, localstore.ChangeDelete, deletedAt); err != nil {
+				logger.Logger.Error().Err(err).Msg("queue note delete")
+				return noteDeletedMsg{id: noteId, status: statusFailed + err.Error()}
+			}
+			status = statusQueued
+		} else if err := localstore.ClearPendingChange(id, deletedAt); err != nil {
+			logger.Logger.Error().Err(err).Msg("clear pending change")
+		}
+
+		if err := localstore.DeleteNoteLocally(noteId); err != nil {
+			logger.Logger.Error().Err(err).Msg("local delete note")
+			return noteDeletedMsg{id: noteId, status: statusFailed + err.Error()}
+		}
+
+		return noteDeletedMsg{id: noteId, status: status}
+	}
+}
+
+// isDeleted reports notes which are gone or being deleted, they can not be selected or edited
+func (m model) isDeleted(id string) bool {
+	switch m.status[id] {
+	case statusDeleting, statusDeleted, statusQueued:
+		return true
+	}
+	return false
+}
+
+func (m model) numberOfChecked() int {
+	count := 0
+	for _, checked := range m.checked {
+		if checked {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *model) reloadPage() {
+	m.paginator.SetTotalPages(m.total)
+	if m.paginator.Page > m.paginator.TotalPages-1 {
+		m.paginator.OnLastPage()
+	}
+	m.Notes = m.loadPage(m.paginator.Page)
+	m.status = make(map[string]string)
+	m.cursor = 0
 }
 
 func newModel() model {
@@ -40,38 +141,140 @@ func newModel() model {
 		Render("•")
 	p.SetTotalPages(numOfNotes)
 
+	// without a token deletions are only queued until the next sync
+	token, err := config.LoadToken()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("get session token")
+	}
+
 	return model{
 		Notes:     notes,
 		cursor:    0,
 		checked:   make(map[string]bool),
+		status:    make(map[string]string),
+		token:     token,
 		paginator: p,
 		total:     numOfNotes,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	// categories are only needed for names and the picker, they load in the background
+	return loadCategories(m.token)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case noteDeletedMsg:
+		m.status[msg.id] = msg.status
+		if !strings.HasPrefix(msg.status, statusFailed) {
+			m.total--
+			m.paginator.SetTotalPages(m.total)
+			if id, err := uuid.Parse(msg.id); err == nil {
+				m.updateResult(models.NoteStruct{Id: id}, true)
+			}
+		}
+		return m, nil
+	case noteSavedMsg:
+		m.status[msg.id] = msg.status
+		if msg.note != nil {
+			for i := range m.Notes {
+				if m.Notes[i].Id == msg.note.Id {
+					if msg.note.Synced && m.Notes[i].Updated_at != msg.note.Updated_at {
+						m.status[msg.id] = statusServer
+					}
+					m.Notes[i] = *msg.note
+				}
+			}
+			m.updateResult(*msg.note, false)
+		}
+		return m, nil
+	case categoriesMsg:
+		if msg.err != nil {
+			m.categoriesErr = msg.err.Error()
+		} else {
+			m.categories = msg.categories
+		}
+		return m, nil
 	case tea.KeyMsg:
+		switch m.mode {
+		case modeEditContent, modeEditEnd:
+			return m.updateInput(msg)
+		case modePickCategory:
+			return m.updatePicker(msg)
+		case modeSearch:
+			return m.updateSearch(msg)
+		case modeConfirmDelete:
+			switch msg.String() {
+			case "y", "Y":
+				var cmds []tea.Cmd
+				for id := range m.checked {
+					if m.checked[id] {
+						m.status[id] = statusDeleting
+						cmds = append(cmds, deleteNote(m.token, id))
+					}
+				}
+				m.checked = make(map[string]bool)
+				m.mode = modeList
+				return m, tea.Batch(cmds...)
+			case "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			default:
+				m.mode = modeList
+				return m, nil
+			}
+		}
+
+		if len(m.Notes) > 0 && !m.isDeleted(m.Notes[m.cursor].Id.String()) {
+			switch msg.String() {
+			case "c":
+				note := m.Notes[m.cursor]
+				note.Completed = !note.Completed
+				return m, m.saveNote(note)
+			case "e":
+				return m, m.startEdit(modeEditContent)
+			case "t":
+				return m, m.startEdit(modeEditEnd)
+			case "g":
+				m.mode = modePickCategory
+				m.pickerCursor = 0
+				m.inputErr = ""
+				if m.categories == nil {
+					m.categoriesErr = ""
+					return m, loadCategories(m.token)
+				}
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
-		case "q", "esc", "ctrl+c":
+		case "esc":
+			if m.query != "" {
+				m.clearSearch()
+				return m, nil
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "/":
+			return m, m.startSearch()
 		case "left", "h":
 			if m.paginator.Page > 0 {
 				// -1 because of lib auto page change only after switch case
-				m.Notes = localstore.GetNotesLocally(m.paginator.Page-1, m.paginator.PerPage)
+				m.Notes = m.loadPage(m.paginator.Page - 1)
+				m.status = make(map[string]string)
 				m.cursor = 0
 			}
 		case "right", "l":
 			if m.paginator.Page < m.paginator.TotalPages-1 {
 				// +1 because of lib auto page change only after switch case
-				m.Notes = localstore.GetNotesLocally(m.paginator.Page+1, m.paginator.PerPage)
+				m.Notes = m.loadPage(m.paginator.Page + 1)
+				m.status = make(map[string]string)
 				m.cursor = 0
 			}
 		case "up", "k":
@@ -83,27 +286,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case " ":
+			if len(m.Notes) == 0 {
+				break
+			}
 			id := m.Notes[m.cursor].Id.String()
+			if m.isDeleted(id) {
+				break
+			}
 			m.checked[id] = !m.checked[id]
 		case "enter":
-			for id := range m.checked {
-				if m.checked[id] {
-					err := localstore.DeleteNoteLocally(id)
-					if err != nil {
-						logger.Logger.Error().Err(err).Msg("local delete note")
-					}
-					m.total--
-				}
+			if m.numberOfChecked() > 0 {
+				m.mode = modeConfirmDelete
 			}
-
-			m.paginator.SetTotalPages(m.total)
-			if m.paginator.Page <= m.paginator.TotalPages-1 {
-				m.Notes = localstore.GetNotesLocally(m.paginator.Page, m.paginator.PerPage)
-			} else {
-				m.paginator.OnLastPage()
-				m.Notes = localstore.GetNotesLocally(m.paginator.Page, m.paginator.PerPage)
+		case "r":
+			if m.query != "" {
+				m.applySearch(m.query)
+				break
 			}
-			m.cursor = 0
+			m.reloadPage()
 		}
 	}
 
@@ -113,13 +313,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	if m.quitting {
-		return "Exit...\n"
+		return i18n.T("notes.exit") + "\n"
 	}
 
 	var b strings.Builder
-	b.WriteString(
-		"Manual\n· ↑(k)/↓(j) - navigation\n· Space - select\n· ←(h)/→(l) - page\n· Enter - delete\n· q - exit\n\n",
-	)
+	b.WriteString(i18n.T("notes.manual") + "\n\n")
 
 	pageNotes := m.Notes
 
@@ -136,16 +334,50 @@ func (m model) View() string {
 		if t.Completed {
 			done = "✅"
 		}
-		line := fmt.Sprintf("%s [%s] %s %s\n", cursor, checked, t.Content, done)
-		b.WriteString(line)
+		line := fmt.Sprintf("%s [%s] %s %s", cursor, checked, t.Content, done)
+		if name := m.categoryName(t.Category_id); name != "" {
+			line += " #" + name
+		}
+		if t.Ended_at != 0 {
+			line += " " + i18n.T("notes.until", time.Unix(int64(t.Ended_at), 0).Format("02.01 15:04"))
+		}
+		if status, ok := m.status[t.Id.String()]; ok {
+			line += " " + statusStyle(status).Render("· "+statusText(status))
+		}
+		b.WriteString(line + "\n")
 	}
 
+	if m.mode == modeConfirmDelete {
+		b.WriteString("\n" + i18n.N("notes.confirm_delete", m.numberOfChecked()) + "\n")
+	}
+	b.WriteString(m.searchView())
+	b.WriteString(m.editView())
+
 	b.WriteString("\n" + m.paginator.View())
 	b.WriteString("\n\n")
 
 	return b.String()
 }
 
+// statusText translates the status, a failure keeps its error
+func statusText(status string) string {
+	if err, ok := strings.CutPrefix(status, statusFailed); ok {
+		return i18n.T("status.failed", err)
+	}
+	return i18n.T(status)
+}
+
+func statusStyle(status string) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(status, statusFailed):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	case status == statusDeleted, status == statusSaved:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	}
+}
+
 func StartNoteSelector() error {
 	p := tea.NewProgram(newModel())
 	if _, err := p.Run(); err != nil {
@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"halo/i18n"
+	"testing"
+)
+
+func TestStatusText(t *testing.T) {
+	t.Cleanup(func() { i18n.SetLocale(i18n.Default) })
+
+	cases := []struct {
+		locale i18n.Locale
+		status string
+		want   string
+	}{
+		{i18n.En, statusQueued, "deleted locally, will be synced"},
+		{i18n.En, statusFailed + "connection refused", "failed: connection refused"},
+		{i18n.Ru, statusSaved, "сохранено"},
+		{i18n.Ru, statusFailed + "connection refused", "ошибка: connection refused"},
+	}
+	for _, c := range cases {
+		i18n.SetLocale(c.locale)
+		if got := statusText(c.status); got != c.want {
+			t.Errorf("statusText(%q) in %s = %q, want %q", c.status, c.locale, got, c.want)
+		}
+	}
+}
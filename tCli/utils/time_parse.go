@@ -2,63 +2,213 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// layouts with a full date, tried in order
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
 func ParseHumanTime(input string) (int64, error) {
+	return ParseHumanTimeAt(input, time.Now())
+}
+
+// ParseHumanTimeAt resolves input relative to now, it accepts:
+//
+//	now, 15:04, 02.01, 02.01 15:04, 02.01.2006 [15:04]
+//	2006-01-02 [15:04[:05]], ISO 8601 (2006-01-02T15:04:05Z07:00)
+//	in 2h, in 1h30m, in 2d, 30m ago, 1d ago
+//	today, yesterday, tomorrow, with an optional time: yesterday 21:00
+//	weekday names (monday, mon) with an optional last/next prefix and time,
+//	a bare weekday is its latest occurrence up to today
+//
+// An empty input gives 0, which means "not set".
+func ParseHumanTimeAt(input string, now time.Time) (int64, error) {
 	input = strings.TrimSpace(strings.ToLower(input))
 	if input == "" {
 		return 0, nil
 	}
 	if input == "now" {
-		return time.Now().Unix(), nil
+		return now.Unix(), nil
 	}
 
-	now := time.Now()
-	currentYear := now.Year()
-
-	if t, err := time.Parse("15:04", input); err == nil {
-		full := time.Date(
-			currentYear,
-			now.Month(),
-			now.Day(),
-			t.Hour(),
-			t.Minute(),
-			0,
-			0,
-			now.Location(),
-		)
-		return full.Unix(), nil
+	if t, ok := parseClock(input); ok {
+		return atClock(now, t).Unix(), nil
 	}
 
 	if t, err := time.Parse("02.01", input); err == nil {
-		full := time.Date(
-			currentYear,
-			t.Month(),
-			t.Day(),
-			0,
-			0,
-			0,
-			0,
-			now.Location(),
-		)
-		return full.Unix(), nil
+		return dayOf(now, now.Year(), t.Month(), t.Day()).Unix(), nil
 	}
 
 	if t, err := time.Parse("02.01 15:04", input); err == nil {
-		full := time.Date(
-			currentYear,
-			t.Month(),
-			t.Day(),
-			t.Hour(),
-			t.Minute(),
-			0,
-			0,
-			now.Location(),
-		)
-		return full.Unix(), nil
+		day := dayOf(now, now.Year(), t.Month(), t.Day())
+		return atClock(day, t).Unix(), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(input), now.Location()); err == nil {
+			return t.Unix(), nil
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(input, "in "); ok {
+		d, err := parseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid time format: %q", input)
+		}
+		return now.Add(d).Unix(), nil
+	}
+
+	if rest, ok := strings.CutSuffix(input, " ago"); ok {
+		d, err := parseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid time format: %q", input)
+		}
+		return now.Add(-d).Unix(), nil
+	}
+
+	if t, ok := parseRelativeDay(input, now); ok {
+		return t.Unix(), nil
 	}
 
 	return 0, fmt.Errorf("Invalid time format: %q", input)
 }
+
+func parseClock(input string) (time.Time, bool) {
+	t, err := time.Parse("15:04", input)
+	return t, err == nil
+}
+
+func dayOf(now time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+}
+
+func atClock(day time.Time, clock time.Time) time.Time {
+	return time.Date(
+		day.Year(),
+		day.Month(),
+		day.Day(),
+		clock.Hour(),
+		clock.Minute(),
+		0,
+		0,
+		day.Location(),
+	)
+}
+
+// parseDuration extends time.ParseDuration with days: 2d, 1d12h
+func parseDuration(input string) (time.Duration, error) {
+	input = strings.ReplaceAll(input, " ", "")
+
+	var days int
+	if before, after, found := strings.Cut(input, "d"); found {
+		n, err := strconv.Atoi(before)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid days %q", before)
+		}
+		days = n
+		input = after
+	}
+
+	var d time.Duration
+	if input != "" {
+		var err error
+		d, err = time.ParseDuration(input)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration")
+	}
+
+	return time.Duration(days)*24*time.Hour + d, nil
+}
+
+// parseRelativeDay handles today/yesterday/tomorrow and weekdays, optionally followed by a time
+func parseRelativeDay(input string, now time.Time) (time.Time, bool) {
+	fields := strings.Fields(input)
+
+	var clock time.Time
+	if len(fields) > 1 {
+		t, ok := parseClock(fields[len(fields)-1])
+		if ok {
+			clock = t
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	today := dayOf(now, now.Year(), now.Month(), now.Day())
+
+	var day time.Time
+	switch {
+	case len(fields) == 1 && fields[0] == "today":
+		day = today
+	case len(fields) == 1 && fields[0] == "yesterday":
+		day = today.AddDate(0, 0, -1)
+	case len(fields) == 1 && fields[0] == "tomorrow":
+		day = today.AddDate(0, 0, 1)
+	default:
+		prefix := ""
+		if len(fields) == 2 {
+			prefix = fields[0]
+			fields = fields[1:]
+		}
+		if len(fields) != 1 {
+			return time.Time{}, false
+		}
+
+		weekday, ok := weekdays[fields[0]]
+		if !ok {
+			return time.Time{}, false
+		}
+
+		back := (int(today.Weekday()) - int(weekday) + 7) % 7
+		switch prefix {
+		case "":
+			day = today.AddDate(0, 0, -back)
+		case "last":
+			if back == 0 {
+				back = 7
+			}
+			day = today.AddDate(0, 0, -back)
+		case "next":
+			ahead := (int(weekday) - int(today.Weekday()) + 7) % 7
+			if ahead == 0 {
+				ahead = 7
+			}
+			day = today.AddDate(0, 0, ahead)
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	return atClock(day, clock), true
+}
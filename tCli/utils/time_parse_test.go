@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHumanTimeAt(t *testing.T) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	// Wednesday
+	now := time.Date(2025, time.March, 12, 14, 30, 15, 0, zone)
+
+	at := func(year int, month time.Month, day, hour, min int) int64 {
+		return time.Date(year, month, day, hour, min, 0, 0, zone).Unix()
+	}
+
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"", 0},
+		{"   ", 0},
+		{"now", now.Unix()},
+		{"NOW", now.Unix()},
+
+		{"15:04", at(2025, time.March, 12, 15, 4)},
+		{"9:00", at(2025, time.March, 12, 9, 0)},
+		{"02.01", at(2025, time.January, 2, 0, 0)},
+		{"02.01 15:04", at(2025, time.January, 2, 15, 4)},
+		{"24.12.2024", at(2024, time.December, 24, 0, 0)},
+		{"24.12.2024 18:45", at(2024, time.December, 24, 18, 45)},
+
+		{"2024-12-24", at(2024, time.December, 24, 0, 0)},
+		{"2024-12-24 18:45", at(2024, time.December, 24, 18, 45)},
+		{"2024-12-24T18:45", at(2024, time.December, 24, 18, 45)},
+		{"2024-12-24T18:45:30", at(2024, time.December, 24, 18, 45) + 30},
+		{"2024-12-24T18:45:00Z", time.Date(2024, time.December, 24, 18, 45, 0, 0, time.UTC).Unix()},
+		{"2024-12-24T18:45:00+05:00", time.Date(2024, time.December, 24, 13, 45, 0, 0, time.UTC).Unix()},
+
+		{"in 2h", now.Add(2 * time.Hour).Unix()},
+		{"in 1h30m", now.Add(90 * time.Minute).Unix()},
+		{"in 2d", now.Add(48 * time.Hour).Unix()},
+		{"30m ago", now.Add(-30 * time.Minute).Unix()},
+		{"1d12h ago", now.Add(-36 * time.Hour).Unix()},
+
+		{"today", at(2025, time.March, 12, 0, 0)},
+		{"yesterday", at(2025, time.March, 11, 0, 0)},
+		{"yesterday 21:00", at(2025, time.March, 11, 21, 0)},
+		{"tomorrow 8:15", at(2025, time.March, 13, 8, 15)},
+
+		{"wednesday", at(2025, time.March, 12, 0, 0)},
+		{"monday", at(2025, time.March, 10, 0, 0)},
+		{"mon 7:30", at(2025, time.March, 10, 7, 30)},
+		{"thursday", at(2025, time.March, 6, 0, 0)},
+		{"last wednesday", at(2025, time.March, 5, 0, 0)},
+		{"next wednesday 10:00", at(2025, time.March, 19, 10, 0)},
+		{"next fri", at(2025, time.March, 14, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHumanTimeAt(tt.input, now)
+			if err != nil {
+				t.Fatalf("ParseHumanTimeAt(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf(
+					"ParseHumanTimeAt(%q) = %v, want %v",
+					tt.input,
+					time.Unix(got, 0).In(zone),
+					time.Unix(tt.want, 0).In(zone),
+				)
+			}
+		})
+	}
+}
+
+func TestParseHumanTimeAt_Invalid(t *testing.T) {
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, time.UTC)
+
+	inputs := []string{
+		"soon",
+		"25:00",
+		"31.02",
+		"2024-13-01",
+		"in",
+		"in two hours",
+		"-2h ago",
+		"in -2h",
+		"someday 10:00",
+		"next",
+		"yesterday 21",
+		"previous monday",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseHumanTimeAt(input, now); err == nil {
+				t.Errorf("ParseHumanTimeAt(%q) = %v, want error", input, time.Unix(got, 0).UTC())
+			}
+		})
+	}
+}
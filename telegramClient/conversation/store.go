@@ -0,0 +1,109 @@
+package conversation
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the step a chat is at in a multi-step dialog and the answers given so far
+type State struct {
+	Flow    string
+	Step    string
+	Answers map[string]string
+	// Previous are the steps answered before Step, newest last
+	Previous []string
+}
+
+// Start begins the flow at its first step
+func Start(flow string, step string) State {
+	return State{Flow: flow, Step: step, Answers: make(map[string]string)}
+}
+
+// Next moves on to the step and remembers the current one for Back
+func (s State) Next(step string) State {
+	s.Previous = append(append([]string(nil), s.Previous...), s.Step)
+	s.Step = step
+	return s
+}
+
+// Back returns to the previous step, false at the first step of the flow
+func (s State) Back() (State, bool) {
+	if len(s.Previous) == 0 {
+		return s, false
+	}
+	s.Step = s.Previous[len(s.Previous)-1]
+	s.Previous = s.Previous[:len(s.Previous)-1]
+	return s, true
+}
+
+type entry struct {
+	state     State
+	expiresAt time.Time
+}
+
+// Store keeps the dialog of each chat in memory, a dialog the user walked
+// away from expires after the ttl; dialogs do not survive a restart of the bot
+type Store struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	states    map[int64]entry
+	lastSweep time.Time
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:    ttl,
+		now:    time.Now,
+		states: make(map[int64]entry),
+	}
+}
+
+// Get returns the dialog of the chat unless there is none or it expired
+func (s *Store) Get(chatId int64) (State, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.states[chatId]
+	if !ok {
+		return State{}, false
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.states, chatId)
+		return State{}, false
+	}
+	return entry.state, true
+}
+
+// Set saves the dialog of the chat, every answer gives the user another ttl
+func (s *Store) Set(chatId int64, state State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.states[chatId] = entry{state: state, expiresAt: now.Add(s.ttl)}
+
+	// expired dialogs of chats which never wrote again are dropped once per ttl
+	if now.Sub(s.lastSweep) >= s.ttl {
+		for id, entry := range s.states {
+			if !now.Before(entry.expiresAt) {
+				delete(s.states, id)
+			}
+		}
+		s.lastSweep = now
+	}
+}
+
+func (s *Store) Delete(chatId int64) {
+	s.mu.Lock()
+	delete(s.states, chatId)
+	s.mu.Unlock()
+}
+
+// Len is the number of dialogs kept, expired ones included until they are swept
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.states)
+}
@@ -0,0 +1,64 @@
+package conversation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreExpires(t *testing.T) {
+	now := time.Unix(1741780800, 0)
+	store := NewStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	store.Set(1, Start("habit", "name"))
+	if state, ok := store.Get(1); !ok || state.Step != "name" {
+		t.Fatalf("Get = %+v, %t", state, ok)
+	}
+
+	now = now.Add(59 * time.Second)
+	store.Set(1, Start("habit", "schedule"))
+	now = now.Add(59 * time.Second)
+	if _, ok := store.Get(1); !ok {
+		t.Error("an answer did not extend the dialog")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := store.Get(1); ok {
+		t.Error("the dialog did not expire")
+	}
+}
+
+func TestStoreSweepsAbandonedChats(t *testing.T) {
+	now := time.Unix(1741780800, 0)
+	store := NewStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	store.Set(1, Start("habit", "name"))
+	store.Set(2, Start("habit", "name"))
+
+	now = now.Add(2 * time.Minute)
+	store.Set(3, Start("habit", "name"))
+
+	if store.Len() != 1 {
+		t.Errorf("Len = %d after the sweep, want 1", store.Len())
+	}
+}
+
+func TestStateBack(t *testing.T) {
+	state := Start("habit", "name").Next("schedule").Next("remind")
+
+	state, ok := state.Back()
+	if !ok || state.Step != "schedule" {
+		t.Fatalf("Back = %s, %t, want schedule", state.Step, ok)
+	}
+
+	branch := state.Next("other")
+	if state.Previous[len(state.Previous)-1] != "name" || len(branch.Previous) != 2 {
+		t.Errorf("Next changed the state it was called on: %v", state.Previous)
+	}
+
+	state, _ = state.Back()
+	if _, ok := state.Back(); ok || state.Step != "name" {
+		t.Errorf("Back went past the first step: %s", state.Step)
+	}
+}
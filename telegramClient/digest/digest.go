@@ -0,0 +1,144 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/i18n"
+	"tgBot/stats"
+
+	"github.com/google/uuid"
+)
+
+// HistoryDays is how far back the notes of a digest should go, streaks need some history
+const HistoryDays = 90
+
+const dateLayout = "02.01"
+
+// byCategory keeps the notes of the category, uuid.Nil keeps the notes without one
+func byCategory(notes []haloapi.Note, categoryId uuid.UUID) []haloapi.Note {
+	var filtered []haloapi.Note
+	for _, note := range notes {
+		if note.Category_id == categoryId {
+			filtered = append(filtered, note)
+		}
+	}
+	return filtered
+}
+
+// ScheduledOn is false for habits not planned on the weekday, categories without a schedule are always on
+func ScheduledOn(category haloapi.Category, day time.Weekday) bool {
+	if category.Schedule_days == 0 {
+		return true
+	}
+	return category.Schedule_days&(1<<((int(day)+6)%7)) != 0
+}
+
+// Daily lists what got done today, what is still open and the streaks which
+// break unless something of the category is completed before midnight;
+// the notes and now are expected in the time zone of the user
+func Daily(p i18n.Printer, notes []haloapi.Note, categories []haloapi.Category, now time.Time) string {
+	var done, open []string
+	for _, note := range stats.Today(notes, now) {
+		if note.Completed {
+			done = append(done, "✅ "+note.Content)
+		} else {
+			open = append(open, "⬜ "+note.Content)
+		}
+	}
+
+	var atRisk []string
+	for _, category := range categories {
+		if !ScheduledOn(category, now.Weekday()) {
+			continue
+		}
+		summary := stats.Compute(byCategory(notes, category.Id), now)
+		if summary.CurrentStreak == 0 || completedToday(byCategory(notes, category.Id), now) {
+			continue
+		}
+		atRisk = append(atRisk, p.T("digest.streak_at_risk", category.Name, p.N("duration.days", summary.CurrentStreak)))
+	}
+
+	var b strings.Builder
+	b.WriteString(p.T("digest.daily_title", now.Format(dateLayout)))
+	if len(done) == 0 && len(open) == 0 {
+		b.WriteString("\n\n" + p.T("digest.nothing_today"))
+	}
+	if len(done) > 0 {
+		b.WriteString("\n\n" + p.N("digest.done_today", len(done)) + "\n" + strings.Join(done, "\n"))
+	}
+	if len(open) > 0 {
+		b.WriteString("\n\n" + p.T("digest.still_open") + "\n" + strings.Join(open, "\n"))
+	}
+	if len(atRisk) > 0 {
+		b.WriteString("\n\n" + p.T("digest.at_risk") + "\n" + strings.Join(atRisk, "\n"))
+	}
+	return b.String()
+}
+
+func completedToday(notes []haloapi.Note, now time.Time) bool {
+	for _, note := range stats.Today(notes, now) {
+		if note.Completed {
+			return true
+		}
+	}
+	return false
+}
+
+// change is the difference to last week, e.g. "+2"
+func change(this int, last int) string {
+	switch {
+	case this > last:
+		return fmt.Sprintf("↑ +%d", this-last)
+	case this < last:
+		return fmt.Sprintf("↓ %d", this-last)
+	}
+	return "="
+}
+
+// Weekly counts the completed notes of this and the previous week per
+// category, categories without notes in both weeks are left out
+func Weekly(p i18n.Printer, notes []haloapi.Note, categories []haloapi.Category, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := stats.StartOfWeek(today)
+	summary := stats.Compute(notes, now)
+
+	var b strings.Builder
+	b.WriteString(p.T("digest.weekly_title", monday.Format(dateLayout), monday.AddDate(0, 0, 6).Format(dateLayout)))
+	b.WriteString("\n\n" + p.T("digest.week_total", summary.Week.Done, summary.LastWeek.Done,
+		change(summary.Week.Done, summary.LastWeek.Done)))
+	if summary.Week.Tracked > 0 {
+		b.WriteString("\n" + p.T("digest.week_tracked", p.Duration(summary.Week.Tracked)))
+	}
+
+	type row struct {
+		name string
+		week stats.Week
+		last stats.Week
+	}
+	var rows []row
+	for _, category := range categories {
+		s := stats.Compute(byCategory(notes, category.Id), now)
+		rows = append(rows, row{category.Name, s.Week, s.LastWeek})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].week.Done > rows[j].week.Done })
+
+	// notes without a category come last
+	s := stats.Compute(byCategory(notes, uuid.Nil), now)
+	rows = append(rows, row{p.T("digest.no_category"), s.Week, s.LastWeek})
+
+	var lines []string
+	for _, r := range rows {
+		if r.week.Done == 0 && r.last.Done == 0 {
+			continue
+		}
+		lines = append(lines, p.T("digest.week_category", r.name, r.week.Done, r.last.Done, change(r.week.Done, r.last.Done)))
+	}
+	if len(lines) > 0 {
+		b.WriteString("\n\n" + p.T("digest.by_category") + "\n" + strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package digest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/i18n"
+
+	"github.com/google/uuid"
+)
+
+var en = i18n.NewPrinter(i18n.En)
+
+func note(categoryId uuid.UUID, content string, at time.Time, completed bool) haloapi.Note {
+	return haloapi.Note{Id: uuid.New(), Category_id: categoryId, Content: content, Created_at: at.Unix(), Completed: completed}
+}
+
+func TestDaily(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	// a wednesday evening
+	now := time.Date(2025, 3, 12, 21, 0, 0, 0, loc)
+
+	run := haloapi.Category{Id: uuid.New(), Name: "Run"}
+	read := haloapi.Category{Id: uuid.New(), Name: "Read"}
+	// weekends only, a streak of it is not at risk on a wednesday
+	hike := haloapi.Category{Id: uuid.New(), Name: "Hike", Schedule_days: 1<<5 | 1<<6}
+
+	notes := []haloapi.Note{
+		note(run.Id, "Run", now.AddDate(0, 0, -2), true),
+		note(run.Id, "Run", now.AddDate(0, 0, -1), true),
+		note(read.Id, "Read", now.AddDate(0, 0, -1), true),
+		note(read.Id, "Read", now.Add(-time.Hour), true),
+		note(hike.Id, "Hike", now.AddDate(0, 0, -1), true),
+		note(uuid.Nil, "Call mom", now.Add(-2*time.Hour), false),
+	}
+
+	text := Daily(en, notes, []haloapi.Category{run, read, hike}, now)
+	for _, want := range []string{
+		"Evening digest, 12.03",
+		"Done today, 1 note:\n✅ Read",
+		"Still open:\n⬜ Call mom",
+		"Streaks at risk, mark them before midnight:\n🔥 Run: 2 days in a row",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("daily digest misses %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"🔥 Read", "🔥 Hike"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("daily digest has %q:\n%s", unwanted, text)
+		}
+	}
+
+	empty := Daily(en, nil, nil, now)
+	if !strings.Contains(empty, "Nothing was recorded today.") {
+		t.Errorf("empty daily digest = %q", empty)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	// a sunday evening, the week started on monday the 10th
+	now := time.Date(2025, 3, 16, 20, 0, 0, 0, time.UTC)
+
+	run := haloapi.Category{Id: uuid.New(), Name: "Run"}
+	read := haloapi.Category{Id: uuid.New(), Name: "Read"}
+	idle := haloapi.Category{Id: uuid.New(), Name: "Idle"}
+
+	var notes []haloapi.Note
+	for i := 0; i < 3; i++ {
+		notes = append(notes, note(run.Id, "Run", now.AddDate(0, 0, -i), true))
+	}
+	notes = append(notes,
+		note(run.Id, "Run", now.AddDate(0, 0, -8), true),
+		note(read.Id, "Read", now.AddDate(0, 0, -8), true),
+		note(read.Id, "Read", now.AddDate(0, 0, -9), true),
+		note(uuid.Nil, "Call mom", now.AddDate(0, 0, -1), true),
+		note(uuid.Nil, "Plan", now.AddDate(0, 0, -1), false),
+	)
+
+	text := Weekly(en, notes, []haloapi.Category{read, run, idle}, now)
+	for _, want := range []string{
+		"Weekly report 10.03–16.03",
+		"Done: 4, last week 3 (↑ +1)",
+		"Run: 3 / 1 (↑ +2)\nRead: 0 / 2 (↓ -2)\nWithout category: 1 / 0 (↑ +1)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("weekly report misses %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Idle") {
+		t.Errorf("weekly report lists a category without notes:\n%s", text)
+	}
+}
+
+func TestDue(t *testing.T) {
+	subscriber := haloapi.DigestSubscriber{Timezone: "Europe/Moscow", Daily_digest: true, Weekly_digest: true}
+
+	// 18:30 UTC on a sunday is 21:30 in Moscow
+	sunday := time.Date(2025, 3, 16, 18, 30, 0, 0, time.UTC)
+	if due := Due(subscriber, sunday); len(due) != 1 || due[0] != KindDaily {
+		t.Errorf("Due at 21:30 Moscow = %v, want daily", due)
+	}
+	if due := Due(subscriber, sunday.Add(-time.Hour)); len(due) != 1 || due[0] != KindWeekly {
+		t.Errorf("Due at 20:30 Moscow on sunday = %v, want weekly", due)
+	}
+	if due := Due(subscriber, sunday.AddDate(0, 0, -1).Add(-time.Hour)); len(due) != 0 {
+		t.Errorf("Due at 20:30 Moscow on saturday = %v, want none", due)
+	}
+
+	subscriber.Timezone = ""
+	if due := Due(subscriber, time.Date(2025, 3, 16, 21, 0, 0, 0, time.UTC)); len(due) != 1 || due[0] != KindDaily {
+		t.Errorf("Due without a timezone = %v, want daily at 21 UTC", due)
+	}
+	subscriber.Daily_digest = false
+	if due := Due(subscriber, time.Date(2025, 3, 16, 21, 0, 0, 0, time.UTC)); len(due) != 0 {
+		t.Errorf("Due with the daily digest off = %v", due)
+	}
+}
+
+func TestSchedulerDeliversOncePerDay(t *testing.T) {
+	subscriber := haloapi.DigestSubscriber{User_id: uuid.New(), Timezone: "Asia/Tokyo", Daily_digest: true}
+
+	var delivered []time.Time
+	scheduler := NewScheduler(
+		func() ([]haloapi.DigestSubscriber, error) { return []haloapi.DigestSubscriber{subscriber}, nil },
+		func(_ haloapi.DigestSubscriber, kind Kind, now time.Time) error {
+			delivered = append(delivered, now)
+			return nil
+		},
+	)
+
+	// 12:00 UTC is 21:00 in Tokyo
+	noon := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	scheduler.check(noon)
+	scheduler.check(noon.Add(time.Minute))
+	scheduler.check(noon.Add(59 * time.Minute))
+	scheduler.check(noon.Add(time.Hour))
+	scheduler.check(noon.AddDate(0, 0, 1).Add(30 * time.Minute))
+
+	if len(delivered) != 2 {
+		t.Fatalf("delivered %d digests, want one per day: %v", len(delivered), delivered)
+	}
+	if delivered[0].Location().String() != "Asia/Tokyo" || delivered[0].Hour() != 21 {
+		t.Errorf("digest time = %v, want 21:00 in Tokyo", delivered[0])
+	}
+}
+
+func TestSchedulerRetriesFailedDelivery(t *testing.T) {
+	subscriber := haloapi.DigestSubscriber{User_id: uuid.New(), Timezone: "Asia/Tokyo", Daily_digest: true}
+
+	attempts, delivered := 0, 0
+	scheduler := NewScheduler(
+		func() ([]haloapi.DigestSubscriber, error) { return []haloapi.DigestSubscriber{subscriber}, nil },
+		func(haloapi.DigestSubscriber, Kind, time.Time) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("telegram is down")
+			}
+			delivered++
+			return nil
+		},
+	)
+
+	// a failed digest is tried again while its hour lasts, then sent once
+	noon := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	scheduler.check(noon)
+	scheduler.check(noon.Add(time.Minute))
+	scheduler.check(noon.Add(2 * time.Minute))
+
+	if attempts != 2 || delivered != 1 {
+		t.Errorf("attempts = %d, delivered = %d, want a retry after the failure and no more", attempts, delivered)
+	}
+}
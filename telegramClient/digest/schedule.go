@@ -0,0 +1,132 @@
+package digest
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"tgBot/haloapi"
+
+	"github.com/google/uuid"
+)
+
+type Kind string
+
+const (
+	KindDaily  Kind = "daily"
+	KindWeekly Kind = "weekly"
+)
+
+// the evening digest goes out at DailyHour and the weekly report on sunday
+// at WeeklyHour, both in the time zone of the user
+const (
+	DailyHour  = 21
+	WeeklyHour = 20
+	weeklyDay  = time.Sunday
+)
+
+// checkEvery is how often the subscribers are read, the settings of a user count from the next check
+const checkEvery = time.Minute
+
+// Location is the time zone of the user, UTC when none is set or it is unknown
+func Location(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Due returns the digests of the subscriber whose hour it is in the time zone of the user
+func Due(subscriber haloapi.DigestSubscriber, now time.Time) []Kind {
+	local := now.In(Location(subscriber.Timezone))
+
+	var due []Kind
+	if subscriber.Daily_digest && local.Hour() == DailyHour {
+		due = append(due, KindDaily)
+	}
+	if subscriber.Weekly_digest && local.Weekday() == weeklyDay && local.Hour() == WeeklyHour {
+		due = append(due, KindWeekly)
+	}
+	return due
+}
+
+type sentKey struct {
+	user uuid.UUID
+	kind Kind
+}
+
+// Scheduler delivers each digest once per local day while its hour lasts, so a
+// digest is not lost to a slow check and a failed delivery is tried again at
+// the next check; what was sent is kept in memory, a restart of the bot within
+// the hour sends the digest again
+type Scheduler struct {
+	subscribers func() ([]haloapi.DigestSubscriber, error)
+	deliver     func(subscriber haloapi.DigestSubscriber, kind Kind, now time.Time) error
+
+	mu   sync.Mutex
+	sent map[sentKey]string
+}
+
+func NewScheduler(
+	subscribers func() ([]haloapi.DigestSubscriber, error),
+	deliver func(subscriber haloapi.DigestSubscriber, kind Kind, now time.Time) error,
+) *Scheduler {
+	return &Scheduler{
+		subscribers: subscribers,
+		deliver:     deliver,
+		sent:        make(map[sentKey]string),
+	}
+}
+
+// check delivers the digests due at now, now is passed on in the time zone of the user
+func (s *Scheduler) check(now time.Time) {
+	subscribers, err := s.subscribers()
+	if err != nil {
+		log.Printf("digest subscribers: %v", err)
+		return
+	}
+
+	for _, subscriber := range subscribers {
+		for _, kind := range Due(subscriber, now) {
+			local := now.In(Location(subscriber.Timezone))
+			key := sentKey{subscriber.User_id, kind}
+			day := local.Format(time.DateOnly)
+
+			s.mu.Lock()
+			sent := s.sent[key] == day
+			s.mu.Unlock()
+			if sent {
+				continue
+			}
+
+			if err := s.deliver(subscriber, kind, local); err != nil {
+				log.Printf("%s digest of user %s: %v", kind, subscriber.User_id, err)
+				continue
+			}
+
+			s.mu.Lock()
+			s.sent[key] = day
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Run checks for due digests until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.check(now)
+		}
+	}
+}
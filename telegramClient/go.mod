@@ -3,7 +3,18 @@ module tgBot
 go 1.22.0
 
 require (
-	github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/segmentio/kafka-go v0.4.48
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
 	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
 )
+
+require i18n v0.0.0
+
+replace i18n => ../i18n
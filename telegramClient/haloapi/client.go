@@ -0,0 +1,165 @@
+package haloapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotLinked means the chat is not linked to a Halo account yet
+var ErrNotLinked = errors.New("chat is not linked")
+
+// bot tokens are renewed a bit before they expire
+const tokenRenewBefore = 5 * time.Minute
+
+type LinkCode struct {
+	Code       string `json:"code"`
+	Expires_at int64  `json:"expires_at"`
+}
+
+type TelegramChats struct {
+	User_id  uuid.UUID `json:"user_id"`
+	Chat_ids []int64   `json:"chat_ids"`
+}
+
+type BotToken struct {
+	Token      string    `json:"token"`
+	User_id    uuid.UUID `json:"user_id"`
+	Expires_at int64     `json:"expires_at"`
+}
+
+// Client talks to the Halo backend on behalf of telegram chats: auth_service
+// and user_service directly for the internal bot routes, everything else through the public api
+type Client struct {
+	authUrl   string
+	userUrl   string
+	apiUrl    string
+	botSecret string
+	http      *http.Client
+
+	mu     sync.Mutex
+	tokens map[int64]BotToken
+}
+
+func NewClient(authUrl string, userUrl string, apiUrl string, botSecret string) *Client {
+	return &Client{
+		authUrl:   strings.TrimRight(authUrl, "/"),
+		userUrl:   strings.TrimRight(userUrl, "/"),
+		apiUrl:    strings.TrimRight(apiUrl, "/"),
+		botSecret: botSecret,
+		http:      &http.Client{Timeout: 10 * time.Second},
+		tokens:    make(map[int64]BotToken),
+	}
+}
+
+// NewClientFromEnv reads HALO_AUTH_URL, HALO_USER_URL, HALO_API_URL and TELEGRAM_BOT_SECRET
+func NewClientFromEnv() *Client {
+	authUrl := os.Getenv("HALO_AUTH_URL")
+	if authUrl == "" {
+		authUrl = "http://auth_service:8080"
+	}
+	userUrl := os.Getenv("HALO_USER_URL")
+	if userUrl == "" {
+		userUrl = "http://user_service:8080"
+	}
+	apiUrl := os.Getenv("HALO_API_URL")
+	if apiUrl == "" {
+		apiUrl = "http://nginx:80"
+	}
+	return NewClient(authUrl, userUrl, apiUrl, os.Getenv("TELEGRAM_BOT_SECRET"))
+}
+
+func (c *Client) postInternal(path string, body any, result any) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", c.authUrl+path, bytes.NewBuffer(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Bot-Secret", c.botSecret)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("%s: status %d", path, resp.StatusCode)
+	}
+
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(result)
+}
+
+// LinkCode asks auth_service for a one-time code the user confirms from tCli
+func (c *Client) LinkCode(chatId int64) (LinkCode, error) {
+	var code LinkCode
+	_, err := c.postInternal("/internal/telegram/link_code", map[string]int64{"chat_id": chatId}, &code)
+	return code, err
+}
+
+// Unlink removes the link of the chat, auth_service revokes the tokens issued
+// for it and the cached one is dropped as well
+func (c *Client) Unlink(chatId int64) error {
+	defer c.Forget(chatId)
+
+	var link struct {
+		Chat_id int64 `json:"chat_id"`
+	}
+	status, err := c.postInternal("/internal/telegram/unlink", map[string]int64{"chat_id": chatId}, &link)
+	if status == http.StatusNotFound {
+		return ErrNotLinked
+	}
+	return err
+}
+
+// Chats returns the chats linked to the user, a user may link several
+func (c *Client) Chats(userId uuid.UUID) ([]int64, error) {
+	var chats TelegramChats
+	_, err := c.postInternal("/internal/telegram/chats", map[string]uuid.UUID{"user_id": userId}, &chats)
+	return chats.Chat_ids, err
+}
+
+// Token returns the scoped token of the user linked to the chat, cached until it nearly expires
+func (c *Client) Token(chatId int64) (BotToken, error) {
+	c.mu.Lock()
+	token, ok := c.tokens[chatId]
+	c.mu.Unlock()
+
+	if ok && time.Until(time.Unix(token.Expires_at, 0)) > tokenRenewBefore {
+		return token, nil
+	}
+
+	status, err := c.postInternal("/internal/telegram/token", map[string]int64{"chat_id": chatId}, &token)
+	if status == http.StatusNotFound {
+		return token, ErrNotLinked
+	}
+	if err != nil {
+		return token, err
+	}
+
+	c.mu.Lock()
+	c.tokens[chatId] = token
+	c.mu.Unlock()
+
+	return token, nil
+}
+
+// Forget drops the cached token of the chat, e.g. after the backend rejected it
+func (c *Client) Forget(chatId int64) {
+	c.mu.Lock()
+	delete(c.tokens, chatId)
+	c.mu.Unlock()
+}
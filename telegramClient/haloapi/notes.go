@@ -0,0 +1,233 @@
+package haloapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoteGone means the note was deleted on the server, e.g. from tCli
+var ErrNoteGone = errors.New("note was deleted")
+
+// categories and notes come in pages of this size, a shorter page is the last one
+const pageSize = 10
+
+// maxNotePages bounds the walk through the notes, newest first
+const maxNotePages = 50
+
+// ErrCategoryExists means the user already has a category of that name
+var ErrCategoryExists = errors.New("category already exists")
+
+// Category is a habit when it has a schedule, Schedule_days has a bit per
+// weekday starting with monday and Remind_time is HH:MM or empty
+type Category struct {
+	Id            uuid.UUID `json:"category_id"`
+	Name          string    `json:"name"`
+	Schedule_days int       `json:"schedule_days"`
+	Remind_time   string    `json:"remind_time"`
+}
+
+type Note struct {
+	Id          uuid.UUID `json:"note_id"`
+	Category_id uuid.UUID `json:"category_id"`
+	Content     string    `json:"content"`
+	Created_at  int64     `json:"created_at"`
+	Updated_at  int64     `json:"updated_at"`
+	Ended_at    int64     `json:"ended_at"`
+	Completed   bool      `json:"completed"`
+}
+
+type syncConflict struct {
+	Note_id uuid.UUID `json:"note_id"`
+	Reason  string    `json:"reason"`
+}
+
+type syncResponse struct {
+	Applied   []uuid.UUID    `json:"applied"`
+	Conflicts []syncConflict `json:"conflicts"`
+}
+
+// do sends a request to the public api as the user linked to the chat, a
+// rejected token is renewed once since the user may have logged out meanwhile
+func (c *Client) do(chatId int64, method string, path string, body any, result any) (int, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.Token(chatId)
+		if err != nil {
+			return 0, err
+		}
+
+		req, err := http.NewRequest(method, c.apiUrl+path, bytes.NewReader(data))
+		if err != nil {
+			return 0, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.AddCookie(&http.Cookie{Name: "session_token", Value: token.Token})
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return 0, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			c.Forget(chatId)
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+		}
+		if result == nil {
+			_, err = io.Copy(io.Discard, resp.Body)
+			return resp.StatusCode, err
+		}
+		return resp.StatusCode, json.NewDecoder(resp.Body).Decode(result)
+	}
+}
+
+// Categories returns all categories of the user linked to the chat
+func (c *Client) Categories(chatId int64) ([]Category, error) {
+	var categories []Category
+	for page := 1; ; page++ {
+		var batch []Category
+		path := "/api/category?" + url.Values{"page": {fmt.Sprint(page)}}.Encode()
+		if _, err := c.do(chatId, "GET", path, nil, &batch); err != nil {
+			return nil, err
+		}
+		categories = append(categories, batch...)
+		if len(batch) < pageSize {
+			return categories, nil
+		}
+	}
+}
+
+// AddCategory creates the category, a taken name is ErrCategoryExists
+func (c *Client) AddCategory(chatId int64, category Category) (Category, error) {
+	var created Category
+	status, err := c.do(chatId, "POST", "/api/category", category, &created)
+	if status == http.StatusConflict {
+		return category, ErrCategoryExists
+	}
+	return created, err
+}
+
+// AddNote creates the note, the id is generated here so a retried request is not a duplicate
+func (c *Client) AddNote(chatId int64, note Note) (Note, error) {
+	if note.Id == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return note, err
+		}
+		note.Id = id
+	}
+	if note.Created_at == 0 {
+		note.Created_at = time.Now().Unix()
+	}
+	note.Updated_at = note.Created_at
+
+	_, err := c.do(chatId, "POST", "/api/note", note, nil)
+	return note, err
+}
+
+// SaveNote pushes a changed note through the sync endpoint, which keeps the
+// newest version, so updated_at is bumped past the previous one
+func (c *Client) SaveNote(chatId int64, note Note) (Note, error) {
+	note.Updated_at = max(time.Now().Unix(), note.Updated_at+1)
+
+	var response syncResponse
+	request := map[string][]Note{"notes": {note}}
+	if _, err := c.do(chatId, "POST", "/api/note/sync", request, &response); err != nil {
+		return note, err
+	}
+
+	for _, conflict := range response.Conflicts {
+		if conflict.Note_id != note.Id {
+			continue
+		}
+		if conflict.Reason == "deleted" {
+			return note, ErrNoteGone
+		}
+		return note, fmt.Errorf("note %s: %s", note.Id, conflict.Reason)
+	}
+
+	return note, nil
+}
+
+// Notes returns a page of the notes, newest first, pages start at 1
+func (c *Client) Notes(chatId int64, page int) ([]Note, error) {
+	var notes []Note
+	path := "/api/note?" + url.Values{"page": {fmt.Sprint(page)}}.Encode()
+	_, err := c.do(chatId, "GET", path, nil, &notes)
+	return notes, err
+}
+
+// NotesSince returns the notes created at or after the unix time, newest first
+func (c *Client) NotesSince(chatId int64, since int64) ([]Note, error) {
+	var notes []Note
+	for page := 1; page <= maxNotePages; page++ {
+		batch, err := c.Notes(chatId, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, note := range batch {
+			if note.Created_at < since {
+				return notes, nil
+			}
+			notes = append(notes, note)
+		}
+		if len(batch) < pageSize {
+			break
+		}
+	}
+	return notes, nil
+}
+
+// FindNote returns the note of the user, a deleted note is ErrNoteGone
+func (c *Client) FindNote(chatId int64, noteId uuid.UUID) (Note, error) {
+	var note Note
+	status, err := c.do(chatId, "GET", "/api/note/"+noteId.String(), nil, &note)
+	if status == http.StatusNotFound {
+		return note, ErrNoteGone
+	}
+	return note, err
+}
+
+// DeleteNote deletes the note, a note which is already gone is not an error
+func (c *Client) DeleteNote(chatId int64, noteId uuid.UUID) error {
+	status, err := c.do(chatId, "DELETE", "/api/note", map[string]uuid.UUID{"note_id": noteId}, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+type Profile struct {
+	User_id  uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Locale   string    `json:"locale"`
+}
+
+// Profile returns the profile of the user linked to the chat, the locale is empty until the user picks one
+func (c *Client) Profile(chatId int64) (Profile, error) {
+	var profile Profile
+	_, err := c.do(chatId, "GET", "/api/user/profile", nil, &profile)
+	return profile, err
+}
@@ -0,0 +1,60 @@
+package haloapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Settings are the digest settings of the user, Timezone is an IANA name, empty for UTC
+type Settings struct {
+	Timezone      string `json:"timezone"`
+	Daily_digest  bool   `json:"daily_digest"`
+	Weekly_digest bool   `json:"weekly_digest"`
+}
+
+// DigestSubscriber is a user who turned a digest on
+type DigestSubscriber struct {
+	User_id       uuid.UUID `json:"user_id"`
+	Locale        string    `json:"locale"`
+	Timezone      string    `json:"timezone"`
+	Daily_digest  bool      `json:"daily_digest"`
+	Weekly_digest bool      `json:"weekly_digest"`
+}
+
+// Settings returns the digest settings of the user linked to the chat
+func (c *Client) Settings(chatId int64) (Settings, error) {
+	var settings Settings
+	_, err := c.do(chatId, "GET", "/api/user/settings", nil, &settings)
+	return settings, err
+}
+
+func (c *Client) SaveSettings(chatId int64, settings Settings) (Settings, error) {
+	var saved Settings
+	_, err := c.do(chatId, "PUT", "/api/user/settings", settings, &saved)
+	return saved, err
+}
+
+// DigestSubscribers asks user_service for the users with a digest turned on
+func (c *Client) DigestSubscribers() ([]DigestSubscriber, error) {
+	req, err := http.NewRequest("GET", c.userUrl+"/internal/user/digests", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Bot-Secret", c.botSecret)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("digest subscribers: status %d", resp.StatusCode)
+	}
+
+	var subscribers []DigestSubscriber
+	return subscribers, json.NewDecoder(resp.Body).Decode(&subscribers)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/google/uuid"
+)
+
+// the running activity of each chat, it is an open note on the server which
+// gets its end time when the user presses the finish button; the map only
+// caches it, after a restart it is recovered from the server
+var (
+	activitiesMu sync.Mutex
+	activities   = make(map[int64]haloapi.Note)
+)
+
+func reply(bot *tgbotapi.BotAPI, chatId int64, text string) {
+	send(bot, chatId, tgbotapi.NewMessage(chatId, text))
+}
+
+// replyApiError explains a failed backend call, unlinked chats are pointed to /link
+func replyApiError(bot *tgbotapi.BotAPI, chatId int64, err error) {
+	if errors.Is(err, haloapi.ErrNotLinked) {
+		reply(bot, chatId, printer(chatId).T("error.not_linked"))
+		return
+	}
+	log.Printf("halo api for chat %d: %v", chatId, err)
+	reply(bot, chatId, printer(chatId).T("error.api"))
+}
+
+// handleStartCategory starts an activity of the category named on the button
+func handleStartCategory(bot *tgbotapi.BotAPI, chatId int64, name string) {
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	for _, category := range categories {
+		if category.Name == name {
+			startActivity(bot, chatId, category.Name, category.Id)
+			return
+		}
+	}
+
+	// the button is from an older keyboard, the category was renamed or deleted since
+	msg := tgbotapi.NewMessage(chatId, printer(chatId).T("activity.category_gone", name))
+	msg.ReplyMarkup = GetMainKeyboard(chatId)
+	send(bot, chatId, msg)
+}
+
+// maxActivity bounds how far back an open activity is looked up on the server
+const maxActivity = 24 * time.Hour
+
+// isActivity tells the notes of the activity buttons from other open notes:
+// they are named after their category, or are a meal without one
+func isActivity(note haloapi.Note, names map[uuid.UUID]string) bool {
+	if note.Ended_at != 0 || note.Completed {
+		return false
+	}
+	if note.Category_id != uuid.Nil {
+		return names[note.Category_id] == note.Content
+	}
+	for _, locale := range i18n.Supported {
+		if note.Content == i18n.NewPrinter(locale).T("activity.meal") {
+			return true
+		}
+	}
+	return false
+}
+
+// runningActivity returns the open activity of the chat, the newest one on
+// the server when the bot was restarted since it was started
+func runningActivity(chatId int64) (haloapi.Note, bool, error) {
+	activitiesMu.Lock()
+	note, running := activities[chatId]
+	activitiesMu.Unlock()
+	if running {
+		return note, true, nil
+	}
+
+	notes, err := api.NotesSince(chatId, time.Now().Add(-maxActivity).Unix())
+	if err != nil {
+		return haloapi.Note{}, false, err
+	}
+
+	var names map[uuid.UUID]string
+	for _, note := range notes {
+		if note.Ended_at != 0 || note.Completed {
+			continue
+		}
+		if names == nil {
+			names = categoryNames(chatId)
+		}
+		if !isActivity(note, names) {
+			continue
+		}
+
+		activitiesMu.Lock()
+		defer activitiesMu.Unlock()
+		if current, ok := activities[chatId]; ok {
+			// started meanwhile
+			return current, true, nil
+		}
+		activities[chatId] = note
+		return note, true, nil
+	}
+	return haloapi.Note{}, false, nil
+}
+
+// startActivity creates the open note, a running activity is finished first
+func startActivity(bot *tgbotapi.BotAPI, chatId int64, content string, categoryId uuid.UUID) {
+	_, running, err := runningActivity(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	if running {
+		finishActivity(bot, chatId)
+	}
+
+	note, err := api.AddNote(chatId, haloapi.Note{
+		Content:     content,
+		Category_id: categoryId,
+	})
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	activitiesMu.Lock()
+	activities[chatId] = note
+	activitiesMu.Unlock()
+
+	p := printer(chatId)
+	reply(bot, chatId, p.T("activity.started", content, p.T("button.finish")))
+}
+
+// finishActivity sets the end time of the running note and reports how long it took
+func finishActivity(bot *tgbotapi.BotAPI, chatId int64) {
+	note, running, err := runningActivity(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	if !running {
+		reply(bot, chatId, printer(chatId).T("activity.not_running"))
+		return
+	}
+
+	now := time.Now()
+	note.Ended_at = max(now.Unix(), note.Created_at)
+	note.Completed = true
+
+	_, err = api.SaveNote(chatId, note)
+	if errors.Is(err, haloapi.ErrNoteGone) {
+		forgetActivity(chatId, note.Id)
+		reply(bot, chatId, printer(chatId).T("activity.gone", note.Content))
+		return
+	}
+	if err != nil {
+		// the note stays running so the finish button can be pressed again
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	forgetActivity(chatId, note.Id)
+
+	duration := time.Duration(note.Ended_at-note.Created_at) * time.Second
+	p := printer(chatId)
+	reply(bot, chatId, p.T("activity.finished", note.Content, p.Duration(duration)))
+}
+
+// forgetActivity drops the running note unless another one was started meanwhile
+func forgetActivity(chatId int64, noteId uuid.UUID) {
+	activitiesMu.Lock()
+	defer activitiesMu.Unlock()
+
+	if activities[chatId].Id == noteId {
+		delete(activities, chatId)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+
+	"github.com/google/uuid"
+)
+
+func TestActivity(t *testing.T) {
+	chat := newTestChat(t, 201, "en")
+	sport := haloapi.Category{Id: uuid.New(), Name: "Sport"}
+	chat.halo.categories = []haloapi.Category{sport}
+
+	chat.expect("✅ I'm done", "Nothing is running")
+	chat.expect("▶ Sport", "Started: Sport")
+	if len(chat.halo.notes) != 1 || chat.halo.notes[0].Category_id != sport.Id || chat.halo.notes[0].Ended_at != 0 {
+		t.Fatalf("notes after the start = %+v", chat.halo.notes)
+	}
+	first := chat.halo.notes[0].Id
+
+	// a new activity finishes the running one first
+	sent := chat.say("🍽 I started eating")
+	if len(sent) != 2 || !strings.Contains(sent[0].Text, "Done: Sport") || !strings.Contains(sent[1].Text, "Started: Meal") {
+		t.Fatalf("starting a second activity sent %+v", sent)
+	}
+	if note, _ := chat.halo.note(first); note.Ended_at == 0 || !note.Completed {
+		t.Errorf("first activity after the second started = %+v", note)
+	}
+
+	chat.expect("✅ I'm done", "Done: Meal")
+	chat.expect("✅ I'm done", "Nothing is running")
+	for _, note := range chat.halo.notes {
+		if note.Ended_at == 0 {
+			t.Errorf("note %q is still running", note.Content)
+		}
+	}
+}
+
+func TestActivityRecoveredAfterRestart(t *testing.T) {
+	chat := newTestChat(t, 202, "en")
+	sport := haloapi.Category{Id: uuid.New(), Name: "Sport"}
+	chat.halo.categories = []haloapi.Category{sport}
+
+	startedAt := time.Now().Add(-30 * time.Minute).Unix()
+	running := haloapi.Note{Id: uuid.New(), Category_id: sport.Id, Content: "Sport", Created_at: startedAt, Updated_at: startedAt}
+	chat.halo.notes = []haloapi.Note{
+		// an open note which is not an activity is left alone
+		{Id: uuid.New(), Category_id: sport.Id, Content: "buy new shoes", Created_at: startedAt + 60, Updated_at: startedAt + 60},
+		running,
+	}
+
+	chat.expect("✅ I'm done", "Done: Sport, took 30 min")
+	if note, _ := chat.halo.note(running.Id); note.Ended_at == 0 || !note.Completed {
+		t.Errorf("recovered activity = %+v, want it finished", note)
+	}
+	if note, _ := chat.halo.note(chat.halo.notes[0].Id); note.Completed {
+		t.Errorf("the todo was finished as an activity: %+v", note)
+	}
+}
+
+func TestActivityDeletedMeanwhile(t *testing.T) {
+	chat := newTestChat(t, 203, "en")
+	chat.halo.categories = []haloapi.Category{{Id: uuid.New(), Name: "Sport"}}
+
+	chat.expect("▶ Sport", "Started: Sport")
+	chat.halo.notes = nil
+
+	chat.expect("✅ I'm done", "was deleted already")
+	chat.expect("✅ I'm done", "Nothing is running")
+}
@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tgBot/digest"
+	"tgBot/haloapi"
+	"tgBot/i18n"
+	"tgBot/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/google/uuid"
+)
+
+const (
+	// listSize is the number of notes /list shows, one page of the note service
+	listSize = 10
+	// statsHistoryDays bounds how far back /stats looks for streaks
+	statsHistoryDays = 90
+	// buttonTextLimit keeps note contents on inline buttons readable
+	buttonTextLimit = 32
+)
+
+// views which can be redrawn after an inline button was pressed, the
+// habits of /today carry the category id instead of a note id
+const (
+	viewToday      = "today"
+	viewTodayHabit = "todayhabit"
+	viewList       = "list"
+)
+
+const (
+	actionDone   = "done"
+	actionDelete = "delete"
+)
+
+type commandHandler func(bot *tgbotapi.BotAPI, message *tgbotapi.Message)
+
+var commands = map[string]commandHandler{
+	"start": handleStart,
+	"link": func(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+		handleLink(bot, message.Chat.ID)
+	},
+	"unlink": func(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+		handleUnlink(bot, message.Chat.ID)
+	},
+	"today":    handleToday,
+	"list":     handleList,
+	"stats":    handleStats,
+	"add":      handleAdd,
+	"done":     handleDone,
+	"habit":    handleHabit,
+	"settings": handleSettings,
+	"cancel": func(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+		cancelConversation(bot, message.Chat.ID)
+	},
+	"back": func(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+		backConversation(bot, message.Chat.ID)
+	},
+}
+
+func handleStart(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+	// the main keyboard replaces the buttons of a dialog in progress
+	conversations.Delete(chatId)
+
+	p := printer(chatId)
+	msg := tgbotapi.NewMessage(chatId, p.T("start", p.T("help")))
+	msg.ReplyMarkup = GetMainKeyboard(chatId)
+	send(bot, chatId, msg)
+}
+
+func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	handler, ok := commands[message.Command()]
+	if !ok {
+		p := printer(message.Chat.ID)
+		reply(bot, message.Chat.ID, p.T("unknown_command", p.T("help")))
+		return
+	}
+	handler(bot, message)
+}
+
+func shorten(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
+
+func callbackData(action string, view string, noteId uuid.UUID) string {
+	return action + ":" + view + ":" + noteId.String()
+}
+
+func categoryNames(chatId int64) map[uuid.UUID]string {
+	names := make(map[uuid.UUID]string)
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		// names are decoration, the notes are still worth showing
+		log.Printf("categories for chat %d: %v", chatId, err)
+		return names
+	}
+	for _, category := range categories {
+		names[category.Id] = category.Name
+	}
+	return names
+}
+
+func startOfToday(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// userNow is the current time in the timezone of the user's settings, the
+// days of /today, /stats and /done begin at midnight of the user
+func userNow(chatId int64) (time.Time, error) {
+	settings, err := api.Settings(chatId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(digest.Location(settings.Timezone)), nil
+}
+
+// habitsLeftToday are the habits scheduled for today without a note of
+// their category today, a pending note is listed on its own
+func habitsLeftToday(notes []haloapi.Note, categories []haloapi.Category, now time.Time) []haloapi.Category {
+	today := startOfToday(now).Unix()
+	noted := make(map[uuid.UUID]bool)
+	for _, note := range notes {
+		if note.Created_at >= today {
+			noted[note.Category_id] = true
+		}
+	}
+
+	var habits []haloapi.Category
+	for _, category := range categories {
+		if category.Schedule_days != 0 && digest.ScheduledOn(category, now.Weekday()) && !noted[category.Id] {
+			habits = append(habits, category)
+		}
+	}
+	return habits
+}
+
+// renderToday lists the pending notes and the habits left for today with a button to complete each
+func renderToday(chatId int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	now, err := userNow(chatId)
+	if err != nil {
+		return "", nil, err
+	}
+	notes, err := api.NotesSince(chatId, startOfToday(now).Unix())
+	if err != nil {
+		return "", nil, err
+	}
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	p := printer(chatId)
+	pending := stats.Compute(notes, now).PendingToday
+	habits := habitsLeftToday(notes, categories, now)
+	if len(pending) == 0 && len(habits) == 0 {
+		return p.T("today.all_done"), nil, nil
+	}
+
+	names := make(map[uuid.UUID]string)
+	for _, category := range categories {
+		names[category.Id] = category.Name
+	}
+
+	var b strings.Builder
+	b.WriteString(p.T("today.title") + "\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, note := range pending {
+		line := fmt.Sprintf("%d. %s", i+1, note.Content)
+		if name, ok := names[note.Category_id]; ok {
+			line += " #" + name
+		}
+		b.WriteString(line + "\n")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"✅ "+shorten(note.Content, buttonTextLimit),
+				callbackData(actionDone, viewToday, note.Id),
+			),
+		))
+	}
+	for i, habit := range habits {
+		b.WriteString(fmt.Sprintf("%d. 🔁 %s\n", len(pending)+i+1, habit.Name))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				"✅ "+shorten(habit.Name, buttonTextLimit),
+				callbackData(actionDone, viewTodayHabit, habit.Id),
+			),
+		))
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return b.String(), &markup, nil
+}
+
+// renderList shows the latest notes with buttons to complete and delete them
+func renderList(chatId int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	notes, err := api.Notes(chatId, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	p := printer(chatId)
+	if len(notes) == 0 {
+		return p.T("list.empty"), nil, nil
+	}
+	if len(notes) > listSize {
+		notes = notes[:listSize]
+	}
+
+	names := categoryNames(chatId)
+
+	var b strings.Builder
+	b.WriteString(p.T("list.title") + "\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, note := range notes {
+		done := "⬜"
+		if note.Completed {
+			done = "✅"
+		}
+		line := fmt.Sprintf("%d. %s %s · %s", i+1, done, note.Content,
+			time.Unix(note.Created_at, 0).Format("02.01 15:04"))
+		if name, ok := names[note.Category_id]; ok {
+			line += " #" + name
+		}
+		b.WriteString(line + "\n")
+
+		var row []tgbotapi.InlineKeyboardButton
+		if !note.Completed {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✅ %d", i+1),
+				callbackData(actionDone, viewList, note.Id),
+			))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🗑 %d", i+1),
+			callbackData(actionDelete, viewList, note.Id),
+		))
+		rows = append(rows, row)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return b.String(), &markup, nil
+}
+
+func sendView(bot *tgbotapi.BotAPI, chatId int64, render func(int64) (string, *tgbotapi.InlineKeyboardMarkup, error)) {
+	text, markup, err := render(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatId, text)
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	send(bot, chatId, msg)
+}
+
+func handleToday(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	sendView(bot, message.Chat.ID, renderToday)
+}
+
+func handleList(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	sendView(bot, message.Chat.ID, renderList)
+}
+
+func formatWeek(p i18n.Printer, title string, week stats.Week) string {
+	line := p.T("stats.week", title, week.Done, week.Pending, week.Days)
+	if week.Tracked > 0 {
+		line += p.T("stats.tracked", p.Duration(week.Tracked))
+	}
+	return line
+}
+
+func handleStats(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+	now, err := userNow(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	notes, err := api.NotesSince(chatId, startOfToday(now).AddDate(0, 0, -statsHistoryDays).Unix())
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	p := printer(chatId)
+	summary := stats.Compute(notes, now)
+	text := strings.Join([]string{
+		formatWeek(p, p.T("stats.this_week"), summary.Week),
+		formatWeek(p, p.T("stats.last_week"), summary.LastWeek),
+		p.T("stats.streak", p.N("duration.days", summary.CurrentStreak), p.N("duration.days", summary.LongestStreak)),
+	}, "\n")
+	reply(bot, chatId, text)
+}
+
+// findCategory matches the category name case-insensitively
+func findCategory(chatId int64, name string) (haloapi.Category, bool, error) {
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		return haloapi.Category{}, false, err
+	}
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, name) {
+			return category, true, nil
+		}
+	}
+	return haloapi.Category{}, false, nil
+}
+
+func handleAdd(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+	text := strings.TrimSpace(message.CommandArguments())
+	if text == "" {
+		reply(bot, chatId, printer(chatId).T("add.usage"))
+		return
+	}
+
+	note := haloapi.Note{Content: text}
+	if tag, rest, ok := strings.Cut(text, " "); ok && strings.HasPrefix(tag, "#") {
+		category, found, err := findCategory(chatId, strings.TrimPrefix(tag, "#"))
+		if err != nil {
+			replyApiError(bot, chatId, err)
+			return
+		}
+		if found && strings.TrimSpace(rest) != "" {
+			note.Category_id = category.Id
+			note.Content = strings.TrimSpace(rest)
+		}
+	}
+
+	note, err := api.AddNote(chatId, note)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	reply(bot, chatId, printer(chatId).T("add.added", note.Content))
+}
+
+// handleDone completes a pending note of today matching the habit by content
+// or category, without one a completed note is recorded right away
+func handleDone(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+	habit := strings.TrimSpace(message.CommandArguments())
+	if habit == "" {
+		reply(bot, chatId, printer(chatId).T("done.usage"))
+		return
+	}
+
+	now, err := userNow(chatId)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	notes, err := api.NotesSince(chatId, startOfToday(now).Unix())
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	category, found, err := findCategory(chatId, habit)
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+
+	for _, note := range stats.Compute(notes, now).PendingToday {
+		if strings.EqualFold(note.Content, habit) || (found && note.Category_id == category.Id) {
+			note.Completed = true
+			if _, err := api.SaveNote(chatId, note); err != nil {
+				replyApiError(bot, chatId, err)
+				return
+			}
+			reply(bot, chatId, printer(chatId).T("done.marked", note.Content))
+			return
+		}
+	}
+
+	note := haloapi.Note{Content: habit, Completed: true}
+	if found {
+		note.Content = category.Name
+		note.Category_id = category.Id
+	}
+	if _, err := api.AddNote(chatId, note); err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	reply(bot, chatId, printer(chatId).T("done.recorded", note.Content))
+}
+
+func answerCallback(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, text string) {
+	if _, err := bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		log.Printf("answer callback %s: %v", query.ID, err)
+	}
+}
+
+// handleCallback applies an inline button of /today, /list, /settings or a reminder and redraws the message
+func handleCallback(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		answerCallback(bot, query, "")
+		return
+	}
+	chatId := query.Message.Chat.ID
+
+	parts := strings.SplitN(query.Data, ":", 3)
+	if len(parts) != 3 {
+		answerCallback(bot, query, "")
+		return
+	}
+	action, view := parts[0], parts[1]
+	if view == viewSettings {
+		handleSettingsCallback(bot, query, action, parts[2])
+		return
+	}
+
+	noteId, err := uuid.Parse(parts[2])
+	if err != nil {
+		answerCallback(bot, query, "")
+		return
+	}
+
+	if view == viewReminder || view == viewHabitReminder {
+		handleReminderCallback(bot, query, action, view, noteId)
+		return
+	}
+
+	p := printer(chatId)
+	var done string
+	switch {
+	case action == actionDone && view == viewTodayHabit:
+		err = completeHabit(chatId, noteId)
+		done = p.T("callback.done")
+	case action == actionDone:
+		err = completeNote(chatId, noteId)
+		done = p.T("callback.done")
+	case action == actionDelete:
+		err = api.DeleteNote(chatId, noteId)
+		done = p.T("callback.deleted")
+	default:
+		answerCallback(bot, query, "")
+		return
+	}
+
+	switch {
+	case errors.Is(err, haloapi.ErrNoteGone):
+		answerCallback(bot, query, p.T("error.note_gone"))
+	case errors.Is(err, errHabitGone):
+		answerCallback(bot, query, p.T("error.habit_gone"))
+	case errors.Is(err, haloapi.ErrNotLinked):
+		answerCallback(bot, query, p.T("error.not_linked_short"))
+		return
+	case err != nil:
+		log.Printf("%s note %s for chat %d: %v", action, noteId, chatId, err)
+		answerCallback(bot, query, p.T("error.failed"))
+		return
+	default:
+		answerCallback(bot, query, done)
+	}
+
+	render := renderList
+	if view == viewToday || view == viewTodayHabit {
+		render = renderToday
+	}
+	text, markup, err := render(chatId)
+	if err != nil {
+		log.Printf("redraw %s for chat %d: %v", view, chatId, err)
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatId, query.Message.MessageID, text)
+	edit.ReplyMarkup = markup
+	send(bot, chatId, edit)
+}
+
+func completeNote(chatId int64, noteId uuid.UUID) error {
+	note, err := api.FindNote(chatId, noteId)
+	if err != nil {
+		return err
+	}
+	if note.Completed {
+		return nil
+	}
+	note.Completed = true
+	_, err = api.SaveNote(chatId, note)
+	return err
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+
+	"github.com/google/uuid"
+)
+
+func TestAddAndToday(t *testing.T) {
+	chat := newTestChat(t, 301, "en")
+	sport := haloapi.Category{Id: uuid.New(), Name: "Sport"}
+	chat.halo.categories = []haloapi.Category{sport}
+
+	chat.expect("/today", "Everything is done for today")
+	chat.expect("/add", "/add <text>")
+	chat.expect("/add Call mom", "Added: Call mom")
+	chat.expect("/add #sport Morning run", "Added: Morning run")
+	chat.expect("/add #unknown tag", "Added: #unknown tag")
+
+	if len(chat.halo.notes) != 3 || chat.halo.notes[1].Category_id != sport.Id || chat.halo.notes[1].Content != "Morning run" {
+		t.Fatalf("notes = %+v", chat.halo.notes)
+	}
+
+	today := chat.expect("/today", "Left for today:")
+	for _, want := range []string{"Call mom", "Morning run #Sport", "#unknown tag"} {
+		if !strings.Contains(today.Text, want) {
+			t.Errorf("/today misses %q:\n%s", want, today.Text)
+		}
+	}
+	if len(today.Inline) != 3 {
+		t.Fatalf("/today buttons = %v, want one per note", today.Inline)
+	}
+
+	// the button completes the note and redraws the list without it
+	sent := chat.press(today.Inline[0])
+	if len(sent) != 1 || sent[0].Method != "editMessageText" || len(sent[0].Inline) != 2 {
+		t.Fatalf("pressing done sent %+v", sent)
+	}
+	id, _ := uuid.Parse(today.Inline[0][strings.LastIndex(today.Inline[0], ":")+1:])
+	if note, _ := chat.halo.note(id); !note.Completed {
+		t.Errorf("note after the done button = %+v", note)
+	}
+}
+
+func TestDone(t *testing.T) {
+	chat := newTestChat(t, 302, "en")
+	read := haloapi.Category{Id: uuid.New(), Name: "Read"}
+	chat.halo.categories = []haloapi.Category{read}
+
+	now := time.Now().Unix()
+	pending := haloapi.Note{Id: uuid.New(), Category_id: read.Id, Content: "Chapter 3", Created_at: now, Updated_at: now}
+	chat.halo.notes = []haloapi.Note{pending}
+
+	chat.expect("/done", "/done <habit>")
+	chat.expect("/done read", "Marked: Chapter 3")
+	if note, _ := chat.halo.note(pending.Id); !note.Completed {
+		t.Errorf("pending note after /done = %+v", note)
+	}
+
+	// without a pending note of the habit a completed one is recorded
+	chat.expect("/done read", "Recorded as done: Read")
+	chat.expect("/done Water", "Recorded as done: Water")
+	if len(chat.halo.notes) != 3 {
+		t.Fatalf("notes = %+v", chat.halo.notes)
+	}
+	if recorded := chat.halo.notes[1]; recorded.Category_id != read.Id || !recorded.Completed {
+		t.Errorf("recorded habit = %+v", recorded)
+	}
+	if recorded := chat.halo.notes[2]; recorded.Category_id != uuid.Nil || recorded.Content != "Water" || !recorded.Completed {
+		t.Errorf("recorded note = %+v", recorded)
+	}
+}
+
+func TestList(t *testing.T) {
+	chat := newTestChat(t, 303, "en")
+
+	chat.expect("/list", "No notes yet")
+
+	now := time.Now().Unix()
+	done := haloapi.Note{Id: uuid.New(), Content: "Plan the week", Created_at: now - 60, Completed: true}
+	open := haloapi.Note{Id: uuid.New(), Content: "Pay rent", Created_at: now}
+	chat.halo.notes = []haloapi.Note{done, open}
+
+	list := chat.expect("/list", "Latest notes:")
+	if !strings.Contains(list.Text, "1. ⬜ Pay rent") || !strings.Contains(list.Text, "2. ✅ Plan the week") {
+		t.Errorf("/list =\n%s", list.Text)
+	}
+	want := []string{
+		callbackData(actionDone, viewList, open.Id),
+		callbackData(actionDelete, viewList, open.Id),
+		callbackData(actionDelete, viewList, done.Id),
+	}
+	if !slices.Equal(list.Inline, want) {
+		t.Fatalf("/list buttons = %v, want %v", list.Inline, want)
+	}
+
+	sent := chat.press(callbackData(actionDelete, viewList, done.Id))
+	if len(sent) != 1 || strings.Contains(sent[0].Text, "Plan the week") {
+		t.Fatalf("deleting redrew %+v", sent)
+	}
+	if _, found := chat.halo.note(done.Id); found {
+		t.Error("the deleted note is still stored")
+	}
+
+	// a note deleted meanwhile, e.g. from tCli, only redraws the list
+	chat.halo.notes = nil
+	sent = chat.press(callbackData(actionDone, viewList, open.Id))
+	if len(sent) != 1 || !strings.Contains(sent[0].Text, "No notes yet") {
+		t.Errorf("completing a deleted note sent %+v", sent)
+	}
+	sent = chat.press(callbackData(actionDelete, viewList, open.Id))
+	if len(sent) != 1 || !strings.Contains(sent[0].Text, "No notes yet") {
+		t.Errorf("deleting a deleted note sent %+v", sent)
+	}
+}
+
+func TestTodayInTimezoneOfUser(t *testing.T) {
+	chat := newTestChat(t, 304, "en")
+	chat.halo.settings.Timezone = "Pacific/Kiritimati"
+
+	loc, _ := time.LoadLocation("Pacific/Kiritimati")
+	midnight := startOfToday(time.Now().In(loc)).Unix()
+	chat.halo.notes = []haloapi.Note{
+		{Id: uuid.New(), Content: "Before midnight of the user", Created_at: midnight - 60},
+	}
+
+	chat.expect("/today", "Everything is done for today")
+	chat.expect("/done Before midnight of the user", "Recorded as done")
+}
+
+func TestTodayListsHabitsLeft(t *testing.T) {
+	chat := newTestChat(t, 305, "en")
+
+	today := 1 << ((int(time.Now().UTC().Weekday()) + 6) % 7)
+	daily := haloapi.Category{Id: uuid.New(), Name: "Stretching", Schedule_days: 1<<7 - 1}
+	otherDays := haloapi.Category{Id: uuid.New(), Name: "Swimming", Schedule_days: (1<<7 - 1) &^ today}
+	plain := haloapi.Category{Id: uuid.New(), Name: "Errands"}
+	chat.halo.categories = []haloapi.Category{daily, otherDays, plain}
+
+	list := chat.expect("/today", "Left for today:")
+	if !strings.Contains(list.Text, "1. 🔁 Stretching") || strings.Contains(list.Text, "Swimming") || strings.Contains(list.Text, "Errands") {
+		t.Errorf("/today =\n%s", list.Text)
+	}
+	button := callbackData(actionDone, viewTodayHabit, daily.Id)
+	if !slices.Equal(list.Inline, []string{button}) {
+		t.Fatalf("/today buttons = %v, want %v", list.Inline, button)
+	}
+
+	sent := chat.press(button)
+	if len(sent) != 1 || !strings.Contains(sent[0].Text, "Everything is done for today") {
+		t.Fatalf("completing the habit redrew %+v", sent)
+	}
+	if len(chat.halo.notes) != 1 || chat.halo.notes[0].Category_id != daily.Id || !chat.halo.notes[0].Completed {
+		t.Errorf("notes after the habit was done = %+v", chat.halo.notes)
+	}
+}
+
+func TestUnlink(t *testing.T) {
+	chat := newTestChat(t, 306, "en")
+	chat.halo.locale = "ru"
+
+	chat.expect("/today", "На сегодня")
+	// the locale of the profile is dropped with the link
+	chat.expect("/unlink", "The chat is unlinked")
+	chat.expect("/today", "Link the chat")
+	chat.expect("/unlink", "Link the chat")
+}
+
+func TestUnlinkedLocaleCached(t *testing.T) {
+	chat := newTestChat(t, 307, "en")
+	chat.halo.unlinked = true
+
+	// every message of an unlinked chat would ask for the profile otherwise
+	chat.expect("/today", "Link the chat")
+	before := chat.halo.tokens
+	chat.expect("/today", "Link the chat")
+	if asked := chat.halo.tokens - before; asked != 1 {
+		t.Errorf("the second message asked for a token %d times, want once for /today only", asked)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"tgBot/conversation"
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// conversationTTL is how long a dialog waits for the next answer
+const conversationTTL = 15 * time.Minute
+
+var conversations = conversation.NewStore(conversationTTL)
+
+// flow is a multi-step dialog, the step of each chat is kept in conversations
+type flow struct {
+	// ask sends the question of the current step
+	ask func(bot *tgbotapi.BotAPI, chatId int64, state conversation.State)
+	// answer handles the reply to the current step, it saves the next step or ends the dialog
+	answer func(bot *tgbotapi.BotAPI, chatId int64, state conversation.State, text string)
+}
+
+var flows = map[string]flow{
+	flowHabit:    {ask: askHabit, answer: answerHabit},
+	flowTimezone: {ask: askTimezone, answer: answerTimezone},
+}
+
+// startConversation begins the flow at its first step, a dialog in progress is dropped
+func startConversation(bot *tgbotapi.BotAPI, chatId int64, state conversation.State) {
+	conversations.Set(chatId, state)
+	flows[state.Flow].ask(bot, chatId, state)
+}
+
+// flowKeyboard adds the back and cancel buttons under the answers of a step
+func flowKeyboard(p i18n.Printer, rows ...[]tgbotapi.KeyboardButton) tgbotapi.ReplyKeyboardMarkup {
+	rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+		tgbotapi.NewKeyboardButton(p.T("button.back")),
+		tgbotapi.NewKeyboardButton(p.T("button.cancel")),
+	))
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+// handleConversation passes the message to the dialog of the chat, false without one
+func handleConversation(bot *tgbotapi.BotAPI, message *tgbotapi.Message) bool {
+	chatId := message.Chat.ID
+	state, ok := conversations.Get(chatId)
+	if !ok {
+		return false
+	}
+
+	current, known := flows[state.Flow]
+	if !known {
+		conversations.Delete(chatId)
+		return false
+	}
+
+	text := strings.TrimSpace(message.Text)
+	switch {
+	case isButton(text, "button.cancel"):
+		cancelConversation(bot, chatId)
+	case isButton(text, "button.back"):
+		backConversation(bot, chatId)
+	default:
+		current.answer(bot, chatId, state, text)
+	}
+	return true
+}
+
+// cancelConversation drops the dialog and brings the main keyboard back
+func cancelConversation(bot *tgbotapi.BotAPI, chatId int64) {
+	p := printer(chatId)
+	if _, ok := conversations.Get(chatId); !ok {
+		reply(bot, chatId, p.T("conversation.none"))
+		return
+	}
+	conversations.Delete(chatId)
+
+	msg := tgbotapi.NewMessage(chatId, p.T("conversation.cancelled"))
+	msg.ReplyMarkup = GetMainKeyboard(chatId)
+	send(bot, chatId, msg)
+}
+
+// backConversation asks the previous question again, at the first step the same one
+func backConversation(bot *tgbotapi.BotAPI, chatId int64) {
+	state, ok := conversations.Get(chatId)
+	if !ok {
+		reply(bot, chatId, printer(chatId).T("conversation.none"))
+		return
+	}
+
+	state, _ = state.Back()
+	conversations.Set(chatId, state)
+	flows[state.Flow].ask(bot, chatId, state)
+}
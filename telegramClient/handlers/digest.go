@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"tgBot/digest"
+	"tgBot/haloapi"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// SendDigest builds the digest from the notes of the user and sends it to
+// every linked chat, now is in the time zone of the user; an error means no
+// chat got the digest and asks the scheduler to try again
+func SendDigest(bot *tgbotapi.BotAPI, subscriber haloapi.DigestSubscriber, kind digest.Kind, now time.Time) error {
+	chats, err := api.Chats(subscriber.User_id)
+	if err != nil {
+		return fmt.Errorf("chats: %w", err)
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+
+	// every chat acts as the same user, the first one reads the notes
+	notes, err := api.NotesSince(chats[0], startOfToday(now).AddDate(0, 0, -digest.HistoryDays).Unix())
+	if err != nil {
+		return fmt.Errorf("notes: %w", err)
+	}
+	categories, err := api.Categories(chats[0])
+	if err != nil {
+		return fmt.Errorf("categories: %w", err)
+	}
+
+	// a chat which got the digest already would get it twice on a retry, so
+	// only a digest no chat got is failed
+	var sendErr error
+	delivered := 0
+	for _, chatId := range chats {
+		p := printer(chatId)
+		text := digest.Daily(p, notes, categories, now)
+		if kind == digest.KindWeekly {
+			text = digest.Weekly(p, notes, categories, now)
+		}
+		if _, err := bot.Send(tgbotapi.NewMessage(chatId, text)); err != nil {
+			log.Printf("send to chat %d: %v", chatId, err)
+			sendErr = err
+			continue
+		}
+		delivered++
+	}
+	if delivered == 0 {
+		return fmt.Errorf("send: %w", sendErr)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/google/uuid"
+)
+
+const testToken = "123:test"
+
+// sentMessage is a sendMessage or editMessageText call of the bot, Keyboard
+// holds the reply keyboard buttons and Inline the inline button callbacks
+type sentMessage struct {
+	Method   string
+	Text     string
+	Keyboard []string
+	Inline   []string
+}
+
+// fakeTelegram records the messages the bot sends
+type fakeTelegram struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+func (f *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/bot"+testToken+"/")
+	r.ParseForm()
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "first_name": "Halo", "username": "halo_test_bot"}
+	case "sendMessage", "editMessageText":
+		message := sentMessage{Method: method, Text: r.PostForm.Get("text")}
+		var markup struct {
+			Keyboard [][]struct {
+				Text string `json:"text"`
+			} `json:"keyboard"`
+			Inline [][]struct {
+				Data string `json:"callback_data"`
+			} `json:"inline_keyboard"`
+		}
+		if json.Unmarshal([]byte(r.PostForm.Get("reply_markup")), &markup) == nil {
+			for _, row := range markup.Keyboard {
+				for _, button := range row {
+					message.Keyboard = append(message.Keyboard, button.Text)
+				}
+			}
+			for _, row := range markup.Inline {
+				for _, button := range row {
+					message.Inline = append(message.Inline, button.Data)
+				}
+			}
+		}
+		f.mu.Lock()
+		f.sent = append(f.sent, message)
+		f.mu.Unlock()
+		result = map[string]any{"message_id": 1, "date": 0, "chat": map[string]any{"id": 1}, "text": message.Text}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// fakeHalo is the backend of one linked user with categories, notes and a profile locale
+type fakeHalo struct {
+	mu         sync.Mutex
+	categories []haloapi.Category
+	created    []haloapi.Category
+	notes      []haloapi.Note
+	chats      []int64
+	unlinked   bool
+	// tokens counts the token requests of the bot
+	tokens int
+	// stale are chats listed for the user whose link is gone already
+	stale    []int64
+	locale   string
+	settings haloapi.Settings
+}
+
+// note returns the stored note by id
+func (f *fakeHalo) note(id uuid.UUID) (haloapi.Note, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, note := range f.notes {
+		if note.Id == id {
+			return note, true
+		}
+	}
+	return haloapi.Note{}, false
+}
+
+// notePage is a page of the notes newest first, like note_service pages them
+func (f *fakeHalo) notePage(page int) []haloapi.Note {
+	notes := slices.Clone(f.notes)
+	slices.SortStableFunc(notes, func(a, b haloapi.Note) int { return int(b.Created_at - a.Created_at) })
+
+	start := (page - 1) * 10
+	if page < 1 || start >= len(notes) {
+		return []haloapi.Note{}
+	}
+	return notes[start:min(start+10, len(notes))]
+}
+
+func (f *fakeHalo) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	route := r.Method + " " + r.URL.Path
+	noteId, byId := strings.CutPrefix(route, "GET /api/note/")
+	if byId {
+		route = "GET /api/note/{note_id}"
+	}
+
+	var result any
+	switch route {
+	case "POST /internal/telegram/token":
+		f.tokens++
+		var request map[string]int64
+		json.NewDecoder(r.Body).Decode(&request)
+		if f.unlinked || slices.Contains(f.stale, request["chat_id"]) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		result = haloapi.BotToken{Token: "bot", User_id: uuid.New(), Expires_at: time.Now().Add(time.Hour).Unix()}
+	case "POST /internal/telegram/unlink":
+		var request map[string]int64
+		json.NewDecoder(r.Body).Decode(&request)
+		if f.unlinked {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		f.unlinked = true
+		result = request
+	case "POST /internal/telegram/chats":
+		result = haloapi.TelegramChats{Chat_ids: f.chats}
+	case "GET /api/user/profile":
+		result = haloapi.Profile{Locale: f.locale}
+	case "GET /api/user/settings":
+		result = f.settings
+	case "PUT /api/user/settings":
+		json.NewDecoder(r.Body).Decode(&f.settings)
+		result = f.settings
+	case "GET /api/category":
+		result = f.categories
+		if r.URL.Query().Get("page") != "1" {
+			result = []haloapi.Category{}
+		}
+	case "POST /api/category":
+		var category haloapi.Category
+		json.NewDecoder(r.Body).Decode(&category)
+		for _, existing := range f.categories {
+			if existing.Name == category.Name {
+				http.Error(w, "Conflict", http.StatusConflict)
+				return
+			}
+		}
+		category.Id = uuid.New()
+		f.categories = append(f.categories, category)
+		f.created = append(f.created, category)
+		result = category
+	case "GET /api/note":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		result = f.notePage(page)
+	case "GET /api/note/{note_id}":
+		i := slices.IndexFunc(f.notes, func(n haloapi.Note) bool { return n.Id.String() == noteId })
+		if i < 0 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		result = f.notes[i]
+	case "POST /api/note":
+		var note haloapi.Note
+		json.NewDecoder(r.Body).Decode(&note)
+		f.notes = append(f.notes, note)
+		w.WriteHeader(http.StatusOK)
+		return
+	case "POST /api/note/sync":
+		var request struct {
+			Notes []haloapi.Note `json:"notes"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		response := map[string][]any{"applied": {}, "conflicts": {}}
+		for _, note := range request.Notes {
+			i := slices.IndexFunc(f.notes, func(n haloapi.Note) bool { return n.Id == note.Id })
+			if i < 0 {
+				response["conflicts"] = append(response["conflicts"], map[string]any{"note_id": note.Id, "reason": "deleted"})
+				continue
+			}
+			f.notes[i] = note
+			response["applied"] = append(response["applied"], note.Id)
+		}
+		result = response
+	case "DELETE /api/note":
+		var request struct {
+			Note_id uuid.UUID `json:"note_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		i := slices.IndexFunc(f.notes, func(n haloapi.Note) bool { return n.Id == request.Note_id })
+		if i < 0 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		f.notes = slices.Delete(f.notes, i, i+1)
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// testChat scripts the updates of one chat against the fakes
+type testChat struct {
+	t        *testing.T
+	bot      *tgbotapi.BotAPI
+	telegram *fakeTelegram
+	halo     *fakeHalo
+	id       int64
+	language string
+}
+
+func newTestChat(t *testing.T, id int64, language string) *testChat {
+	halo := &fakeHalo{chats: []int64{id}}
+	haloServer := httptest.NewServer(http.HandlerFunc(halo.serve))
+	t.Cleanup(haloServer.Close)
+	Init(haloapi.NewClient(haloServer.URL, haloServer.URL, haloServer.URL, "secret"))
+
+	fake := &fakeTelegram{}
+	telegramServer := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(telegramServer.Close)
+
+	bot, err := telegram.NewBot(testToken, telegramServer.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conversations.Delete(id)
+		activitiesMu.Lock()
+		delete(activities, id)
+		activitiesMu.Unlock()
+	})
+	return &testChat{t: t, bot: bot, telegram: fake, halo: halo, id: id, language: language}
+}
+
+// say handles the message as an update of the chat and returns what the bot sent back
+func (c *testChat) say(text string) []sentMessage {
+	c.t.Helper()
+
+	message := &tgbotapi.Message{
+		Chat: &tgbotapi.Chat{ID: c.id},
+		From: &tgbotapi.User{ID: int(c.id), LanguageCode: c.language},
+		Text: text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	return c.handle(tgbotapi.Update{Message: message})
+}
+
+// press handles an inline button of a message the bot sent before
+func (c *testChat) press(data string) []sentMessage {
+	c.t.Helper()
+
+	query := &tgbotapi.CallbackQuery{
+		ID:   "query",
+		From: &tgbotapi.User{ID: int(c.id), LanguageCode: c.language},
+		Message: &tgbotapi.Message{
+			MessageID: 1,
+			Chat:      &tgbotapi.Chat{ID: c.id},
+		},
+		Data: data,
+	}
+
+	return c.handle(tgbotapi.Update{CallbackQuery: query})
+}
+
+// handle runs the update and returns what the bot sent meanwhile
+func (c *testChat) handle(update tgbotapi.Update) []sentMessage {
+	c.telegram.mu.Lock()
+	before := len(c.telegram.sent)
+	c.telegram.mu.Unlock()
+
+	HandleUpdate(c.bot, update)
+
+	c.telegram.mu.Lock()
+	defer c.telegram.mu.Unlock()
+	return append([]sentMessage(nil), c.telegram.sent[before:]...)
+}
+
+// expect checks the bot answered with one message containing the text
+func (c *testChat) expect(text string, contains string) sentMessage {
+	c.t.Helper()
+
+	sent := c.say(text)
+	if len(sent) != 1 {
+		c.t.Fatalf("%q: bot sent %d messages, want 1: %+v", text, len(sent), sent)
+	}
+	if !strings.Contains(sent[0].Text, contains) {
+		c.t.Fatalf("%q: bot answered %q, want it to contain %q", text, sent[0].Text, contains)
+	}
+	return sent[0]
+}
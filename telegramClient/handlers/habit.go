@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tgBot/conversation"
+	"tgBot/haloapi"
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// flowHabit creates a category with a schedule and a reminder time
+const flowHabit = "habit"
+
+const (
+	stepName     = "name"
+	stepSchedule = "schedule"
+	stepRemind   = "remind"
+)
+
+// habitNameLimit keeps habit names readable on the keyboard buttons
+const habitNameLimit = 64
+
+// schedules have a bit per weekday starting with monday
+const (
+	allDays  = 1<<7 - 1
+	weekdays = 1<<5 - 1
+	weekends = allDays &^ weekdays
+)
+
+var weekdayKeys = [7]string{
+	"weekday.mon", "weekday.tue", "weekday.wed", "weekday.thu", "weekday.fri", "weekday.sat", "weekday.sun",
+}
+
+func handleHabit(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	startConversation(bot, message.Chat.ID, conversation.Start(flowHabit, stepName))
+}
+
+// parseSchedule reads a schedule button or weekdays like "mon wed fri" in any locale
+func parseSchedule(text string) (int, bool) {
+	switch {
+	case isButton(text, "button.every_day"):
+		return allDays, true
+	case isButton(text, "button.weekdays"):
+		return weekdays, true
+	case isButton(text, "button.weekends"):
+		return weekends, true
+	}
+
+	days := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return r == ' ' || r == ','
+	}) {
+		day, ok := parseWeekday(word)
+		if !ok {
+			return 0, false
+		}
+		days |= 1 << day
+	}
+	return days, days != 0
+}
+
+func parseWeekday(word string) (int, bool) {
+	for day, key := range weekdayKeys {
+		for _, locale := range i18n.Supported {
+			if word == strings.ToLower(i18n.NewPrinter(locale).T(key)) {
+				return day, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func formatSchedule(p i18n.Printer, days int) string {
+	switch days {
+	case allDays:
+		return p.T("schedule.every_day")
+	case weekdays:
+		return p.T("schedule.weekdays")
+	case weekends:
+		return p.T("schedule.weekends")
+	}
+
+	var names []string
+	for day, key := range weekdayKeys {
+		if days&(1<<day) != 0 {
+			names = append(names, p.T(key))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func askHabit(bot *tgbotapi.BotAPI, chatId int64, state conversation.State) {
+	p := printer(chatId)
+
+	var msg tgbotapi.MessageConfig
+	switch state.Step {
+	case stepName:
+		msg = tgbotapi.NewMessage(chatId, p.T("habit.ask_name"))
+		msg.ReplyMarkup = flowKeyboard(p)
+	case stepSchedule:
+		msg = tgbotapi.NewMessage(chatId, p.T("habit.ask_schedule"))
+		msg.ReplyMarkup = flowKeyboard(p,
+			tgbotapi.NewKeyboardButtonRow(
+				tgbotapi.NewKeyboardButton(p.T("button.every_day")),
+				tgbotapi.NewKeyboardButton(p.T("button.weekdays")),
+			),
+			tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(p.T("button.weekends"))),
+		)
+	case stepRemind:
+		msg = tgbotapi.NewMessage(chatId, p.T("habit.ask_remind"))
+		msg.ReplyMarkup = flowKeyboard(p,
+			tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(p.T("button.no_reminder"))),
+		)
+	default:
+		conversations.Delete(chatId)
+		return
+	}
+	send(bot, chatId, msg)
+}
+
+// answerHabit checks the answer of the step, a wrong one is asked for again
+func answerHabit(bot *tgbotapi.BotAPI, chatId int64, state conversation.State, text string) {
+	p := printer(chatId)
+
+	switch state.Step {
+	case stepName:
+		if text == "" || utf8.RuneCountInString(text) > habitNameLimit {
+			reply(bot, chatId, p.T("habit.name_invalid", habitNameLimit))
+			return
+		}
+		_, found, err := findCategory(chatId, text)
+		if err != nil {
+			replyApiError(bot, chatId, err)
+			return
+		}
+		if found {
+			reply(bot, chatId, p.T("habit.name_taken", text))
+			return
+		}
+		state.Answers[stepName] = text
+
+	case stepSchedule:
+		days, ok := parseSchedule(text)
+		if !ok {
+			reply(bot, chatId, p.T("habit.schedule_invalid"))
+			return
+		}
+		state.Answers[stepSchedule] = strconv.Itoa(days)
+
+	case stepRemind:
+		remind := ""
+		if !isButton(text, "button.no_reminder") {
+			at, err := time.Parse("15:04", text)
+			if err != nil {
+				reply(bot, chatId, p.T("habit.remind_invalid", p.T("button.no_reminder")))
+				return
+			}
+			remind = at.Format("15:04")
+		}
+		state.Answers[stepRemind] = remind
+		createHabit(bot, chatId, state)
+		return
+	}
+
+	next := map[string]string{stepName: stepSchedule, stepSchedule: stepRemind}[state.Step]
+	state = state.Next(next)
+	conversations.Set(chatId, state)
+	askHabit(bot, chatId, state)
+}
+
+// createHabit saves the answers as a category, the dialog stays open when the backend fails
+func createHabit(bot *tgbotapi.BotAPI, chatId int64, state conversation.State) {
+	p := printer(chatId)
+	days, _ := strconv.Atoi(state.Answers[stepSchedule])
+
+	category, err := api.AddCategory(chatId, haloapi.Category{
+		Name:          state.Answers[stepName],
+		Schedule_days: days,
+		Remind_time:   state.Answers[stepRemind],
+	})
+	if errors.Is(err, haloapi.ErrCategoryExists) {
+		// the name was taken meanwhile, e.g. from tCli
+		reply(bot, chatId, p.T("habit.name_taken", state.Answers[stepName]))
+		state = conversation.Start(flowHabit, stepName)
+		conversations.Set(chatId, state)
+		askHabit(bot, chatId, state)
+		return
+	}
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	conversations.Delete(chatId)
+
+	remind := p.T("habit.no_reminder")
+	if category.Remind_time != "" {
+		remind = p.T("habit.remind_at", category.Remind_time)
+	}
+	msg := tgbotapi.NewMessage(chatId,
+		p.T("habit.created", category.Name, formatSchedule(p, category.Schedule_days), remind))
+	msg.ReplyMarkup = GetMainKeyboard(chatId)
+	send(bot, chatId, msg)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"tgBot/haloapi"
+)
+
+func TestHabitFlow(t *testing.T) {
+	chat := newTestChat(t, 101, "en")
+
+	asked := chat.expect("/habit", "What habit")
+	if !slices.Contains(asked.Keyboard, "✖ Cancel") {
+		t.Errorf("name step keyboard = %v, want a cancel button", asked.Keyboard)
+	}
+
+	asked = chat.expect("Stretching", "On which days")
+	if !slices.Contains(asked.Keyboard, "Weekdays") {
+		t.Errorf("schedule step keyboard = %v", asked.Keyboard)
+	}
+	chat.expect("Weekdays", "When should I remind")
+
+	done := chat.expect("8:30", "Habit «Stretching» created: on weekdays, reminder at 08:30")
+	if !slices.Contains(done.Keyboard, startPrefix+"Stretching") {
+		t.Errorf("keyboard after the flow = %v, want the new habit", done.Keyboard)
+	}
+
+	want := haloapi.Category{Name: "Stretching", Schedule_days: weekdays, Remind_time: "08:30"}
+	if len(chat.halo.created) != 1 || chat.halo.created[0].Name != want.Name ||
+		chat.halo.created[0].Schedule_days != want.Schedule_days || chat.halo.created[0].Remind_time != want.Remind_time {
+		t.Errorf("created = %+v, want %+v", chat.halo.created, want)
+	}
+	if _, ok := conversations.Get(chat.id); ok {
+		t.Error("the dialog is still open after the habit was created")
+	}
+}
+
+func TestHabitFlowBack(t *testing.T) {
+	chat := newTestChat(t, 102, "en")
+
+	chat.expect("/habit", "What habit")
+	chat.expect("Read", "On which days")
+	chat.expect("⬅ Back", "What habit")
+	chat.expect("Read books", "On which days")
+	chat.expect("mon, wed fri", "When should I remind")
+	chat.expect("/back", "On which days")
+	chat.expect("sat sun", "When should I remind")
+	chat.expect("No reminder", "Habit «Read books» created: on weekends, no reminder")
+
+	if len(chat.halo.created) != 1 || chat.halo.created[0].Name != "Read books" {
+		t.Errorf("created = %+v", chat.halo.created)
+	}
+}
+
+func TestHabitFlowCancel(t *testing.T) {
+	chat := newTestChat(t, 103, "en")
+
+	chat.expect("/habit", "What habit")
+	chat.expect("Water", "On which days")
+	cancelled := chat.expect("✖ Cancel", "Cancelled")
+	if !slices.Contains(cancelled.Keyboard, "✅ I'm done") {
+		t.Errorf("keyboard after cancel = %v, want the main keyboard", cancelled.Keyboard)
+	}
+
+	if sent := chat.say("Every day"); len(sent) != 0 {
+		t.Errorf("an answer after cancel was handled: %+v", sent)
+	}
+	chat.expect("/cancel", "no dialog in progress")
+	if len(chat.halo.created) != 0 {
+		t.Errorf("cancelled habit was created: %+v", chat.halo.created)
+	}
+}
+
+func TestHabitFlowRepeatsWrongAnswers(t *testing.T) {
+	chat := newTestChat(t, 104, "en")
+	chat.halo.categories = []haloapi.Category{{Name: "Water"}}
+
+	chat.expect("/habit", "What habit")
+	chat.expect("water", "You already have «water»")
+	chat.expect("Run", "On which days")
+	chat.expect("someday", "I did not get the days")
+	chat.expect("tue", "When should I remind")
+	chat.expect("25:00", "Send the time as HH:MM")
+
+	// commands work in the middle of the dialog and keep it open
+	chat.expect("/unknown", "I don't know this command")
+	chat.expect("07:05", "Habit «Run» created: Tue, reminder at 07:05")
+}
+
+func TestHabitFlowRussian(t *testing.T) {
+	chat := newTestChat(t, 105, "ru")
+
+	chat.expect("/habit", "Какую привычку")
+	chat.expect("Зарядка", "В какие дни")
+	chat.expect("пн ср пт", "Когда напоминать")
+	chat.expect("Без напоминания", "Привычка «Зарядка» заведена: пн, ср, пт, без напоминания")
+}
+
+func TestHabitFlowProfileLocale(t *testing.T) {
+	chat := newTestChat(t, 106, "en")
+	chat.halo.locale = "ru"
+
+	chat.expect("/habit", "Какую привычку")
+}
+
+func TestParseSchedule(t *testing.T) {
+	cases := map[string]int{
+		"Every day":   allDays,
+		"По будням":   weekdays,
+		"mon":         1,
+		"Sun":         1 << 6,
+		"пн,вт":       3,
+		"mon Mon пн":  1,
+		"fri sat sun": 1<<4 | weekends,
+	}
+	for text, want := range cases {
+		if got, ok := parseSchedule(text); !ok || got != want {
+			t.Errorf("parseSchedule(%q) = %b, %t, want %b", text, got, ok, want)
+		}
+	}
+	for _, text := range []string{"", "monday", "mon funday", ","} {
+		if _, ok := parseSchedule(text); ok {
+			t.Errorf("parseSchedule(%q) accepted", text)
+		}
+	}
+}
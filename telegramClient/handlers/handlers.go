@@ -1,28 +1,62 @@
 package handlers
 
 import (
+	"log"
+	"tgBot/haloapi"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/google/uuid"
 )
 
+var api *haloapi.Client
+
+// Init sets the backend client used by the handlers
+func Init(client *haloapi.Client) {
+	api = client
+}
+
+// send logs failures, the bot transport already retried rate limited requests
+func send(bot *tgbotapi.BotAPI, chatId int64, c tgbotapi.Chattable) {
+	if _, err := bot.Send(c); err != nil {
+		log.Printf("send to chat %d: %v", chatId, err)
+	}
+}
+
+// HandleUpdate routes commands, answers of a dialog in progress, keyboard
+// buttons and inline button callbacks; commands work in the middle of a dialog
 func HandleUpdate(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
+	rememberLanguage(update)
+
+	if update.CallbackQuery != nil {
+		handleCallback(bot, update.CallbackQuery)
+		return
+	}
 	if update.Message == nil {
 		return
 	}
 
-	switch update.Message.Text {
-	case "/start":
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Привет! Я помогу отслеживать твои действия.")
-		msg.ReplyMarkup = GetMainKeyboard()
-		bot.Send(msg)
-
-	case "🍽 Я начал есть":
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Окей, записал, что ты начал есть.")
-		bot.Send(msg)
-		// Тут запись в БД
-
-	case "✅ Я закончил":
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Хорошо, записал, что ты закончил.")
-		bot.Send(msg)
-		// Тут обновление записи в БД
+	if update.Message.IsCommand() {
+		handleCommand(bot, update.Message)
+		return
+	}
+
+	if handleConversation(bot, update.Message) {
+		return
+	}
+
+	chatId := update.Message.Chat.ID
+	text := update.Message.Text
+
+	switch {
+	case isButton(text, "button.meal"):
+		startActivity(bot, chatId, printer(chatId).T("activity.meal"), uuid.Nil)
+
+	case isButton(text, "button.finish"):
+		finishActivity(bot, chatId)
+
+	default:
+		if name, ok := categoryFromButton(text); ok {
+			handleStartCategory(bot, chatId, name)
+		}
 	}
 }
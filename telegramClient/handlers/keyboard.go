@@ -1,14 +1,79 @@
 package handlers
 
-import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+import (
+	"log"
+	"strings"
 
-func GetMainKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// startPrefix marks the buttons generated from the user's categories
+	startPrefix = "▶ "
+
+	buttonsPerRow = 2
+)
+
+// isButton matches the label in every locale, the keyboard of a chat
+// stays in the language it was sent in until the next /start
+func isButton(text string, key string) bool {
+	for _, locale := range i18n.Supported {
+		if text == i18n.NewPrinter(locale).T(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultKeyboard has the meal button of the first keyboard, chats still have it
+func defaultKeyboard(p i18n.Printer) tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("🍽 Я начал есть"),
+			tgbotapi.NewKeyboardButton(p.T("button.meal")),
 		),
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("✅ Я закончил"),
+			tgbotapi.NewKeyboardButton(p.T("button.finish")),
 		),
 	)
 }
+
+// GetMainKeyboard has a start button per category of the linked user and
+// falls back to the meal button for unlinked chats and users without categories
+func GetMainKeyboard(chatId int64) tgbotapi.ReplyKeyboardMarkup {
+	p := printer(chatId)
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		log.Printf("categories for chat %d keyboard: %v", chatId, err)
+		return defaultKeyboard(p)
+	}
+	if len(categories) == 0 {
+		return defaultKeyboard(p)
+	}
+
+	var rows [][]tgbotapi.KeyboardButton
+	var row []tgbotapi.KeyboardButton
+	for _, category := range categories {
+		row = append(row, tgbotapi.NewKeyboardButton(startPrefix+category.Name))
+		if len(row) == buttonsPerRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if row != nil {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(p.T("button.finish"))))
+
+	return tgbotapi.NewReplyKeyboard(rows...)
+}
+
+// categoryFromButton returns the category name of a generated start button
+func categoryFromButton(text string) (string, bool) {
+	if !strings.HasPrefix(text, startPrefix) {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(text, startPrefix))
+	return name, name != ""
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"tgBot/haloapi"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func handleLink(bot *tgbotapi.BotAPI, chatId int64) {
+	p := printer(chatId)
+	code, err := api.LinkCode(chatId)
+	if err != nil {
+		log.Printf("link code for chat %d: %v", chatId, err)
+		send(bot, chatId, tgbotapi.NewMessage(chatId, p.T("link.failed")))
+		return
+	}
+
+	text := p.T("link.code", code.Code, code.Code, p.Duration(time.Until(time.Unix(code.Expires_at, 0))))
+	send(bot, chatId, tgbotapi.NewMessage(chatId, text))
+}
+
+// handleUnlink detaches the chat from the account, what the bot keeps about the
+// user of the chat goes with it
+func handleUnlink(bot *tgbotapi.BotAPI, chatId int64) {
+	err := api.Unlink(chatId)
+	if err != nil {
+		log.Printf("unlink chat %d: %v", chatId, err)
+		if errors.Is(err, haloapi.ErrNotLinked) {
+			reply(bot, chatId, printer(chatId).T("error.not_linked"))
+			return
+		}
+		reply(bot, chatId, printer(chatId).T("unlink.failed"))
+		return
+	}
+
+	conversations.Delete(chatId)
+	activitiesMu.Lock()
+	delete(activities, chatId)
+	activitiesMu.Unlock()
+	forgetProfileLocale(chatId)
+
+	p := printer(chatId)
+	msg := tgbotapi.NewMessage(chatId, p.T("unlink.done"))
+	msg.ReplyMarkup = defaultKeyboard(p)
+	send(bot, chatId, msg)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// profileLocaleTTL is how long the locale of the profile is trusted, a
+// locale changed from tCli reaches the bot after at most this long
+const profileLocaleTTL = 30 * time.Minute
+
+// profileRetryTTL is how long an unlinked chat or a failed lookup is trusted,
+// short so a chat linked from tCli gets the locale of the profile soon
+const profileRetryTTL = time.Minute
+
+type chatLocale struct {
+	// profile is the locale the linked user picked, empty without a choice
+	profile string
+	// expiresAt is when the profile is asked for again
+	expiresAt time.Time
+	// language is the language_code telegram sent with the last update of the chat
+	language string
+}
+
+var (
+	localesMu sync.Mutex
+	locales   = make(map[int64]chatLocale)
+)
+
+// rememberLanguage keeps the telegram language of the sender as the fallback locale of the chat
+func rememberLanguage(update tgbotapi.Update) {
+	var chatId int64
+	var user *tgbotapi.User
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		chatId, user = update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From
+	case update.Message != nil:
+		chatId, user = update.Message.Chat.ID, update.Message.From
+	}
+	if user == nil || user.LanguageCode == "" {
+		return
+	}
+
+	localesMu.Lock()
+	locale := locales[chatId]
+	locale.language = user.LanguageCode
+	locales[chatId] = locale
+	localesMu.Unlock()
+}
+
+// profileLocale returns the locale of the linked user's profile, unlinked chats have none
+func profileLocale(chatId int64) string {
+	localesMu.Lock()
+	locale := locales[chatId]
+	localesMu.Unlock()
+
+	if time.Now().Before(locale.expiresAt) {
+		return locale.profile
+	}
+
+	result, ttl := locale.profile, profileLocaleTTL
+	profile, err := api.Profile(chatId)
+	switch {
+	case errors.Is(err, haloapi.ErrNotLinked):
+		result, ttl = "", profileRetryTTL
+	case err != nil:
+		// keep the old choice until the next try
+		log.Printf("profile of chat %d: %v", chatId, err)
+		ttl = profileRetryTTL
+	default:
+		result = profile.Locale
+	}
+
+	localesMu.Lock()
+	locale = locales[chatId]
+	locale.profile = result
+	locale.expiresAt = time.Now().Add(ttl)
+	locales[chatId] = locale
+	localesMu.Unlock()
+
+	return result
+}
+
+// forgetProfileLocale drops the cached locale of the profile, e.g. after the chat was unlinked
+func forgetProfileLocale(chatId int64) {
+	localesMu.Lock()
+	locale := locales[chatId]
+	locale.profile = ""
+	locale.expiresAt = time.Time{}
+	locales[chatId] = locale
+	localesMu.Unlock()
+}
+
+// printer speaks the locale of the linked profile, then the telegram language of the chat
+func printer(chatId int64) i18n.Printer {
+	profile := profileLocale(chatId)
+
+	localesMu.Lock()
+	language := locales[chatId].language
+	localesMu.Unlock()
+
+	return i18n.NewPrinter(i18n.Resolve(profile, language))
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/reminders"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/google/uuid"
+)
+
+// the reminders of a note carry its id on the buttons, the ones of a habit the category id
+const (
+	viewReminder      = "remind"
+	viewHabitReminder = "habit"
+)
+
+// errHabitGone means the category of a habit reminder was deleted meanwhile
+var errHabitGone = errors.New("habit was deleted")
+
+const (
+	actionSnooze = "snooze"
+	actionSkip   = "skip"
+)
+
+const snoozeFor = 15 * time.Minute
+
+func reminderMessage(chatId int64, view string, id uuid.UUID, content string) tgbotapi.MessageConfig {
+	p := printer(chatId)
+	msg := tgbotapi.NewMessage(chatId, p.T("reminder.text", content))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("reminder.button_done"), callbackData(actionDone, view, id)),
+			tgbotapi.NewInlineKeyboardButtonData(
+				p.T("reminder.button_snooze", int(snoozeFor.Minutes())),
+				callbackData(actionSnooze, view, id),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("reminder.button_skip"), callbackData(actionSkip, view, id)),
+		),
+	)
+	return msg
+}
+
+// SendReminder pushes the reminder to every chat linked to the user, a habit
+// done today already is not reminded of; an error asks the consumer to retry
+func SendReminder(bot *tgbotapi.BotAPI, event reminders.Event) error {
+	chats, err := api.Chats(event.User_id)
+	if err != nil {
+		return fmt.Errorf("chats of user %s: %w", event.User_id, err)
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+
+	view, id := viewReminder, event.Note_id
+	if event.Category_id != uuid.Nil {
+		done, err := habitDoneByUser(chats, event.Category_id)
+		if err != nil {
+			return fmt.Errorf("habit %s of user %s: %w", event.Category_id, event.User_id, err)
+		}
+		if done {
+			return nil
+		}
+		view, id = viewHabitReminder, event.Category_id
+	}
+
+	for _, chatId := range chats {
+		send(bot, chatId, reminderMessage(chatId, view, id, event.Content))
+	}
+	return nil
+}
+
+// findHabit returns the category of a habit reminder, errHabitGone when it was deleted
+func findHabit(chatId int64, categoryId uuid.UUID) (haloapi.Category, error) {
+	categories, err := api.Categories(chatId)
+	if err != nil {
+		return haloapi.Category{}, err
+	}
+	for _, category := range categories {
+		if category.Id == categoryId {
+			return category, nil
+		}
+	}
+	return haloapi.Category{}, errHabitGone
+}
+
+// habitDoneByUser asks through the linked chats in turn until one answers, a
+// chat whose link just went away does not hold up the reminder of the others
+func habitDoneByUser(chats []int64, categoryId uuid.UUID) (bool, error) {
+	var err error
+	for _, chatId := range chats {
+		var done bool
+		if done, err = habitDoneToday(chatId, categoryId); err == nil {
+			return done, nil
+		}
+		log.Printf("habit %s through chat %d: %v", categoryId, chatId, err)
+	}
+	return false, err
+}
+
+// habitDoneToday tells whether a completed note of the category was recorded
+// today in the timezone of the user
+func habitDoneToday(chatId int64, categoryId uuid.UUID) (bool, error) {
+	now, err := userNow(chatId)
+	if err != nil {
+		return false, err
+	}
+	notes, err := api.NotesSince(chatId, startOfToday(now).Unix())
+	if err != nil {
+		return false, err
+	}
+	for _, note := range notes {
+		if note.Completed && note.Category_id == categoryId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// completeHabit records a completed note of the habit unless it is done today already
+func completeHabit(chatId int64, categoryId uuid.UUID) error {
+	category, err := findHabit(chatId, categoryId)
+	if err != nil {
+		return err
+	}
+	done, err := habitDoneToday(chatId, categoryId)
+	if err != nil || done {
+		return err
+	}
+	_, err = api.AddNote(chatId, haloapi.Note{Content: category.Name, Category_id: category.Id, Completed: true})
+	return err
+}
+
+// remindAgain repeats the reminder in the chat unless the note or habit got
+// done or deleted meanwhile, snoozed reminders live in memory and do not
+// survive a restart of the bot
+func remindAgain(bot *tgbotapi.BotAPI, chatId int64, view string, id uuid.UUID) {
+	time.AfterFunc(snoozeFor, func() {
+		content, err := snoozedContent(chatId, view, id)
+		if errors.Is(err, haloapi.ErrNoteGone) || errors.Is(err, errHabitGone) {
+			return
+		}
+		if err != nil {
+			log.Printf("snoozed %s %s for chat %d: %v", view, id, chatId, err)
+			return
+		}
+		if content == "" {
+			return
+		}
+		send(bot, chatId, reminderMessage(chatId, view, id, content))
+	})
+}
+
+// snoozedContent is the text of a snoozed reminder, empty once it is done
+func snoozedContent(chatId int64, view string, id uuid.UUID) (string, error) {
+	if view == viewHabitReminder {
+		category, err := findHabit(chatId, id)
+		if err != nil {
+			return "", err
+		}
+		done, err := habitDoneToday(chatId, id)
+		if err != nil || done {
+			return "", err
+		}
+		return category.Name, nil
+	}
+
+	note, err := api.FindNote(chatId, id)
+	if err != nil || note.Completed {
+		return "", err
+	}
+	return note.Content, nil
+}
+
+// handleReminderCallback applies a button of a reminder and replaces the
+// buttons with the outcome so the reminder can not be answered twice
+func handleReminderCallback(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, action string, view string, id uuid.UUID) {
+	chatId := query.Message.Chat.ID
+	p := printer(chatId)
+
+	var err error
+	var outcome string
+	switch action {
+	case actionDone:
+		if view == viewHabitReminder {
+			err = completeHabit(chatId, id)
+		} else {
+			err = completeNote(chatId, id)
+		}
+		outcome = p.T("reminder.done")
+	case actionSnooze:
+		remindAgain(bot, chatId, view, id)
+		outcome = p.T("reminder.snoozed", p.Duration(snoozeFor))
+	case actionSkip:
+		outcome = p.T("reminder.skipped")
+	default:
+		answerCallback(bot, query, "")
+		return
+	}
+
+	switch {
+	case errors.Is(err, haloapi.ErrNoteGone):
+		outcome = p.T("error.note_gone")
+	case errors.Is(err, errHabitGone):
+		outcome = p.T("error.habit_gone")
+	case errors.Is(err, haloapi.ErrNotLinked):
+		answerCallback(bot, query, p.T("error.not_linked_short"))
+		return
+	case err != nil:
+		log.Printf("%s reminder of %s %s for chat %d: %v", action, view, id, chatId, err)
+		answerCallback(bot, query, p.T("error.failed"))
+		return
+	}
+	answerCallback(bot, query, outcome)
+
+	edit := tgbotapi.NewEditMessageText(chatId, query.Message.MessageID, query.Message.Text+"\n"+outcome)
+	send(bot, chatId, edit)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+	"tgBot/reminders"
+
+	"github.com/google/uuid"
+)
+
+// remind delivers the reminder like the kafka consumer and returns what the bot sent
+func (c *testChat) remind(event reminders.Event) []sentMessage {
+	c.t.Helper()
+
+	c.telegram.mu.Lock()
+	before := len(c.telegram.sent)
+	c.telegram.mu.Unlock()
+
+	if err := SendReminder(c.bot, event); err != nil {
+		c.t.Fatalf("SendReminder: %v", err)
+	}
+
+	c.telegram.mu.Lock()
+	defer c.telegram.mu.Unlock()
+	return append([]sentMessage(nil), c.telegram.sent[before:]...)
+}
+
+func TestHabitReminder(t *testing.T) {
+	chat := newTestChat(t, 401, "en")
+	stretching := haloapi.Category{Id: uuid.New(), Name: "Stretching", Schedule_days: 1<<7 - 1, Remind_time: "08:30"}
+	chat.halo.categories = []haloapi.Category{stretching}
+
+	event := reminders.Event{User_id: uuid.New(), Category_id: stretching.Id, Content: "Stretching"}
+	sent := chat.remind(event)
+	if len(sent) != 1 || sent[0].Text != "⏰ Reminder: Stretching" {
+		t.Fatalf("reminder sent %+v", sent)
+	}
+	want := []string{
+		callbackData(actionDone, viewHabitReminder, stretching.Id),
+		callbackData(actionSnooze, viewHabitReminder, stretching.Id),
+		callbackData(actionSkip, viewHabitReminder, stretching.Id),
+	}
+	if !slices.Equal(sent[0].Inline, want) {
+		t.Fatalf("reminder buttons = %v, want %v", sent[0].Inline, want)
+	}
+
+	sent = chat.press(want[0])
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "✅ Done") {
+		t.Fatalf("done button sent %+v", sent)
+	}
+	if len(chat.halo.notes) != 1 || chat.halo.notes[0].Category_id != stretching.Id || !chat.halo.notes[0].Completed {
+		t.Fatalf("notes after done = %+v", chat.halo.notes)
+	}
+
+	// pressing done twice or a reminder after the habit was done adds nothing
+	chat.press(want[0])
+	if len(chat.halo.notes) != 1 {
+		t.Errorf("notes after a second done = %+v", chat.halo.notes)
+	}
+	if sent := chat.remind(event); len(sent) != 0 {
+		t.Errorf("a habit done today was reminded of: %+v", sent)
+	}
+}
+
+func TestHabitReminderStaleChat(t *testing.T) {
+	chat := newTestChat(t, 404, "en")
+	stretching := haloapi.Category{Id: uuid.New(), Name: "Stretching", Schedule_days: 1<<7 - 1, Remind_time: "08:30"}
+	now := time.Now().Unix()
+	chat.halo.categories = []haloapi.Category{stretching}
+	chat.halo.notes = []haloapi.Note{{Id: uuid.New(), Category_id: stretching.Id, Completed: true, Created_at: now, Updated_at: now}}
+
+	// the first chat of the user was unlinked meanwhile, the other one still answers
+	chat.halo.chats = []int64{405, chat.id}
+	chat.halo.stale = []int64{405}
+
+	event := reminders.Event{User_id: uuid.New(), Category_id: stretching.Id, Content: "Stretching"}
+	if sent := chat.remind(event); len(sent) != 0 {
+		t.Errorf("a habit done today was reminded of: %+v", sent)
+	}
+}
+
+func TestHabitReminderDeleted(t *testing.T) {
+	chat := newTestChat(t, 402, "en")
+
+	sent := chat.press(callbackData(actionDone, viewHabitReminder, uuid.New()))
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "The habit was deleted already") {
+		t.Fatalf("done of a deleted habit sent %+v", sent)
+	}
+	if len(chat.halo.notes) != 0 {
+		t.Errorf("a note was recorded for a deleted habit: %+v", chat.halo.notes)
+	}
+}
+
+func TestNoteReminder(t *testing.T) {
+	chat := newTestChat(t, 403, "en")
+
+	now := time.Now().Unix()
+	note := haloapi.Note{Id: uuid.New(), Content: "Water the plants", Created_at: now, Updated_at: now}
+	chat.halo.notes = []haloapi.Note{note}
+
+	sent := chat.remind(reminders.Event{User_id: uuid.New(), Note_id: note.Id, Content: note.Content})
+	if len(sent) != 1 || sent[0].Text != "⏰ Reminder: Water the plants" {
+		t.Fatalf("reminder sent %+v", sent)
+	}
+
+	sent = chat.press(callbackData(actionSnooze, viewReminder, note.Id))
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "I will remind you in 15 minutes") {
+		t.Fatalf("snooze button sent %+v", sent)
+	}
+
+	sent = chat.press(callbackData(actionDone, viewReminder, note.Id))
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "✅ Done") {
+		t.Fatalf("done button sent %+v", sent)
+	}
+	if stored, _ := chat.halo.note(note.Id); !stored.Completed {
+		t.Errorf("note after done = %+v", stored)
+	}
+
+	chat.halo.notes = nil
+	sent = chat.press(callbackData(actionDone, viewReminder, note.Id))
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "The note was deleted already") {
+		t.Errorf("done of a deleted note sent %+v", sent)
+	}
+
+	sent = chat.press(callbackData(actionSkip, viewReminder, note.Id))
+	if len(sent) != 1 || !strings.HasSuffix(sent[0].Text, "⏭ Skipped") {
+		t.Errorf("skip button sent %+v", sent)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"tgBot/conversation"
+	"tgBot/digest"
+	"tgBot/haloapi"
+	"tgBot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const viewSettings = "settings"
+
+const (
+	actionToggle   = "toggle"
+	actionTimezone = "timezone"
+)
+
+// options of the settings toggle buttons
+const (
+	optionDaily  = "daily"
+	optionWeekly = "weekly"
+)
+
+// flowTimezone asks for the time zone the digests are scheduled in
+const (
+	flowTimezone = "timezone"
+	stepTimezone = "timezone"
+)
+
+func settingsCallback(action string, option string) string {
+	return action + ":" + viewSettings + ":" + option
+}
+
+func onOff(p i18n.Printer, on bool) string {
+	if on {
+		return p.T("settings.on")
+	}
+	return p.T("settings.off")
+}
+
+func timezoneName(timezone string) string {
+	if timezone == "" {
+		return "UTC"
+	}
+	return timezone
+}
+
+func renderSettings(chatId int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	settings, err := api.Settings(chatId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	p := printer(chatId)
+	text := p.T("settings.text",
+		digest.DailyHour, onOff(p, settings.Daily_digest),
+		digest.WeeklyHour, onOff(p, settings.Weekly_digest),
+		timezoneName(settings.Timezone),
+	)
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			p.T("settings.button_daily", onOff(p, settings.Daily_digest)),
+			settingsCallback(actionToggle, optionDaily),
+		)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			p.T("settings.button_weekly", onOff(p, settings.Weekly_digest)),
+			settingsCallback(actionToggle, optionWeekly),
+		)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			p.T("settings.button_timezone"),
+			settingsCallback(actionTimezone, ""),
+		)),
+	)
+	return text, &markup, nil
+}
+
+func handleSettings(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	sendView(bot, message.Chat.ID, renderSettings)
+}
+
+// handleSettingsCallback flips a digest or starts the timezone dialog
+func handleSettingsCallback(bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, action string, option string) {
+	chatId := query.Message.Chat.ID
+	p := printer(chatId)
+
+	if action == actionTimezone {
+		answerCallback(bot, query, "")
+		startConversation(bot, chatId, conversation.Start(flowTimezone, stepTimezone))
+		return
+	}
+	if action != actionToggle || (option != optionDaily && option != optionWeekly) {
+		answerCallback(bot, query, "")
+		return
+	}
+
+	settings, err := api.Settings(chatId)
+	if err == nil {
+		if option == optionDaily {
+			settings.Daily_digest = !settings.Daily_digest
+		} else {
+			settings.Weekly_digest = !settings.Weekly_digest
+		}
+		_, err = api.SaveSettings(chatId, settings)
+	}
+	switch {
+	case errors.Is(err, haloapi.ErrNotLinked):
+		answerCallback(bot, query, p.T("error.not_linked_short"))
+		return
+	case err != nil:
+		log.Printf("toggle %s digest for chat %d: %v", option, chatId, err)
+		answerCallback(bot, query, p.T("error.failed"))
+		return
+	}
+	answerCallback(bot, query, p.T("settings.saved"))
+
+	text, markup, err := renderSettings(chatId)
+	if err != nil {
+		log.Printf("redraw settings for chat %d: %v", chatId, err)
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatId, query.Message.MessageID, text)
+	edit.ReplyMarkup = markup
+	send(bot, chatId, edit)
+}
+
+// parseTimezone accepts IANA names and whole hour offsets like UTC+3, GMT-5 or +3
+func parseTimezone(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	offset := strings.ToUpper(text)
+	for _, prefix := range []string{"UTC", "GMT"} {
+		if strings.HasPrefix(offset, prefix) {
+			offset = strings.TrimPrefix(offset, prefix)
+			if offset == "" {
+				return "UTC", true
+			}
+			break
+		}
+	}
+
+	if strings.HasPrefix(offset, "+") || strings.HasPrefix(offset, "-") {
+		hours, err := strconv.Atoi(offset)
+		if err != nil || hours < -12 || hours > 14 {
+			return "", false
+		}
+		if hours == 0 {
+			return "UTC", true
+		}
+		// the Etc zones count the other way round, Etc/GMT-3 is three hours ahead of UTC
+		return fmt.Sprintf("Etc/GMT%+d", -hours), true
+	}
+
+	// "Local" names the zone of the bot, not a zone of the user
+	if text == "" || text == "Local" {
+		return "", false
+	}
+	if _, err := time.LoadLocation(text); err != nil {
+		return "", false
+	}
+	return text, true
+}
+
+func askTimezone(bot *tgbotapi.BotAPI, chatId int64, state conversation.State) {
+	p := printer(chatId)
+	msg := tgbotapi.NewMessage(chatId, p.T("settings.ask_timezone"))
+	msg.ReplyMarkup = flowKeyboard(p)
+	send(bot, chatId, msg)
+}
+
+func answerTimezone(bot *tgbotapi.BotAPI, chatId int64, state conversation.State, text string) {
+	p := printer(chatId)
+
+	timezone, ok := parseTimezone(text)
+	if !ok {
+		reply(bot, chatId, p.T("settings.timezone_invalid"))
+		return
+	}
+
+	settings, err := api.Settings(chatId)
+	if err == nil {
+		settings.Timezone = timezone
+		settings, err = api.SaveSettings(chatId, settings)
+	}
+	if err != nil {
+		replyApiError(bot, chatId, err)
+		return
+	}
+	conversations.Delete(chatId)
+
+	now := time.Now().In(digest.Location(settings.Timezone))
+	msg := tgbotapi.NewMessage(chatId, p.T("settings.timezone_saved", timezoneName(settings.Timezone), now.Format("15:04")))
+	msg.ReplyMarkup = GetMainKeyboard(chatId)
+	send(bot, chatId, msg)
+}
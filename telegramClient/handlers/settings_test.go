@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSettingsToggleDigests(t *testing.T) {
+	chat := newTestChat(t, 201, "en")
+
+	shown := chat.expect("/settings", "Evening digest at 21:00: off")
+	daily := settingsCallback(actionToggle, optionDaily)
+	if !slices.Contains(shown.Inline, daily) {
+		t.Fatalf("settings buttons = %v, want %q", shown.Inline, daily)
+	}
+
+	sent := chat.press(daily)
+	if len(sent) != 1 || sent[0].Method != "editMessageText" || !strings.Contains(sent[0].Text, "Evening digest at 21:00: on") {
+		t.Fatalf("after the toggle the bot sent %+v", sent)
+	}
+	chat.press(settingsCallback(actionToggle, optionWeekly))
+
+	if !chat.halo.settings.Daily_digest || !chat.halo.settings.Weekly_digest {
+		t.Errorf("settings = %+v, want both digests on", chat.halo.settings)
+	}
+}
+
+func TestSettingsTimezoneDialog(t *testing.T) {
+	chat := newTestChat(t, 202, "en")
+	chat.halo.settings.Daily_digest = true
+
+	sent := chat.press(settingsCallback(actionTimezone, ""))
+	if len(sent) != 1 || !strings.Contains(sent[0].Text, "Send your timezone") {
+		t.Fatalf("timezone button sent %+v", sent)
+	}
+
+	chat.expect("Mars/Olympus", "I don't know this timezone")
+	chat.expect("UTC+3", "Timezone set: Etc/GMT-3")
+
+	if chat.halo.settings.Timezone != "Etc/GMT-3" || !chat.halo.settings.Daily_digest {
+		t.Errorf("settings = %+v", chat.halo.settings)
+	}
+	if _, ok := conversations.Get(chat.id); ok {
+		t.Error("the dialog is still open after the timezone was saved")
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	cases := map[string]string{
+		"Europe/Moscow": "Europe/Moscow",
+		"UTC":           "UTC",
+		"gmt":           "UTC",
+		"+0":            "UTC",
+		"UTC+3":         "Etc/GMT-3",
+		"GMT-5":         "Etc/GMT+5",
+		" +14 ":         "Etc/GMT-14",
+	}
+	for text, want := range cases {
+		if got, ok := parseTimezone(text); !ok || got != want {
+			t.Errorf("parseTimezone(%q) = %q, %t, want %q", text, got, ok, want)
+		}
+	}
+	for _, text := range []string{"", "Local", "UTC+3:30", "+15", "Moscow", "UTC3"} {
+		if got, ok := parseTimezone(text); ok {
+			t.Errorf("parseTimezone(%q) = %q, want it rejected", text, got)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Package i18n binds the messages of the bot to the shared locale engine.
+package i18n
+
+import (
+	engine "i18n"
+)
+
+type (
+	Locale  = engine.Locale
+	Printer = engine.Printer
+)
+
+const (
+	En      = engine.En
+	Ru      = engine.Ru
+	Default = engine.Default
+)
+
+var Supported = engine.Supported
+
+// Parse maps values like "ru", "ru-RU" or "ru_RU.UTF-8" to a supported locale
+func Parse(value string) (Locale, bool) {
+	return engine.Parse(value)
+}
+
+// Resolve picks the first supported of the values, the default without one
+func Resolve(values ...string) Locale {
+	return engine.Resolve(values...)
+}
+
+// NewPrinter formats the messages of the bot in the locale
+func NewPrinter(locale Locale) Printer {
+	return engine.Catalogue(messages).Printer(locale)
+}
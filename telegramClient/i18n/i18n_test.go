@@ -0,0 +1,24 @@
+package i18n
+
+import (
+	"testing"
+
+	engine "i18n"
+)
+
+// the engine is tested in the shared i18n module, the tests here cover the
+// catalogue of the bot
+func TestCatalogueIsComplete(t *testing.T) {
+	for _, err := range engine.Catalogue(messages).Check() {
+		t.Error(err)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	if got := NewPrinter(Ru).N("duration.days", 3); got != "3 дня" {
+		t.Errorf("ru 3 days = %q", got)
+	}
+	if got := NewPrinter(En).N("duration.days", 1); got != "1 day" {
+		t.Errorf("en 1 day = %q", got)
+	}
+}
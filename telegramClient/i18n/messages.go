@@ -0,0 +1,433 @@
+package i18n
+
+// messages of the bot, plural messages hold their forms separated by "|"
+var messages = map[string]map[Locale]string{
+	"duration.less_than_minute": {
+		En: "less than a minute",
+		Ru: "меньше минуты",
+	},
+	"duration.minutes": {
+		En: "%d minute|%d minutes",
+		Ru: "%d минуту|%d минуты|%d минут",
+	},
+	"duration.hours": {
+		En: "%d hour|%d hours",
+		Ru: "%d час|%d часа|%d часов",
+	},
+	"duration.days": {
+		En: "%d day|%d days",
+		Ru: "%d день|%d дня|%d дней",
+	},
+
+	"help": {
+		En: "/today - what is left for today\n" +
+			"/list - latest notes\n" +
+			"/stats - weekly summary\n" +
+			"/add <text> - new note, a leading #category sets the category\n" +
+			"/done <habit> - mark as done\n" +
+			"/habit - create a habit\n" +
+			"/cancel - stop the current dialog, /back - go one step back\n" +
+			"/settings - evening digest, weekly report and timezone\n" +
+			"/link - link the chat to your Halo account\n" +
+			"/unlink - unlink the chat from your Halo account",
+		Ru: "/today - что осталось на сегодня\n" +
+			"/list - последние записи\n" +
+			"/stats - итоги недели\n" +
+			"/add <текст> - новая запись, #категория в начале задаёт категорию\n" +
+			"/done <привычка> - отметить выполненным\n" +
+			"/habit - завести привычку\n" +
+			"/cancel - прервать диалог, /back - вернуться на шаг назад\n" +
+			"/settings - итоги дня, итоги недели и часовой пояс\n" +
+			"/link - связать чат с аккаунтом Halo\n" +
+			"/unlink - отвязать чат от аккаунта Halo",
+	},
+	"start": {
+		En: "Hi! I will help you keep track of what you do.\nTo link the chat to your Halo account, send /link.\n\n%s",
+		Ru: "Привет! Я помогу отслеживать твои действия.\nЧтобы связать чат с аккаунтом Halo, отправь /link.\n\n%s",
+	},
+	"unknown_command": {
+		En: "I don't know this command.\n\n%s",
+		Ru: "Не знаю такой команды.\n\n%s",
+	},
+
+	"link.failed": {
+		En: "Could not create a code, try again later.",
+		Ru: "Не получилось создать код, попробуй позже.",
+	},
+	"link.code": {
+		En: "Your code: %s\n\nConfirm it in the terminal:\nhalo telegram link %s\n\nThe code is valid for %s.",
+		Ru: "Твой код: %s\n\nПодтверди его в терминале:\nhalo telegram link %s\n\nКод действует %s.",
+	},
+
+	"unlink.done": {
+		En: "The chat is unlinked from your Halo account. Send /link to link it again.",
+		Ru: "Чат отвязан от аккаунта Halo. Отправь /link, чтобы связать его снова.",
+	},
+	"unlink.failed": {
+		En: "Could not unlink the chat, try again later.",
+		Ru: "Не получилось отвязать чат, попробуй позже.",
+	},
+
+	"error.not_linked": {
+		En: "Link the chat to your Halo account first: send /link.",
+		Ru: "Сначала свяжи чат с аккаунтом Halo: отправь /link.",
+	},
+	"error.api": {
+		En: "Could not reach Halo, try again later.",
+		Ru: "Не получилось связаться с Halo, попробуй позже.",
+	},
+	"error.not_linked_short": {
+		En: "Link the chat first: /link",
+		Ru: "Сначала свяжи чат: /link",
+	},
+	"error.failed": {
+		En: "That did not work, try again later",
+		Ru: "Не получилось, попробуй позже",
+	},
+	"error.note_gone": {
+		En: "The note was deleted already",
+		Ru: "Запись уже удалена",
+	},
+	"error.habit_gone": {
+		En: "The habit was deleted already",
+		Ru: "Привычка уже удалена",
+	},
+
+	"button.meal": {
+		En: "🍽 I started eating",
+		Ru: "🍽 Я начал есть",
+	},
+	"button.finish": {
+		En: "✅ I'm done",
+		Ru: "✅ Я закончил",
+	},
+
+	"activity.meal": {
+		En: "Meal",
+		Ru: "Еда",
+	},
+	"activity.category_gone": {
+		En: "The category «%s» is gone, I updated the keyboard.",
+		Ru: "Категории «%s» больше нет, обновил клавиатуру.",
+	},
+	"activity.started": {
+		En: "Started: %s. Press «%s» when you are done.",
+		Ru: "Записал начало: %s. Нажми «%s», когда закончишь.",
+	},
+	"activity.not_running": {
+		En: "Nothing is running. Pick an activity on the keyboard to start.",
+		Ru: "Сейчас ничего не запущено. Выбери занятие на клавиатуре, чтобы начать.",
+	},
+	"activity.gone": {
+		En: "The note «%s» was deleted already, start again.",
+		Ru: "Запись «%s» уже удалена, начни заново.",
+	},
+	"activity.finished": {
+		En: "Done: %s, took %s.",
+		Ru: "Готово: %s, заняло %s.",
+	},
+
+	"today.all_done": {
+		En: "Everything is done for today 🎉",
+		Ru: "На сегодня всё сделано 🎉",
+	},
+	"today.title": {
+		En: "Left for today:",
+		Ru: "Осталось на сегодня:",
+	},
+	"list.empty": {
+		En: "No notes yet. Add the first one: /add <text>",
+		Ru: "Записей пока нет. Добавь первую: /add <текст>",
+	},
+	"list.title": {
+		En: "Latest notes:",
+		Ru: "Последние записи:",
+	},
+
+	"stats.this_week": {
+		En: "This week",
+		Ru: "Эта неделя",
+	},
+	"stats.last_week": {
+		En: "Last week",
+		Ru: "Прошлая неделя",
+	},
+	"stats.week": {
+		En: "%s: done %d, not done %d, days with notes %d",
+		Ru: "%s: выполнено %d, не выполнено %d, дней с отметками %d",
+	},
+	"stats.tracked": {
+		En: ", tracked %s",
+		Ru: ", учтено %s",
+	},
+	"stats.streak": {
+		En: "Streak: %s in a row, record %s",
+		Ru: "Серия: %s подряд, рекорд %s",
+	},
+
+	"add.usage": {
+		En: "Write the text of the note: /add <text>",
+		Ru: "Напиши текст записи: /add <текст>",
+	},
+	"add.added": {
+		En: "Added: %s",
+		Ru: "Добавил: %s",
+	},
+	"done.usage": {
+		En: "Write what is done: /done <habit>. Today's list: /today",
+		Ru: "Напиши, что выполнено: /done <привычка>. Список на сегодня: /today",
+	},
+	"done.marked": {
+		En: "Marked: %s ✅",
+		Ru: "Отметил: %s ✅",
+	},
+	"done.recorded": {
+		En: "Recorded as done: %s ✅",
+		Ru: "Записал выполненным: %s ✅",
+	},
+
+	"callback.done": {
+		En: "Marked ✅",
+		Ru: "Отмечено ✅",
+	},
+	"callback.deleted": {
+		En: "Deleted 🗑",
+		Ru: "Удалено 🗑",
+	},
+
+	"reminder.text": {
+		En: "⏰ Reminder: %s",
+		Ru: "⏰ Напоминание: %s",
+	},
+	"reminder.button_done": {
+		En: "✅ Done",
+		Ru: "✅ Готово",
+	},
+	"reminder.button_snooze": {
+		En: "⏰ %d min",
+		Ru: "⏰ %d мин",
+	},
+	"reminder.button_skip": {
+		En: "⏭ Skip",
+		Ru: "⏭ Пропустить",
+	},
+	"reminder.done": {
+		En: "✅ Done",
+		Ru: "✅ Выполнено",
+	},
+	"reminder.snoozed": {
+		En: "⏰ I will remind you in %s",
+		Ru: "⏰ Напомню через %s",
+	},
+	"reminder.skipped": {
+		En: "⏭ Skipped",
+		Ru: "⏭ Пропущено",
+	},
+
+	"button.back": {
+		En: "⬅ Back",
+		Ru: "⬅ Назад",
+	},
+	"button.cancel": {
+		En: "✖ Cancel",
+		Ru: "✖ Отмена",
+	},
+	"button.every_day": {
+		En: "Every day",
+		Ru: "Каждый день",
+	},
+	"button.weekdays": {
+		En: "Weekdays",
+		Ru: "По будням",
+	},
+	"button.weekends": {
+		En: "Weekends",
+		Ru: "По выходным",
+	},
+	"button.no_reminder": {
+		En: "No reminder",
+		Ru: "Без напоминания",
+	},
+
+	"conversation.none": {
+		En: "There is no dialog in progress.",
+		Ru: "Сейчас нет начатого диалога.",
+	},
+	"conversation.cancelled": {
+		En: "Cancelled.",
+		Ru: "Отменено.",
+	},
+
+	"habit.ask_name": {
+		En: "What habit do you want to build? Send its name.",
+		Ru: "Какую привычку хочешь завести? Напиши её название.",
+	},
+	"habit.name_invalid": {
+		En: "The name must be 1 to %d characters long, try another one.",
+		Ru: "Название должно быть длиной от 1 до %d символов, попробуй другое.",
+	},
+	"habit.name_taken": {
+		En: "You already have «%s», pick another name.",
+		Ru: "«%s» у тебя уже есть, выбери другое название.",
+	},
+	"habit.ask_schedule": {
+		En: "On which days? Press a button or list the days, e.g. mon wed fri.",
+		Ru: "В какие дни? Нажми кнопку или перечисли дни, например: пн ср пт.",
+	},
+	"habit.schedule_invalid": {
+		En: "I did not get the days. Press a button or write them like mon wed fri.",
+		Ru: "Не понял дни. Нажми кнопку или напиши их так: пн ср пт.",
+	},
+	"habit.ask_remind": {
+		En: "When should I remind you? Send the time as HH:MM, e.g. 08:30.",
+		Ru: "Когда напоминать? Напиши время в виде ЧЧ:ММ, например 08:30.",
+	},
+	"habit.remind_invalid": {
+		En: "Send the time as HH:MM, e.g. 08:30, or press «%s».",
+		Ru: "Напиши время в виде ЧЧ:ММ, например 08:30, или нажми «%s».",
+	},
+	"habit.created": {
+		En: "Habit «%s» created: %s, %s.",
+		Ru: "Привычка «%s» заведена: %s, %s.",
+	},
+	"habit.remind_at": {
+		En: "reminder at %s",
+		Ru: "напоминание в %s",
+	},
+	"habit.no_reminder": {
+		En: "no reminder",
+		Ru: "без напоминания",
+	},
+
+	"schedule.every_day": {
+		En: "every day",
+		Ru: "каждый день",
+	},
+	"schedule.weekdays": {
+		En: "on weekdays",
+		Ru: "по будням",
+	},
+	"schedule.weekends": {
+		En: "on weekends",
+		Ru: "по выходным",
+	},
+
+	"weekday.mon": {
+		En: "Mon",
+		Ru: "пн",
+	},
+	"weekday.tue": {
+		En: "Tue",
+		Ru: "вт",
+	},
+	"weekday.wed": {
+		En: "Wed",
+		Ru: "ср",
+	},
+	"weekday.thu": {
+		En: "Thu",
+		Ru: "чт",
+	},
+	"weekday.fri": {
+		En: "Fri",
+		Ru: "пт",
+	},
+	"weekday.sat": {
+		En: "Sat",
+		Ru: "сб",
+	},
+	"weekday.sun": {
+		En: "Sun",
+		Ru: "вс",
+	},
+
+	"settings.text": {
+		En: "⚙ Settings\nEvening digest at %d:00: %s\nWeekly report on Sunday at %d:00: %s\nTimezone: %s",
+		Ru: "⚙ Настройки\nИтоги дня в %d:00: %s\nИтоги недели в воскресенье в %d:00: %s\nЧасовой пояс: %s",
+	},
+	"settings.on": {
+		En: "on",
+		Ru: "вкл",
+	},
+	"settings.off": {
+		En: "off",
+		Ru: "выкл",
+	},
+	"settings.button_daily": {
+		En: "Evening digest: %s",
+		Ru: "Итоги дня: %s",
+	},
+	"settings.button_weekly": {
+		En: "Weekly report: %s",
+		Ru: "Итоги недели: %s",
+	},
+	"settings.button_timezone": {
+		En: "🌍 Change timezone",
+		Ru: "🌍 Сменить часовой пояс",
+	},
+	"settings.saved": {
+		En: "Saved",
+		Ru: "Сохранено",
+	},
+	"settings.ask_timezone": {
+		En: "Send your timezone, e.g. Europe/Moscow or UTC+3.",
+		Ru: "Напиши свой часовой пояс, например Europe/Moscow или UTC+3.",
+	},
+	"settings.timezone_invalid": {
+		En: "I don't know this timezone. Send it like Europe/Berlin or UTC+1.",
+		Ru: "Не знаю такого часового пояса. Напиши его так: Europe/Berlin или UTC+1.",
+	},
+	"settings.timezone_saved": {
+		En: "Timezone set: %s, it is %s there now.",
+		Ru: "Часовой пояс: %s, сейчас там %s.",
+	},
+
+	"digest.daily_title": {
+		En: "🌙 Evening digest, %s",
+		Ru: "🌙 Итоги дня, %s",
+	},
+	"digest.nothing_today": {
+		En: "Nothing was recorded today.",
+		Ru: "Сегодня записей не было.",
+	},
+	"digest.done_today": {
+		En: "Done today, %d note:|Done today, %d notes:",
+		Ru: "Сделано сегодня, %d запись:|Сделано сегодня, %d записи:|Сделано сегодня, %d записей:",
+	},
+	"digest.still_open": {
+		En: "Still open:",
+		Ru: "Не сделано:",
+	},
+	"digest.at_risk": {
+		En: "Streaks at risk, mark them before midnight:",
+		Ru: "Серии под угрозой, отметь их до полуночи:",
+	},
+	"digest.streak_at_risk": {
+		En: "🔥 %s: %s in a row",
+		Ru: "🔥 %s: %s подряд",
+	},
+	"digest.weekly_title": {
+		En: "📊 Weekly report %s–%s",
+		Ru: "📊 Итоги недели %s–%s",
+	},
+	"digest.week_total": {
+		En: "Done: %d, last week %d (%s)",
+		Ru: "Выполнено: %d, на прошлой неделе %d (%s)",
+	},
+	"digest.week_tracked": {
+		En: "Tracked: %s",
+		Ru: "Учтено времени: %s",
+	},
+	"digest.by_category": {
+		En: "By category, this week / last week:",
+		Ru: "По категориям, эта неделя / прошлая:",
+	},
+	"digest.week_category": {
+		En: "%s: %d / %d (%s)",
+		Ru: "%s: %d / %d (%s)",
+	},
+	"digest.no_category": {
+		En: "Without category",
+		Ru: "Без категории",
+	},
+}
@@ -1,24 +1,102 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"tgBot/digest"
+	"tgBot/haloapi"
+	"tgBot/handlers"
+	"tgBot/reminders"
+	"tgBot/telegram"
+	"time"
+	// the digests are scheduled in the time zones of the users
+	_ "time/tzdata"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/joho/godotenv"
 )
 
-func main() {
-	bot, err := tgbotapi.NewBotAPI(os.Getenv("BOT_TOKEN"))
+const defaultWorkers = 8
 
-	if err != nil {
-		log.Panic(err)
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// run reads BOT_MODE (polling or webhook), TELEGRAM_API_URL, BOT_WORKERS and
+// for webhooks WEBHOOK_URL, WEBHOOK_LISTEN and WEBHOOK_SECRET
+func run(ctx context.Context) error {
+	token := os.Getenv("BOT_TOKEN")
+	if token == "" {
+		return errors.New("BOT_TOKEN is not set")
 	}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates, _ := bot.GetUpdatesChan(u)
+	workers, err := strconv.Atoi(getenv("BOT_WORKERS", strconv.Itoa(defaultWorkers)))
+	if err != nil || workers < 1 {
+		return fmt.Errorf("BOT_WORKERS: expected a positive number")
+	}
+
+	client := haloapi.NewClientFromEnv()
+	handlers.Init(client)
+
+	bot, err := telegram.NewBot(token, os.Getenv("TELEGRAM_API_URL"))
+	if err != nil {
+		return fmt.Errorf("connect to telegram: %w", err)
+	}
 
-	for update := range updates {
+	dispatcher := telegram.NewDispatcher(workers, func(update tgbotapi.Update) {
 		handlers.HandleUpdate(bot, update)
+	})
+	// updates taken already are handled before the bot exits
+	defer dispatcher.Close()
+
+	// reminders are optional, the bot works without kafka
+	if host := os.Getenv("KAFKA_HOST"); host != "" {
+		kafkaUrl := fmt.Sprintf("%v:%v", host, os.Getenv("KAFKA_PORT"))
+		go reminders.Run(ctx, kafkaUrl, func(event reminders.Event) error {
+			return handlers.SendReminder(bot, event)
+		})
+	}
+
+	scheduler := digest.NewScheduler(client.DigestSubscribers, func(subscriber haloapi.DigestSubscriber, kind digest.Kind, now time.Time) error {
+		return handlers.SendDigest(bot, subscriber, kind, now)
+	})
+	go scheduler.Run(ctx)
+
+	switch mode := getenv("BOT_MODE", "polling"); mode {
+	case "polling":
+		log.Printf("bot @%s is polling for updates", bot.Self.UserName)
+		return telegram.Poll(ctx, bot, dispatcher)
+	case "webhook":
+		return telegram.RunWebhook(ctx, bot, telegram.WebhookConfig{
+			Url:            os.Getenv("WEBHOOK_URL"),
+			Listen:         getenv("WEBHOOK_LISTEN", ":8080"),
+			Secret:         os.Getenv("WEBHOOK_SECRET"),
+			MaxConnections: workers,
+		}, dispatcher)
+	default:
+		return fmt.Errorf("BOT_MODE %q: expected polling or webhook", mode)
+	}
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		log.Printf("bot: %v", err)
+		stop()
+		os.Exit(1)
 	}
+	log.Print("bot stopped")
 }
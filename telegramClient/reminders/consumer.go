@@ -0,0 +1,146 @@
+package reminders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// Topic carries the reminders of notes and habits, any service may produce to
+// it, category_service reminds of the habits with a remind_time
+const Topic = "note-reminder"
+
+const groupId = "telegram_bot"
+
+// reminders which are late by more than this are dropped, e.g. after the bot was down
+const maxDelay = time.Hour
+
+// reminders due later than this are dropped, waiting for them would hold up
+// the partition; producers emit reminders when they are due
+const maxEarly = 5 * time.Minute
+
+// a failed delivery is tried again after this until the reminder is too late
+const retryDelay = 30 * time.Second
+
+// Event asks to remind the user of a note, or of a habit when Category_id is
+// set, at remind_at, zero means now
+type Event struct {
+	User_id     uuid.UUID `json:"user_id"`
+	Note_id     uuid.UUID `json:"note_id"`
+	Category_id uuid.UUID `json:"category_id"`
+	Content     string    `json:"content"`
+	Remind_at   int64     `json:"remind_at"`
+}
+
+func getKafkaReader(kafkaUrl string) *kafka.Reader {
+	brokers := strings.Split(kafkaUrl, ",")
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupId,
+		GroupTopics: []string{Topic},
+		MinBytes:    1,
+		MaxBytes:    10e6, // 10MB
+		MaxWait:     time.Second,
+	})
+}
+
+func parseEvent(data []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, err
+	}
+	if event.User_id == uuid.Nil || (event.Note_id == uuid.Nil && event.Category_id == uuid.Nil) {
+		return event, errors.New("reminder without user id or note and category id")
+	}
+	return event, nil
+}
+
+// Run reads reminders until the context is done and hands each of them to
+// deliver when it is due. The offset is committed only after the delivery,
+// so a reminder waiting when the bot stops is read again after the restart;
+// reminders wait in order, so only a few minutes early ones are waited for
+func Run(ctx context.Context, kafkaUrl string, deliver func(Event) error) {
+	reader := getKafkaReader(kafkaUrl)
+	defer reader.Close()
+
+	log.Printf("consuming kafka topic %s", Topic)
+
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("kafka %s read: %v", Topic, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		event, err := parseEvent(m.Value)
+		if err != nil {
+			log.Printf("kafka %s message at offset %d: %v", Topic, m.Offset, err)
+		} else if !process(ctx, event, time.Now(), deliver) {
+			return
+		}
+
+		if err := reader.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			log.Printf("kafka %s commit at offset %d: %v", Topic, m.Offset, err)
+		}
+	}
+}
+
+// process waits until the reminder is due and delivers it, retrying failed
+// deliveries until it is more than maxDelay late; a reminder due more than
+// maxEarly ahead is dropped; false means the context ended first and the
+// reminder is still to be delivered
+func process(ctx context.Context, event Event, received time.Time, deliver func(Event) error) bool {
+	due := received
+	if event.Remind_at != 0 {
+		due = time.Unix(event.Remind_at, 0)
+	}
+
+	if wait := time.Until(due); wait > maxEarly {
+		log.Printf("reminder of user %s is due in %s, too early, dropped", event.User_id, wait)
+		return true
+	}
+
+	for {
+		wait := time.Until(due)
+		if -wait > maxDelay {
+			log.Printf("reminder of user %s is %s late, dropped", event.User_id, -wait)
+			return true
+		}
+		if wait > 0 {
+			if !sleep(ctx, wait) {
+				return false
+			}
+			continue
+		}
+
+		err := deliver(event)
+		if err == nil {
+			return true
+		}
+		log.Printf("reminder of user %s: %v, retrying", event.User_id, err)
+		if !sleep(ctx, retryDelay) {
+			return false
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
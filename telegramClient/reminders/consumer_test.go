@@ -0,0 +1,105 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseEvent(t *testing.T) {
+	event, err := parseEvent([]byte(`{"user_id":"0195b6c0-0000-7000-8000-000000000001",
+		"note_id":"0195b6c0-0000-7000-8000-000000000002","content":"water","remind_at":1741780800}`))
+	if err != nil {
+		t.Fatalf("parseEvent: %v", err)
+	}
+	if event.Content != "water" || event.Remind_at != 1741780800 {
+		t.Errorf("event = %+v", event)
+	}
+
+	if _, err := parseEvent([]byte(`{"note_id":"0195b6c0-0000-7000-8000-000000000002"}`)); err == nil {
+		t.Error("event without user id was accepted")
+	}
+	if _, err := parseEvent([]byte(`{note`)); err == nil {
+		t.Error("broken json was accepted")
+	}
+}
+
+func TestParseHabitEvent(t *testing.T) {
+	event, err := parseEvent([]byte(`{"user_id":"0195b6c0-0000-7000-8000-000000000001",
+		"category_id":"0195b6c0-0000-7000-8000-000000000003","content":"Stretching","remind_at":1741780800}`))
+	if err != nil {
+		t.Fatalf("parseEvent: %v", err)
+	}
+	if event.Category_id == uuid.Nil || event.Note_id != uuid.Nil {
+		t.Errorf("event = %+v", event)
+	}
+
+	if _, err := parseEvent([]byte(`{"user_id":"0195b6c0-0000-7000-8000-000000000001"}`)); err == nil {
+		t.Error("event without note and category id was accepted")
+	}
+}
+
+func TestProcess(t *testing.T) {
+	now := time.Now()
+	ctx := context.Background()
+
+	var delivered []uuid.UUID
+	deliver := func(event Event) error {
+		delivered = append(delivered, event.Note_id)
+		return nil
+	}
+
+	due := Event{Note_id: uuid.New(), Remind_at: now.Add(-time.Minute).Unix()}
+	immediate := Event{Note_id: uuid.New()}
+	late := Event{Note_id: uuid.New(), Remind_at: now.Add(-2 * maxDelay).Unix()}
+	soon := Event{Note_id: uuid.New(), Remind_at: now.Add(time.Second).Unix()}
+	// waiting for it would hold up the reminders behind it
+	ahead := Event{Note_id: uuid.New(), Remind_at: now.Add(2 * maxEarly).Unix()}
+
+	for _, event := range []Event{due, immediate, late, ahead, soon} {
+		if !process(ctx, event, now, deliver) {
+			t.Fatalf("process(%+v) stopped without a cancelled context", event)
+		}
+	}
+
+	want := []uuid.UUID{due.Note_id, immediate.Note_id, soon.Note_id}
+	if !slices.Equal(delivered, want) {
+		t.Errorf("delivered %v, want %v without the late and early reminders", delivered, want)
+	}
+	if time.Now().Unix() < soon.Remind_at {
+		t.Error("the reminder due soon was delivered early")
+	}
+}
+
+func TestProcessKeepsUndelivered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// a reminder waiting for its time is not acknowledged on shutdown
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	future := Event{Note_id: uuid.New(), Remind_at: time.Now().Add(maxEarly / 2).Unix()}
+	if process(ctx, future, time.Now(), func(Event) error { return nil }) {
+		t.Error("a reminder due later counted as delivered on shutdown")
+	}
+
+	// neither is one whose delivery failed
+	ctx, cancel = context.WithCancel(context.Background())
+	attempts := 0
+	failing := func(Event) error {
+		attempts++
+		cancel()
+		return errors.New("telegram is down")
+	}
+	if process(ctx, Event{Note_id: uuid.New()}, time.Now(), failing) {
+		t.Error("a failed delivery counted as delivered")
+	}
+	if attempts != 1 {
+		t.Errorf("delivery attempts = %d, want 1", attempts)
+	}
+}
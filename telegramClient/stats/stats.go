@@ -0,0 +1,128 @@
+package stats
+
+import (
+	"time"
+
+	"tgBot/haloapi"
+)
+
+const dayLayout = "2006-01-02"
+
+// Summary sums up the notes by local day the same way the tCli dashboard
+// does, a day counts for a streak when at least one note of it is completed
+type Summary struct {
+	CurrentStreak int
+	LongestStreak int
+	PendingToday  []haloapi.Note
+	Week          Week
+	LastWeek      Week
+}
+
+type Week struct {
+	Done    int
+	Pending int
+	Tracked time.Duration
+	Days    int
+}
+
+func dayOf(unix int64, loc *time.Location) time.Time {
+	t := time.Unix(unix, 0).In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns the monday of the week of the day
+func StartOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Today keeps the notes created on the local day of now
+func Today(notes []haloapi.Note, now time.Time) []haloapi.Note {
+	today := dayOf(now.Unix(), now.Location())
+	var filtered []haloapi.Note
+	for _, note := range notes {
+		if dayOf(note.Created_at, now.Location()).Equal(today) {
+			filtered = append(filtered, note)
+		}
+	}
+	return filtered
+}
+
+// Compute expects the notes in the time zone of now, streaks only see the given notes
+func Compute(notes []haloapi.Note, now time.Time) Summary {
+	today := dayOf(now.Unix(), now.Location())
+	thisWeek := StartOfWeek(today)
+	previousWeek := thisWeek.AddDate(0, 0, -7)
+
+	var summary Summary
+	done := make(map[string]int)
+
+	var first time.Time
+	for _, note := range notes {
+		day := dayOf(note.Created_at, now.Location())
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+
+		if note.Completed {
+			done[day.Format(dayLayout)]++
+		} else if day.Equal(today) {
+			summary.PendingToday = append(summary.PendingToday, note)
+		}
+
+		var week *Week
+		switch {
+		case !day.Before(thisWeek) && !day.After(today):
+			week = &summary.Week
+		case !day.Before(previousWeek) && day.Before(thisWeek):
+			week = &summary.LastWeek
+		default:
+			continue
+		}
+		if note.Completed {
+			week.Done++
+		} else {
+			week.Pending++
+		}
+		if note.Ended_at > note.Created_at {
+			week.Tracked += time.Duration(note.Ended_at-note.Created_at) * time.Second
+		}
+	}
+
+	for day := thisWeek; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if done[day.Format(dayLayout)] > 0 {
+			summary.Week.Days++
+		}
+	}
+	for day := previousWeek; day.Before(thisWeek); day = day.AddDate(0, 0, 1) {
+		if done[day.Format(dayLayout)] > 0 {
+			summary.LastWeek.Days++
+		}
+	}
+
+	if first.IsZero() {
+		return summary
+	}
+
+	run := 0
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if done[day.Format(dayLayout)] > 0 {
+			run++
+			summary.LongestStreak = max(summary.LongestStreak, run)
+		} else {
+			run = 0
+		}
+	}
+
+	// a streak is not broken yet while today is still open
+	day := today
+	if done[day.Format(dayLayout)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for done[day.Format(dayLayout)] > 0 {
+		summary.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return summary
+}
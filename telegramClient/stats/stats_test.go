@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"tgBot/haloapi"
+)
+
+func TestCompute(t *testing.T) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	// Wednesday
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, zone)
+
+	note := func(month time.Month, day, hour int, completed bool) haloapi.Note {
+		return haloapi.Note{
+			Created_at: time.Date(2025, month, day, hour, 0, 0, 0, zone).Unix(),
+			Completed:  completed,
+		}
+	}
+
+	notes := []haloapi.Note{
+		// a four day streak at the end of february
+		note(time.February, 24, 9, true),
+		note(time.February, 25, 9, true),
+		note(time.February, 26, 9, true),
+		note(time.February, 27, 9, true),
+		// last week
+		note(time.March, 8, 9, true),
+		note(time.March, 9, 9, true),
+		// this week, today is still open
+		note(time.March, 10, 9, true),
+		note(time.March, 10, 20, true),
+		note(time.March, 11, 9, true),
+		note(time.March, 12, 9, false),
+	}
+	// an hour long activity this week
+	notes[6].Ended_at = notes[6].Created_at + 3600
+
+	summary := Compute(notes, now)
+
+	if summary.CurrentStreak != 4 {
+		t.Errorf("CurrentStreak = %d, want 4", summary.CurrentStreak)
+	}
+	if summary.LongestStreak != 4 {
+		t.Errorf("LongestStreak = %d, want 4", summary.LongestStreak)
+	}
+	if len(summary.PendingToday) != 1 {
+		t.Errorf("PendingToday = %d notes, want 1", len(summary.PendingToday))
+	}
+	if summary.Week.Done != 3 || summary.Week.Pending != 1 || summary.Week.Days != 2 || summary.Week.Tracked != time.Hour {
+		t.Errorf("Week = %+v, want 3 done, 1 pending, 2 days, 1h tracked", summary.Week)
+	}
+	if summary.LastWeek.Done != 2 || summary.LastWeek.Days != 2 {
+		t.Errorf("LastWeek = %+v, want 2 done, 2 days", summary.LastWeek)
+	}
+	if today := Today(notes, now); len(today) != 1 {
+		t.Errorf("Today = %d notes, want 1", len(today))
+	}
+
+	// completing today extends the streak
+	notes[len(notes)-1].Completed = true
+	if got := Compute(notes, now).CurrentStreak; got != 5 {
+		t.Errorf("CurrentStreak with today done = %d, want 5", got)
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, time.UTC)
+
+	if empty := Compute(nil, now); empty.CurrentStreak != 0 || empty.LongestStreak != 0 || empty.Week.Days != 0 {
+		t.Errorf("empty summary = %+v, want zero", empty)
+	}
+}
@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// ErrClosed means the dispatcher is shutting down and takes no more updates
+var ErrClosed = errors.New("dispatcher is closed")
+
+// queueSize is the number of updates waiting per worker before Dispatch blocks
+const queueSize = 16
+
+// Dispatcher handles updates on a fixed number of workers, the updates of a
+// chat always go to the same worker so they are handled in order
+type Dispatcher struct {
+	handle func(tgbotapi.Update)
+	queues []chan tgbotapi.Update
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewDispatcher(workers int, handle func(tgbotapi.Update)) *Dispatcher {
+	workers = max(workers, 1)
+	d := &Dispatcher{
+		handle: handle,
+		queues: make([]chan tgbotapi.Update, workers),
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+
+	return d
+}
+
+func (d *Dispatcher) work(queue chan tgbotapi.Update) {
+	defer d.wg.Done()
+	for update := range queue {
+		d.handleSafely(update)
+	}
+}
+
+// handleSafely keeps the worker alive when a handler panics
+func (d *Dispatcher) handleSafely(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("update %d panicked: %v\n%s", update.UpdateID, r, debug.Stack())
+		}
+	}()
+	d.handle(update)
+}
+
+func chatOf(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return int64(update.CallbackQuery.From.ID)
+	}
+	return 0
+}
+
+// Dispatch queues the update, it blocks while the worker of the chat is busy
+func (d *Dispatcher) Dispatch(ctx context.Context, update tgbotapi.Update) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrClosed
+	}
+
+	chatId := chatOf(update)
+	if chatId < 0 {
+		chatId = -chatId
+	}
+	queue := d.queues[chatId%int64(len(d.queues))]
+
+	select {
+	case queue <- update:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops taking updates and waits until the queued ones are handled
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
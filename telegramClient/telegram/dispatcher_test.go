@@ -0,0 +1,96 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func messageUpdate(id int, chatId int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: id,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatId}, Text: "hi"},
+	}
+}
+
+func TestDispatcher_BoundedAndOrderedPerChat(t *testing.T) {
+	const workers = 3
+
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int64][]int)
+
+	dispatcher := NewDispatcher(workers, func(update tgbotapi.Update) {
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		inFlight.Add(-1)
+
+		mu.Lock()
+		seen[update.Message.Chat.ID] = append(seen[update.Message.Chat.ID], update.UpdateID)
+		mu.Unlock()
+	})
+
+	ctx := context.Background()
+	id := 0
+	for round := 0; round < 10; round++ {
+		for chatId := int64(1); chatId <= 6; chatId++ {
+			id++
+			if err := dispatcher.Dispatch(ctx, messageUpdate(id, chatId)); err != nil {
+				t.Fatalf("Dispatch: %v", err)
+			}
+		}
+	}
+	dispatcher.Close()
+
+	if got := maxInFlight.Load(); got > workers {
+		t.Errorf("%d updates handled at once, want at most %d", got, workers)
+	}
+	for chatId, ids := range seen {
+		if len(ids) != 10 {
+			t.Errorf("chat %d: %d updates handled, want 10", chatId, len(ids))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Errorf("chat %d: updates handled out of order: %v", chatId, ids)
+				break
+			}
+		}
+	}
+
+	if err := dispatcher.Dispatch(ctx, messageUpdate(id+1, 1)); err != ErrClosed {
+		t.Errorf("Dispatch after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestDispatcher_SurvivesPanic(t *testing.T) {
+	handled := make(chan int, 2)
+	dispatcher := NewDispatcher(1, func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("broken handler")
+		}
+		handled <- update.UpdateID
+	})
+	defer dispatcher.Close()
+
+	dispatcher.Dispatch(context.Background(), messageUpdate(1, 7))
+	dispatcher.Dispatch(context.Background(), messageUpdate(2, 7))
+
+	select {
+	case id := <-handled:
+		if id != 2 {
+			t.Errorf("handled update %d, want 2", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the worker stopped after a panic")
+	}
+}
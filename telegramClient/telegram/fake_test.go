@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testToken = "123:test"
+
+// fakeTelegram answers Bot API methods with canned results, a method answers
+// 429 with retry_after as often as it is rate limited
+type fakeTelegram struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	calls       map[string][]map[string]string
+	rateLimited map[string]int
+	retryAfter  int
+	results     map[string]func(params map[string]string) any
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	f := &fakeTelegram{
+		t:           t,
+		calls:       make(map[string][]map[string]string),
+		rateLimited: make(map[string]int),
+		retryAfter:  1,
+		results: map[string]func(map[string]string) any{
+			"getMe": func(map[string]string) any {
+				return map[string]any{"id": 1, "is_bot": true, "first_name": "Halo", "username": "halo_test_bot"}
+			},
+			"sendMessage": func(params map[string]string) any {
+				return map[string]any{"message_id": 1, "date": 0, "chat": map[string]any{"id": 42}, "text": params["text"]}
+			},
+			"setWebhook":    func(map[string]string) any { return true },
+			"deleteWebhook": func(map[string]string) any { return true },
+			"getUpdates":    func(map[string]string) any { return []any{} },
+		},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	prefix := "/bot" + testToken + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, prefix)
+
+	if err := r.ParseForm(); err != nil {
+		f.t.Errorf("%s: parse form: %v", method, err)
+	}
+	params := make(map[string]string)
+	for key := range r.PostForm {
+		params[key] = r.PostForm.Get(key)
+	}
+
+	f.mu.Lock()
+	f.calls[method] = append(f.calls[method], params)
+	limited := f.rateLimited[method] > 0
+	if limited {
+		f.rateLimited[method]--
+	}
+	result, known := f.results[method]
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case limited:
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]any{
+			"ok":          false,
+			"error_code":  429,
+			"description": "Too Many Requests: retry later",
+			"parameters":  map[string]any{"retry_after": f.retryAfter},
+		})
+	case !known:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 404, "description": "Not Found"})
+	default:
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result(params)})
+	}
+}
+
+func (f *fakeTelegram) callsOf(method string) []map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]string(nil), f.calls[method]...)
+}
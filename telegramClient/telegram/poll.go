@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"context"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// pollTimeout is the long poll of getUpdates in seconds
+	pollTimeout = 30
+	pollBackoff = 3 * time.Second
+)
+
+// Poll fetches updates with getUpdates until the context is done, a poll in
+// flight is abandoned then, its updates are confirmed only by the next poll
+// and come again after a restart
+func Poll(ctx context.Context, bot *tgbotapi.BotAPI, dispatcher *Dispatcher) error {
+	if err := DeleteWebhook(bot); err != nil {
+		return err
+	}
+
+	updates := make(chan []tgbotapi.Update)
+	config := tgbotapi.NewUpdate(0)
+	config.Timeout = pollTimeout
+
+	for {
+		go func(config tgbotapi.UpdateConfig) {
+			batch, err := bot.GetUpdates(config)
+			if err != nil {
+				log.Printf("get updates: %v", err)
+				select {
+				case <-time.After(pollBackoff):
+				case <-ctx.Done():
+				}
+			}
+			select {
+			case updates <- batch:
+			case <-ctx.Done():
+			}
+		}(config)
+
+		var batch []tgbotapi.Update
+		select {
+		case batch = <-updates:
+		case <-ctx.Done():
+			return nil
+		}
+
+		for _, update := range batch {
+			if update.UpdateID < config.Offset {
+				continue
+			}
+			if err := dispatcher.Dispatch(ctx, update); err != nil {
+				return nil
+			}
+			config.Offset = update.UpdateID + 1
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMaxWait    = time.Minute
+
+	// requests are long polls of pollTimeout at most, the client gives them some slack
+	clientTimeout = pollTimeout*time.Second + 15*time.Second
+)
+
+// Transport retries requests Telegram rejected with 429 once retry_after has
+// passed, and can point the bot at another server, e.g. a local Bot API server
+// or a fake one in tests, since the library has the endpoint hard-coded
+type Transport struct {
+	Base       http.RoundTripper
+	Endpoint   *url.URL
+	MaxRetries int
+	MaxWait    time.Duration
+}
+
+type errorResponse struct {
+	Parameters struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Endpoint != nil {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = t.Endpoint.Scheme
+		req.URL.Host = t.Endpoint.Host
+		req.URL.Path = strings.TrimRight(t.Endpoint.Path, "/") + req.URL.Path
+		req.Host = ""
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base().RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= t.MaxRetries {
+			return resp, err
+		}
+		// the body can not be sent again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed errorResponse
+		_ = json.Unmarshal(data, &parsed)
+		wait := time.Duration(max(parsed.Parameters.RetryAfter, 1)) * time.Second
+		if wait > t.MaxWait {
+			resp.Body = io.NopCloser(bytes.NewReader(data))
+			return resp, nil
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// NewBot connects to the Bot API at apiUrl, empty means api.telegram.org
+func NewBot(token string, apiUrl string) (*tgbotapi.BotAPI, error) {
+	transport := &Transport{
+		MaxRetries: defaultMaxRetries,
+		MaxWait:    defaultMaxWait,
+	}
+	if apiUrl != "" {
+		endpoint, err := url.Parse(apiUrl)
+		if err != nil {
+			return nil, fmt.Errorf("telegram api url: %w", err)
+		}
+		transport.Endpoint = endpoint
+	}
+
+	client := &http.Client{Transport: transport, Timeout: clientTimeout}
+	return tgbotapi.NewBotAPIWithClient(token, client)
+}
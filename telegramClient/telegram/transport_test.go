@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestNewBot_FakeServer(t *testing.T) {
+	fake := newFakeTelegram(t)
+
+	bot, err := NewBot(testToken, fake.server.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+	if bot.Self.UserName != "halo_test_bot" {
+		t.Errorf("bot user name = %q, want halo_test_bot", bot.Self.UserName)
+	}
+}
+
+func TestTransport_RetriesAfter429(t *testing.T) {
+	fake := newFakeTelegram(t)
+	bot, err := NewBot(testToken, fake.server.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	fake.rateLimited["sendMessage"] = 1
+
+	started := time.Now()
+	if _, err := bot.Send(tgbotapi.NewMessage(42, "hello")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least retry_after of 1s", elapsed)
+	}
+
+	calls := fake.callsOf("sendMessage")
+	if len(calls) != 2 {
+		t.Fatalf("sendMessage called %d times, want 2", len(calls))
+	}
+	if calls[1]["chat_id"] != "42" || calls[1]["text"] != "hello" {
+		t.Errorf("retried request params = %v, want the original ones", calls[1])
+	}
+}
+
+func TestTransport_GivesUpPastMaxWait(t *testing.T) {
+	fake := newFakeTelegram(t)
+	fake.retryAfter = 30
+
+	endpoint, _ := url.Parse(fake.server.URL)
+	client := &http.Client{Transport: &Transport{Endpoint: endpoint, MaxRetries: 3, MaxWait: time.Second}}
+	bot, err := tgbotapi.NewBotAPIWithClient(testToken, client)
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+
+	fake.rateLimited["sendMessage"] = 1
+
+	_, err = bot.Send(tgbotapi.NewMessage(42, "hello"))
+	var apiErr tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 30 {
+		t.Fatalf("Send error = %v, want the 429 with retry_after 30", err)
+	}
+	if calls := fake.callsOf("sendMessage"); len(calls) != 1 {
+		t.Errorf("sendMessage called %d times, want 1", len(calls))
+	}
+}
@@ -0,0 +1,130 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// secretHeader carries the secret_token given to setWebhook on every update
+const secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+const (
+	maxUpdateSize   = 1 << 20
+	shutdownTimeout = 10 * time.Second
+)
+
+type WebhookConfig struct {
+	// Url is the public https address Telegram posts updates to
+	Url    string
+	Listen string
+	Secret string
+	// MaxConnections limits the parallel requests of Telegram, it matches the workers
+	MaxConnections int
+}
+
+// WebhookHandler accepts updates carrying the secret and queues them, the
+// request waits while the dispatcher is busy so Telegram slows down
+func WebhookHandler(secret string, dispatcher *Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(secretHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			log.Printf("webhook update decode: %v", err)
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		if err := dispatcher.Dispatch(r.Context(), update); err != nil {
+			// Telegram delivers the update again later
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// SetWebhook registers the webhook with its secret, the library predates secret_token
+func SetWebhook(bot *tgbotapi.BotAPI, config WebhookConfig) error {
+	params := url.Values{}
+	params.Set("url", config.Url)
+	params.Set("secret_token", config.Secret)
+	if config.MaxConnections > 0 {
+		params.Set("max_connections", strconv.Itoa(config.MaxConnections))
+	}
+
+	_, err := bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+// DeleteWebhook switches the bot back to getUpdates, which fails while a webhook is set
+func DeleteWebhook(bot *tgbotapi.BotAPI) error {
+	_, err := bot.MakeRequest("deleteWebhook", url.Values{})
+	return err
+}
+
+// RunWebhook registers the webhook and serves it until the context is done
+func RunWebhook(ctx context.Context, bot *tgbotapi.BotAPI, config WebhookConfig, dispatcher *Dispatcher) error {
+	if config.Secret == "" {
+		return errors.New("webhook secret is required")
+	}
+	public, err := url.Parse(config.Url)
+	if err != nil || public.Host == "" {
+		return fmt.Errorf("webhook url %q is not valid", config.Url)
+	}
+
+	path := public.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, WebhookHandler(config.Secret, dispatcher))
+
+	server := &http.Server{
+		Addr:              config.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	if err := SetWebhook(bot, config); err != nil {
+		server.Close()
+		return fmt.Errorf("set webhook: %w", err)
+	}
+	log.Printf("webhook listening on %s", config.Listen)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("webhook server: %w", err)
+	case <-ctx.Done():
+	}
+
+	// the webhook stays registered, Telegram keeps the updates until the bot is back
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	handled := make(chan tgbotapi.Update, 1)
+	dispatcher := NewDispatcher(2, func(update tgbotapi.Update) { handled <- update })
+	defer dispatcher.Close()
+
+	server := httptest.NewServer(WebhookHandler("s3cret", dispatcher))
+	defer server.Close()
+
+	post := func(secret string, body string) int {
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set(secretHeader, secret)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post update: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	update := `{"update_id": 7, "message": {"message_id": 1, "date": 0, "chat": {"id": 42}, "text": "/today"}}`
+
+	if status := post("", update); status != http.StatusUnauthorized {
+		t.Errorf("without secret: status %d, want 401", status)
+	}
+	if status := post("wrong", update); status != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status %d, want 401", status)
+	}
+	if status := post("s3cret", "{update"); status != http.StatusBadRequest {
+		t.Errorf("broken json: status %d, want 400", status)
+	}
+	select {
+	case got := <-handled:
+		t.Fatalf("rejected update %d was handled", got.UpdateID)
+	default:
+	}
+
+	if status := post("s3cret", update); status != http.StatusOK {
+		t.Fatalf("valid update: status %d, want 200", status)
+	}
+	select {
+	case got := <-handled:
+		if got.UpdateID != 7 || got.Message.Text != "/today" {
+			t.Errorf("handled update = %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("valid update was not handled")
+	}
+}
+
+func TestSetWebhook_SendsSecret(t *testing.T) {
+	fake := newFakeTelegram(t)
+	bot, err := NewBot(testToken, fake.server.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	err = SetWebhook(bot, WebhookConfig{Url: "https://halo.example/telegram", Secret: "s3cret", MaxConnections: 4})
+	if err != nil {
+		t.Fatalf("SetWebhook: %v", err)
+	}
+
+	calls := fake.callsOf("setWebhook")
+	if len(calls) != 1 {
+		t.Fatalf("setWebhook called %d times, want 1", len(calls))
+	}
+	if calls[0]["url"] != "https://halo.example/telegram" || calls[0]["secret_token"] != "s3cret" ||
+		calls[0]["max_connections"] != "4" {
+		t.Errorf("setWebhook params = %v", calls[0])
+	}
+}
+
+func TestRunWebhook_RequiresSecret(t *testing.T) {
+	fake := newFakeTelegram(t)
+	bot, err := NewBot(testToken, fake.server.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+	dispatcher := NewDispatcher(1, func(tgbotapi.Update) {})
+	defer dispatcher.Close()
+
+	err = RunWebhook(context.Background(), bot, WebhookConfig{Url: "https://halo.example/telegram"}, dispatcher)
+	if err == nil {
+		t.Fatal("RunWebhook without a secret succeeded")
+	}
+	if calls := fake.callsOf("setWebhook"); len(calls) != 0 {
+		t.Errorf("setWebhook called without a secret")
+	}
+}
+
+func TestPoll_DispatchesAndStops(t *testing.T) {
+	fake := newFakeTelegram(t)
+	fake.results["getUpdates"] = func(params map[string]string) any {
+		if params["offset"] == "" {
+			return []any{
+				map[string]any{"update_id": 10, "message": map[string]any{"message_id": 1, "date": 0, "chat": map[string]any{"id": 42}, "text": "a"}},
+				map[string]any{"update_id": 11, "message": map[string]any{"message_id": 2, "date": 0, "chat": map[string]any{"id": 42}, "text": "b"}},
+			}
+		}
+		// a long poll without updates
+		time.Sleep(50 * time.Millisecond)
+		return []any{}
+	}
+	bot, err := NewBot(testToken, fake.server.URL)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	handled := make(chan int, 2)
+	dispatcher := NewDispatcher(1, func(update tgbotapi.Update) { handled <- update.UpdateID })
+	defer dispatcher.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Poll(ctx, bot, dispatcher) }()
+
+	for _, want := range []int{10, 11} {
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Errorf("handled update %d, want %d", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("update %d was not handled", want)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Poll: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Poll did not stop")
+	}
+
+	if len(fake.callsOf("deleteWebhook")) != 1 {
+		t.Error("Poll did not delete the webhook first")
+	}
+	for _, call := range fake.callsOf("getUpdates")[1:] {
+		if call["offset"] != "12" {
+			t.Errorf("getUpdates offset = %s, want 12 after the first batch", call["offset"])
+		}
+	}
+}